@@ -4,15 +4,30 @@ import (
 	"dolme/internal/compiler"
 	"dolme/internal/logger"
 	"dolme/pkg/color"
+	"dolme/pkg/parser/codegen/optimizer"
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/log"
 )
 
 // Main entry point for the Dolme compiler.
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "callgraph" {
+		callgraph(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "parse" {
+		parse(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "grammar" {
+		grammar(os.Args[2:])
+		return
+	}
+
 	options := compiler.Compiler{}
 
 	flag.BoolVar(&options.Help, "h", false, "Show help")
@@ -22,6 +37,16 @@ func main() {
 	flag.BoolVar(&options.NoColor, "n", false, "No color")
 	flag.StringVar(&options.TargetArch, "a", "arm64-macos", "Target architecture (e.g., arm64-macos, x86_64-linux)")
 	flag.StringVar(&options.OutputFile, "o", "a.out", "Output binary name")
+	flag.IntVar(&options.InlineThreshold, "inline", optimizer.DefaultInlineThreshold, "Largest function body (in instructions) to inline, 0 disables inlining")
+	flag.IntVar(&options.EvalSteps, "eval-steps", optimizer.DefaultEvalSteps, "Step budget for evaluating a pure call at compile time, 0 disables it")
+	o0 := flag.Bool("O0", false, "Disable optimizations")
+	o1 := flag.Bool("O1", false, "Run the local optimizations (default)")
+	o2 := flag.Bool("O2", false, "Also evaluate pure calls, inline, eliminate tail calls and hoist loop invariants")
+	flag.StringVar(&options.Passes, "passes", "", fmt.Sprintf("Comma-separated pass pipeline, overrides -O (%s)", strings.Join(optimizer.PassNames(), ", ")))
+	flag.StringVar(&options.PrintAfter, "print-after", "", "Dump the IR after the given comma-separated passes")
+	flag.BoolVar(&options.Stats, "stats", false, "Print per-pass timing and instruction counts")
+	flag.StringVar(&options.Parser, "parser", "ll1", "Parsing algorithm (ll1, lalr, lr1)")
+	flag.IntVar(&options.HeapLimit, "heap-limit", 0, "Bytes of lists, structs and arrays the interpreter may hold, 0 for no limit")
 
 	flag.Parse()
 	args := flag.Args()
@@ -44,8 +69,100 @@ func main() {
 
 	options.SourceFile = args[0]
 
+	options.OptLevel = 1
+	levels := 0
+	for level, set := range []bool{*o0, *o1, *o2} {
+		if set {
+			options.OptLevel = level
+			levels++
+		}
+	}
+	if levels > 1 {
+		log.Fatal("Conflicting optimization levels", "help", "pass only one of -O0, -O1 and -O2")
+	}
+
 	err := options.Compile()
 	if err != nil {
 		log.Fatal("Compilation failed", "error", err)
 	}
 }
+
+// callgraph runs the "dolme callgraph" subcommand
+func callgraph(args []string) {
+	options := compiler.Compiler{}
+
+	fs := flag.NewFlagSet("callgraph", flag.ExitOnError)
+	format := fs.String("format", "dot", "Output format (dot, json)")
+	fs.BoolVar(&options.Verbose, "v", false, "Verbose mode")
+	fs.BoolVar(&options.NoColor, "n", false, "No color")
+	fs.Usage = func() {
+		fmt.Printf("Usage: %s callgraph [options] <file>\n", os.Args[0])
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	logger.Init(options.Verbose, options.NoColor)
+	if options.NoColor {
+		color.EnableColor(false)
+	}
+
+	if fs.NArg() == 0 {
+		log.Fatal("No input file provided", "help", fmt.Sprintf("%s callgraph -h", os.Args[0]))
+	}
+	options.SourceFile = fs.Arg(0)
+
+	if err := options.CallGraph(*format); err != nil {
+		log.Fatal("Call graph failed", "error", err)
+	}
+}
+
+// parse runs the "dolme parse" subcommand
+func parse(args []string) {
+	options := compiler.Compiler{}
+
+	fs := flag.NewFlagSet("parse", flag.ExitOnError)
+	fs.BoolVar(&options.Trace, "trace", false, "Print the parse stack, current token and action of every step")
+	fs.StringVar(&options.TraceFormat, "format", "text", "Trace format (text, csv, json for -trace; text, html for -trace-sdt)")
+	fs.StringVar(&options.Tree, "tree", "", "Print the parse tree (dot, json)")
+	fs.BoolVar(&options.TraceSDT, "trace-sdt", false, "Print the semantic stack and generated code of every semantic action")
+	fs.StringVar(&options.Parser, "parser", "ll1", "Parsing algorithm (ll1, lalr, lr1)")
+	fs.BoolVar(&options.Verbose, "v", false, "Verbose mode")
+	fs.BoolVar(&options.NoColor, "n", false, "No color")
+	fs.Usage = func() {
+		fmt.Printf("Usage: %s parse [options] <file>\n", os.Args[0])
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	logger.Init(options.Verbose, options.NoColor)
+	if options.NoColor {
+		color.EnableColor(false)
+	}
+
+	if fs.NArg() == 0 {
+		log.Fatal("No input file provided", "help", fmt.Sprintf("%s parse -h", os.Args[0]))
+	}
+	options.SourceFile = fs.Arg(0)
+
+	if err := options.Parse(); err != nil {
+		log.Fatal("Parse failed", "error", err)
+	}
+}
+
+// grammar runs the "dolme grammar" subcommand
+func grammar(args []string) {
+	fs := flag.NewFlagSet("grammar", flag.ExitOnError)
+	format := fs.String("format", "md", "Output format (md, ebnf, json)")
+	fs.Usage = func() {
+		fmt.Printf("Usage: %s grammar [options]\n", os.Args[0])
+		fmt.Println("Options:")
+		fs.PrintDefaults()
+	}
+	_ = fs.Parse(args)
+
+	if err := compiler.Grammar(*format); err != nil {
+		log.Fatal("Grammar export failed", "error", err)
+	}
+}
@@ -0,0 +1,50 @@
+package compiler
+
+import (
+	"dolme/pkg/color"
+	"dolme/pkg/parser/codegen/optimizer"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// CallGraph prints the call graph of the source file as DOT or JSON and
+// reports its recursive cycles and unreachable functions on stderr
+func (opts *Compiler) CallGraph(format string) error {
+	p, err := opts.parse()
+	if err != nil {
+		return err
+	}
+
+	g := optimizer.BuildCallGraph(p.GetIRCode())
+
+	switch format {
+	case "dot":
+		fmt.Print(g.DOT())
+	case "json":
+		data, err := g.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	default:
+		return fmt.Errorf("unknown call graph format %q (available: dot, json)", format)
+	}
+
+	for _, cycle := range g.Cycles() {
+		if len(cycle) == 1 {
+			fmt.Fprintln(os.Stderr, color.YellowText(fmt.Sprintf("recursive: %s", cycle[0])))
+			continue
+		}
+		fmt.Fprintln(os.Stderr, color.YellowText(fmt.Sprintf("mutually recursive: %s", strings.Join(cycle, ", "))))
+	}
+
+	reachable := g.Reachable()
+	for _, f := range g.Funcs {
+		if !reachable[f] {
+			fmt.Fprintln(os.Stderr, color.YellowText(fmt.Sprintf("unreachable: %s", f)))
+		}
+	}
+
+	return nil
+}
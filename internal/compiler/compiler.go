@@ -5,10 +5,14 @@ import (
 	"dolme/pkg/interpreter"
 	"dolme/pkg/lexer"
 	"dolme/pkg/parser"
+	"dolme/pkg/parser/codegen"
 	"dolme/pkg/parser/codegen/assembly"
 	arm64_macos "dolme/pkg/parser/codegen/assembly/arm64/macos"
+	"dolme/pkg/parser/codegen/optimizer"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 
 	"github.com/charmbracelet/log"
 )
@@ -22,65 +26,61 @@ type Compiler struct {
 	TargetArch      string // Target architecture for compilation (e.g., "arm64-macos")
 	SourceFile      string // Path to the source file
 	OutputFile      string // Path to the output file
+	InlineThreshold int    // Largest function body to inline, 0 disables inlining
+	EvalSteps       int    // Step budget for evaluating a pure call at compile time, 0 disables it
+	HeapLimit       int    // Bytes of lists, structs and arrays the interpreter may hold, 0 for no limit
+	OptLevel        int    // Optimization preset (0, 1 or 2)
+	Passes          string // Comma-separated pass pipeline, overrides OptLevel
+	PrintAfter      string // Comma-separated passes whose output is dumped
+	Stats           bool   // Print per-pass timing and instruction counts
+	Parser          string // Parsing algorithm (ll1, lalr, lr1), ll1 when empty
+	Trace           bool   // Print every step of the parse (parse subcommand)
+	TraceFormat     string // Format of the traces (text, csv or json for Trace, text or html for TraceSDT)
+	Tree            string // Print the parse tree in this format (dot, json), none when empty
+	TraceSDT        bool   // Print the semantic stack and generated code of every semantic action (parse subcommand)
 }
 
 // Compile processes the source file, generates IR code, and either interprets or compiles it based on the options set.
 func (opts *Compiler) Compile() error {
-	log.Info("Processing file", "file", opts.SourceFile)
-
-	input, err := os.ReadFile(opts.SourceFile)
+	p, err := opts.parse()
 	if err != nil {
-		log.Fatal("Failed to read file", "file", opts.SourceFile, "error", err)
+		return err
 	}
 
-	l := lexer.NewLexer(string(input))
-	p := parser.NewParser(l)
-	p.Parse()
-
-	syntaxErrors := p.Errors()
-	if len(syntaxErrors) > 0 {
-		fmt.Println(color.BrightRedText("=== Syntax Errors ==="))
-		fmt.Println(syntaxErrors[0])
-		return fmt.Errorf("parsing failed with %d errors", len(syntaxErrors))
+	passes, err := optimizer.Preset(opts.OptLevel)
+	if opts.Passes != "" {
+		passes, err = optimizer.ParsePipeline(opts.Passes)
+	}
+	if err != nil {
+		return err
 	}
 
-	semanticErrors := p.GetSemanticErrors()
-	if len(semanticErrors) > 0 {
-		fmt.Println(color.BrightRedText("=== Semantic Errors ==="))
-		fmt.Println(semanticErrors[0])
-		return fmt.Errorf("semantic analysis failed with %d errors", len(semanticErrors))
+	// -print-after names passes the way -passes does
+	if _, err := optimizer.ParsePipeline(opts.PrintAfter); err != nil {
+		return err
+	}
+	printAfter := make([]string, 0)
+	for _, name := range strings.Split(opts.PrintAfter, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			printAfter = append(printAfter, name)
+		}
 	}
 
-	instructions := p.GetIRCode()
+	pm := optimizer.NewManager(p.GetCG(), passes,
+		optimizer.WithInlineThreshold(opts.InlineThreshold),
+		optimizer.WithEvalSteps(opts.EvalSteps),
+		optimizer.WithPrintAfter(printAfter, func(pass string, pb []codegen.Instruction) {
+			printIR(fmt.Sprintf("\n=== After %s ===", pass), pb)
+		}),
+	)
+	instructions := pm.Run(p.GetIRCode())
+
+	if opts.Stats || opts.Verbose {
+		printStats(pm.Stats())
+	}
 
 	if opts.Verbose {
-		fmt.Println(color.GreenText("\n=== Generated Three-Address Code ==="))
-		if len(instructions) == 0 {
-			fmt.Println(color.GrayText("No code generated."))
-		} else {
-			for i, instr := range instructions {
-				arg1 := ""
-				arg2 := ""
-				arg3 := ""
-
-				if instr.Arg1 != nil {
-					arg1 = fmt.Sprintf("%v", instr.Arg1)
-				}
-				if instr.Arg2 != nil {
-					arg2 = fmt.Sprintf("%v", instr.Arg2)
-				}
-				if instr.Arg3 != nil {
-					arg3 = fmt.Sprintf("%v", instr.Arg3)
-				}
-
-				fmt.Printf("%s: (%s, %s, %s, %s)\n",
-					color.CyanText(fmt.Sprintf("%d", i)),
-					color.YellowText(string(instr.Op)),
-					color.BlueText(arg1),
-					color.BlueText(arg2),
-					color.BlueText(arg3))
-			}
-		}
+		printIR("\n=== Generated Three-Address Code ===", instructions)
 	}
 
 	var arch assembly.Assembly
@@ -105,7 +105,7 @@ func (opts *Compiler) Compile() error {
 	}
 
 	if opts.ShouldInterpret {
-		intr := interpreter.NewInterpreter(instructions)
+		intr := interpreter.NewInterpreter(instructions, interpreter.WithHeapLimit(opts.HeapLimit))
 		fmt.Println(color.GreenText("\n=== Program Output ==="))
 		if err := intr.Run(); err != nil {
 			return fmt.Errorf("interpretation failed: %w", err)
@@ -114,3 +114,114 @@ func (opts *Compiler) Compile() error {
 
 	return nil
 }
+
+// parse reads and parses the source file, printing every syntax error or, when
+// there are none, every semantic error
+func (opts *Compiler) parse() (*parser.Parser, error) {
+	p, err := opts.newParser()
+	if err != nil {
+		return nil, err
+	}
+	p.Parse()
+
+	if err := reportErrors(os.Stdout, p); err != nil {
+		return nil, err
+	}
+
+	return p, nil
+}
+
+// reportErrors writes every syntax error of a finished parse or, when there
+// are none, every semantic error to w
+func reportErrors(w io.Writer, p *parser.Parser) error {
+	syntaxErrors := p.Errors()
+	if len(syntaxErrors) > 0 {
+		fmt.Fprintln(w, color.BrightRedText("=== Syntax Errors ==="))
+		for _, e := range syntaxErrors {
+			fmt.Fprintln(w, e)
+		}
+		return fmt.Errorf("parsing failed with %d errors", len(syntaxErrors))
+	}
+
+	semanticErrors := p.GetSemanticErrors()
+	if len(semanticErrors) > 0 {
+		fmt.Fprintln(w, color.BrightRedText("=== Semantic Errors ==="))
+		for _, e := range semanticErrors {
+			fmt.Fprintln(w, e)
+		}
+		return fmt.Errorf("semantic analysis failed with %d errors", len(semanticErrors))
+	}
+
+	return nil
+}
+
+// newParser reads the source file and sets up a parser for it with the
+// selected algorithm and the given options
+func (opts *Compiler) newParser(options ...parser.Option) (*parser.Parser, error) {
+	log.Info("Processing file", "file", opts.SourceFile)
+
+	input, err := os.ReadFile(opts.SourceFile)
+	if err != nil {
+		log.Fatal("Failed to read file", "file", opts.SourceFile, "error", err)
+	}
+
+	algorithm := parser.LL1
+	if opts.Parser != "" {
+		if algorithm, err = parser.ParseAlgorithm(opts.Parser); err != nil {
+			return nil, err
+		}
+	}
+
+	l := lexer.NewLexer(string(input))
+	return parser.NewParser(l, append([]parser.Option{parser.WithAlgorithm(algorithm)}, options...)...), nil
+}
+
+// printIR prints the three-address code under a title
+func printIR(title string, instructions []codegen.Instruction) {
+	fmt.Println(color.GreenText(title))
+	if len(instructions) == 0 {
+		fmt.Println(color.GrayText("No code generated."))
+		return
+	}
+
+	for i, instr := range instructions {
+		arg1 := ""
+		arg2 := ""
+		arg3 := ""
+
+		if instr.Arg1 != nil {
+			arg1 = fmt.Sprintf("%v", instr.Arg1)
+		}
+		if instr.Arg2 != nil {
+			arg2 = fmt.Sprintf("%v", instr.Arg2)
+		}
+		if instr.Arg3 != nil {
+			arg3 = fmt.Sprintf("%v", instr.Arg3)
+		}
+
+		fmt.Printf("%s: (%s, %s, %s, %s)\n",
+			color.CyanText(fmt.Sprintf("%d", i)),
+			color.YellowText(string(instr.Op)),
+			color.BlueText(arg1),
+			color.BlueText(arg2),
+			color.BlueText(arg3))
+	}
+}
+
+// printStats prints how long each pass took and how it changed the instruction count
+func printStats(stats []optimizer.Stat) {
+	fmt.Println(color.GreenText("\n=== Optimization Passes ==="))
+	if len(stats) == 0 {
+		fmt.Println(color.GrayText("No passes run."))
+		return
+	}
+
+	for _, st := range stats {
+		delta := fmt.Sprintf("%+d", st.After-st.Before)
+		fmt.Printf("%-10s %12s  %5d -> %-5d (%s)\n",
+			color.YellowText(st.Pass),
+			color.GrayText(st.Duration.String()),
+			st.Before, st.After,
+			color.BlueText(delta))
+	}
+}
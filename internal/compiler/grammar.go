@@ -0,0 +1,29 @@
+package compiler
+
+import (
+	"dolme/pkg/parser"
+	"fmt"
+)
+
+// Grammar prints the grammar with its FIRST/FOLLOW sets and LL(1) table as
+// Markdown (the committed dolme.md), EBNF or JSON
+func Grammar(format string) error {
+	doc := parser.NewGrammarDoc()
+
+	switch format {
+	case "md":
+		fmt.Print(doc.Markdown())
+	case "ebnf":
+		fmt.Print(doc.EBNF())
+	case "json":
+		data, err := doc.JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	default:
+		return fmt.Errorf("unknown grammar format %q (available: md, ebnf, json)", format)
+	}
+
+	return nil
+}
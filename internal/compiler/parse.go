@@ -0,0 +1,93 @@
+package compiler
+
+import (
+	"dolme/pkg/parser"
+	"dolme/pkg/parser/codegen"
+	"fmt"
+	"os"
+)
+
+// Parse parses the source file and prints the requested views of the parse.
+// Errors go to stderr so that CSV and JSON output stays machine readable.
+func (opts *Compiler) Parse() error {
+	switch opts.TraceFormat {
+	case "", "text", "csv", "json", "html":
+	default:
+		return fmt.Errorf("unknown trace format %q (available: text, csv, json, html)", opts.TraceFormat)
+	}
+	if opts.Trace && opts.TraceFormat == "html" {
+		return fmt.Errorf("unknown trace format %q (available: text, csv, json)", opts.TraceFormat)
+	}
+	if opts.TraceSDT && (opts.TraceFormat == "csv" || opts.TraceFormat == "json") {
+		return fmt.Errorf("unknown SDT trace format %q (available: text, html)", opts.TraceFormat)
+	}
+	switch opts.Tree {
+	case "", "dot", "json":
+	default:
+		return fmt.Errorf("unknown tree format %q (available: dot, json)", opts.Tree)
+	}
+
+	var trace parser.Trace
+	var sdt codegen.SDTTrace
+	options := make([]parser.Option, 0)
+	if opts.Trace {
+		options = append(options, parser.WithTrace(&trace))
+	}
+	if opts.Tree != "" {
+		options = append(options, parser.WithParseTree())
+	}
+	if opts.TraceSDT {
+		options = append(options, parser.WithSDTTrace(&sdt))
+	}
+
+	p, err := opts.newParser(options...)
+	if err != nil {
+		return err
+	}
+	p.Parse()
+
+	if opts.Trace {
+		switch opts.TraceFormat {
+		case "csv":
+			out, err := trace.CSV()
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+		case "json":
+			data, err := trace.JSON()
+			if err != nil {
+				return err
+			}
+			fmt.Println(string(data))
+		default:
+			fmt.Print(trace.Text())
+		}
+	}
+
+	switch opts.Tree {
+	case "dot":
+		fmt.Print(p.ParseTree().DOT())
+	case "json":
+		data, err := p.ParseTree().JSON()
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	}
+
+	if opts.TraceSDT {
+		switch opts.TraceFormat {
+		case "html":
+			out, err := sdt.HTML()
+			if err != nil {
+				return err
+			}
+			fmt.Print(out)
+		default:
+			fmt.Print(sdt.Text())
+		}
+	}
+
+	return reportErrors(os.Stderr, p)
+}
@@ -0,0 +1,333 @@
+package ast
+
+import "dolme/pkg/lexer"
+
+// Span covers a node in the source, from the position of its first token to
+// the position of its last one
+type Span struct {
+	Start lexer.Position
+	End   lexer.Position
+}
+
+// Node is any element of the syntax tree
+type Node interface {
+	Span() Span
+}
+
+// Decl is a top-level declaration: a function, a struct, an enum or a statement
+type Decl interface {
+	Node
+	declNode()
+}
+
+// Stmt is a statement, allowed both at top level and inside blocks
+type Stmt interface {
+	Decl
+	stmtNode()
+}
+
+// Expr is an expression producing a value
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Program is a whole source file
+type Program struct {
+	Decls []Decl
+	Pos   Span
+}
+
+// FuncDecl is `func name(params): type { body }`, or a method
+// `func (recv: type) name(params): type { body }`
+type FuncDecl struct {
+	Recv       *Param      // receiver, nil unless a method
+	Name       lexer.Token // function name
+	Params     []*Param
+	Result     lexer.Token  // return type keyword or struct name
+	ResultLen  *lexer.Token // array length of the return type, nil unless an array
+	ResultList *lexer.Token // closing bracket of a list return type, nil unless a list
+	Body       *Block
+	Pos        Span
+}
+
+// Param is a `name: type` function parameter
+type Param struct {
+	Name lexer.Token
+	Type lexer.Token  // type keyword or struct name, the element type of an array or a list
+	Len  *lexer.Token // array length, nil unless an array
+	List *lexer.Token // closing bracket of a list type, nil unless a list
+	Pos  Span
+}
+
+// StructDecl is `struct name { fields }`
+type StructDecl struct {
+	Name   lexer.Token
+	Fields []*FieldDecl
+	Pos    Span
+}
+
+// FieldDecl is a `name: type` field of a struct
+type FieldDecl struct {
+	Name lexer.Token
+	Type lexer.Token  // type keyword or struct name, the element type of an array or a list
+	Len  *lexer.Token // array length, nil unless an array
+	List *lexer.Token // closing bracket of a list type, nil unless a list
+	Pos  Span
+}
+
+// EnumDecl is `enum name { variants }`
+type EnumDecl struct {
+	Name     lexer.Token
+	Variants []lexer.Token
+	Pos      Span
+}
+
+// Block is a braced list of statements
+type Block struct {
+	Stmts []Stmt
+	Pos   Span // from the opening to the closing brace
+}
+
+// VarDecl is `let name: type = value;`
+type VarDecl struct {
+	Name  lexer.Token
+	Type  lexer.Token  // type keyword or struct name, the element type of an array or a list
+	Len   *lexer.Token // array length, nil unless an array
+	List  *lexer.Token // closing bracket of a list type, nil unless a list
+	Value Expr
+	Pos   Span
+}
+
+// Assign is `name = value;`, `name[index] = value;` to assign an element,
+// or `name.a.b = value;` to assign a field
+type Assign struct {
+	Target lexer.Token
+	Fields []lexer.Token  // fields selected from Target, the last one assigned
+	Index  Expr           // nil when the whole variable is assigned
+	Rbrack lexer.Position // position of the closing bracket of Index
+	Value  Expr
+	Pos    Span
+}
+
+// CallStmt is a call made for its effect, `name(args);`
+type CallStmt struct {
+	Call *Call
+	Pos  Span
+}
+
+// If is `if (cond) { then } else { else }`; Else is nil without an else branch
+type If struct {
+	Cond Expr
+	Then *Block
+	Else *Block
+	Pos  Span
+}
+
+// While is `while (cond) { body }`
+type While struct {
+	Cond Expr
+	Body *Block
+	Pos  Span
+}
+
+// Match is `match (value) { arms }`
+type Match struct {
+	Value  Expr
+	Rparen lexer.Position // position of the parenthesis closing Value
+	Arms   []*Arm
+	Pos    Span
+}
+
+// Arm is a `variant => { body }` arm of a match
+type Arm struct {
+	Variant lexer.Token
+	Body    *Block
+	Pos     Span
+}
+
+// Print is `print(value);`
+type Print struct {
+	Value Expr
+	Pos   Span
+}
+
+// Append is `append(list, value);`
+type Append struct {
+	List  Expr
+	Value Expr
+	Pos   Span
+}
+
+// Return is `return value;`; Value is nil for a bare return
+type Return struct {
+	Value Expr
+	Pos   Span
+}
+
+// Break is `break;`
+type Break struct {
+	Pos Span
+}
+
+// Continue is `continue;`
+type Continue struct {
+	Pos Span
+}
+
+// Ident is a variable reference
+type Ident struct {
+	Name lexer.Token
+}
+
+// Literal is a number, a string, true or false
+type Literal struct {
+	Value lexer.Token
+}
+
+// Binary is an arithmetic, logical or relational operator applied to two operands
+type Binary struct {
+	Op lexer.Token
+	X  Expr
+	Y  Expr
+}
+
+// Unary is an operator applied to one operand
+type Unary struct {
+	Op lexer.Token
+	X  Expr
+}
+
+// Call is `name(args)` used as a value, or a method call `recv.name(args)`
+type Call struct {
+	Recv   Expr // nil unless a method call
+	Name   lexer.Token
+	Args   []Expr
+	Rparen lexer.Position // position of the closing parenthesis
+}
+
+// Len is `len(x)`, the length of a string, an array or a list
+type Len struct {
+	X       Expr
+	Keyword lexer.Position // position of len
+	Rparen  lexer.Position // position of the closing parenthesis
+}
+
+// Index is `x[index]`, an element of an array or a list
+type Index struct {
+	X      Expr
+	Index  Expr
+	Rbrack lexer.Position // position of the closing bracket
+}
+
+// ArrayLit is `[elems]`, an array literal, which also starts a list
+type ArrayLit struct {
+	Elems  []Expr
+	Lbrack lexer.Position
+	Rbrack lexer.Position
+}
+
+// Selector is `x.name`, a field of a struct
+type Selector struct {
+	X    Expr
+	Name lexer.Token
+}
+
+// StructLit is `Name { field: value, ... }`, a struct literal
+type StructLit struct {
+	Type   lexer.Token // struct name
+	Fields []*FieldInit
+	Rbrace lexer.Position
+}
+
+// FieldInit is a `name: value` field of a struct literal
+type FieldInit struct {
+	Name  lexer.Token
+	Value Expr
+}
+
+// Paren is a parenthesized expression
+type Paren struct {
+	X      Expr
+	Lparen lexer.Position
+	Rparen lexer.Position
+}
+
+func (n *Program) Span() Span    { return n.Pos }
+func (n *FuncDecl) Span() Span   { return n.Pos }
+func (n *StructDecl) Span() Span { return n.Pos }
+func (n *FieldDecl) Span() Span  { return n.Pos }
+func (n *EnumDecl) Span() Span   { return n.Pos }
+func (n *Param) Span() Span      { return n.Pos }
+func (n *Block) Span() Span      { return n.Pos }
+func (n *VarDecl) Span() Span    { return n.Pos }
+func (n *Assign) Span() Span     { return n.Pos }
+func (n *CallStmt) Span() Span   { return n.Pos }
+func (n *If) Span() Span         { return n.Pos }
+func (n *While) Span() Span      { return n.Pos }
+func (n *Match) Span() Span      { return n.Pos }
+func (n *Arm) Span() Span        { return n.Pos }
+func (n *Print) Span() Span      { return n.Pos }
+func (n *Append) Span() Span     { return n.Pos }
+func (n *Return) Span() Span     { return n.Pos }
+func (n *Break) Span() Span      { return n.Pos }
+func (n *Continue) Span() Span   { return n.Pos }
+func (n *Ident) Span() Span      { return Span{n.Name.Pos, n.Name.Pos} }
+func (n *Literal) Span() Span    { return Span{n.Value.Pos, n.Value.Pos} }
+func (n *Binary) Span() Span     { return Span{n.X.Span().Start, n.Y.Span().End} }
+func (n *Unary) Span() Span      { return Span{n.Op.Pos, n.X.Span().End} }
+func (n *Call) Span() Span       { return Span{n.start(), n.Rparen} }
+func (n *Len) Span() Span        { return Span{n.Keyword, n.Rparen} }
+func (n *Index) Span() Span      { return Span{n.X.Span().Start, n.Rbrack} }
+func (n *ArrayLit) Span() Span   { return Span{n.Lbrack, n.Rbrack} }
+func (n *Selector) Span() Span   { return Span{n.X.Span().Start, n.Name.Pos} }
+func (n *StructLit) Span() Span  { return Span{n.Type.Pos, n.Rbrace} }
+func (n *Paren) Span() Span      { return Span{n.Lparen, n.Rparen} }
+
+// start returns the position of the first token of a call, that of its
+// receiver for a method call
+func (n *Call) start() lexer.Position {
+	if n.Recv != nil {
+		return n.Recv.Span().Start
+	}
+	return n.Name.Pos
+}
+
+func (*FuncDecl) declNode()   {}
+func (*StructDecl) declNode() {}
+func (*EnumDecl) declNode()   {}
+func (*VarDecl) declNode()    {}
+func (*Assign) declNode()     {}
+func (*CallStmt) declNode()   {}
+func (*If) declNode()         {}
+func (*While) declNode()      {}
+func (*Match) declNode()      {}
+func (*Print) declNode()      {}
+func (*Append) declNode()     {}
+func (*Return) declNode()     {}
+func (*Break) declNode()      {}
+func (*Continue) declNode()   {}
+
+func (*VarDecl) stmtNode()  {}
+func (*Assign) stmtNode()   {}
+func (*CallStmt) stmtNode() {}
+func (*If) stmtNode()       {}
+func (*While) stmtNode()    {}
+func (*Match) stmtNode()    {}
+func (*Print) stmtNode()    {}
+func (*Append) stmtNode()   {}
+func (*Return) stmtNode()   {}
+func (*Break) stmtNode()    {}
+func (*Continue) stmtNode() {}
+
+func (*Ident) exprNode()     {}
+func (*Literal) exprNode()   {}
+func (*Binary) exprNode()    {}
+func (*Unary) exprNode()     {}
+func (*Call) exprNode()      {}
+func (*Len) exprNode()       {}
+func (*Index) exprNode()     {}
+func (*ArrayLit) exprNode()  {}
+func (*Selector) exprNode()  {}
+func (*StructLit) exprNode() {}
+func (*Paren) exprNode()     {}
@@ -0,0 +1,476 @@
+package ast
+
+import (
+	"dolme/pkg/lexer"
+
+	"github.com/charmbracelet/log"
+)
+
+// item is an entry of the builder's semantic stack: a finished expression
+// with the range of tokens it covers, or a construct still being built
+type item struct {
+	expr Expr
+	from int // index of the first token of expr
+	to   int // index of the last token of expr
+
+	tok   lexer.Token  // captured name, type or operator
+	size  *lexer.Token // length of a captured array type
+	list  *lexer.Token // closing bracket of a captured list type
+	index int          // index of the token that starts the construct
+
+	call   *Call     // call collecting its arguments
+	target *CallStmt // statement call collecting its arguments
+	fn     *FuncDecl // function collecting its parameters
+	ifs    *If       // if statement between its branches
+	loop   *While    // while statement before its body
+	lit    *ArrayLit // array literal collecting its elements
+
+	edecl  *EnumDecl     // enum collecting its variants
+	match  *Match        // match collecting its arms
+	arm    *Arm          // match arm before its body
+	sdecl  *StructDecl   // struct collecting its fields
+	slit   *StructLit    // struct literal collecting its fields
+	fields []lexer.Token // fields selected from an assignment target
+}
+
+// group is an open parenthesis that may wrap a parenthesized expression
+type group struct {
+	index int  // token index of the parenthesis
+	depth int  // stack size when it was opened
+	wraps bool // false when it belongs to a call, a parameter list or a statement
+}
+
+// Builder turns the tokens and semantic actions of a parse into a syntax
+// tree. It plugs into the parser in place of the code generator through
+// parser.WithTranslator and mirrors what each action does to the semantic
+// stack, building nodes where the code generator emits instructions.
+type Builder struct {
+	toks   []lexer.Token
+	stack  []item
+	blocks []*Block // innermost last
+	opens  []int    // token index of the opening brace of each open block
+	starts []int    // token index of the keyword of each open construct, innermost last
+	groups []group
+	semi   *Span // statement whose span ends at the next semicolon
+	prog   *Program
+}
+
+// NewBuilder creates a Builder with an empty program
+func NewBuilder() *Builder {
+	return &Builder{prog: &Program{}}
+}
+
+// Program returns the tree built so far; it is complete once the parse
+// finished without syntax errors
+func (b *Builder) Program() *Program {
+	if len(b.toks) > 0 {
+		b.prog.Pos = Span{b.toks[0].Pos, b.toks[len(b.toks)-1].Pos}
+	}
+	return b.prog
+}
+
+// SetCurrentToken records a matched token
+func (b *Builder) SetCurrentToken(token lexer.Token) {
+	b.toks = append(b.toks, token)
+	idx := len(b.toks) - 1
+
+	switch token.Type {
+	case lexer.LPAREN:
+		// parentheses right after a name, if, while, print, len or append are part of that construct
+		wraps := true
+		if idx > 0 {
+			switch b.toks[idx-1].Type {
+			case lexer.ID, lexer.IF, lexer.WHILE, lexer.PRINT, lexer.LEN, lexer.APPEND, lexer.FUNC, lexer.MATCH:
+				wraps = false
+			}
+		}
+		b.groups = append(b.groups, group{index: idx, depth: len(b.stack), wraps: wraps})
+
+	case lexer.RPAREN:
+		if len(b.groups) == 0 {
+			return
+		}
+		g := b.groups[len(b.groups)-1]
+		b.groups = b.groups[:len(b.groups)-1]
+		if g.wraps && len(b.stack) == g.depth+1 && b.top().expr != nil {
+			x := b.pop()
+			b.pushExpr(&Paren{X: x.expr, Lparen: b.toks[g.index].Pos, Rparen: token.Pos}, g.index, idx)
+		}
+
+	case lexer.LET, lexer.FUNC, lexer.STRUCT, lexer.ENUM, lexer.IF, lexer.MATCH, lexer.PRINT, lexer.APPEND, lexer.LEN, lexer.RETURN, lexer.CONTINUE, lexer.BREAK:
+		// the action that builds the construct takes its start from here
+		b.starts = append(b.starts, idx)
+
+	case lexer.SEMICOLON:
+		if b.semi != nil {
+			b.semi.End = token.Pos
+			b.semi = nil
+		}
+	}
+}
+
+// ExecuteAction applies a semantic action to the tree under construction
+func (b *Builder) ExecuteAction(actionName string) {
+	n := len(b.toks)
+	last := n - 1
+
+	switch actionName {
+	case "@push":
+		b.pushExpr(&Literal{Value: b.toks[last]}, last, last)
+	case "@load":
+		b.pushExpr(&Ident{Name: b.toks[last]}, last, last)
+
+	case "@add", "@sub", "@mul", "@div", "@mod", "@and", "@or":
+		y, x := b.pop(), b.pop()
+		// the operator is the first token after x that is not a closing parenthesis
+		op := x.to + 1
+		for op < y.from && b.toks[op].Type == lexer.RPAREN {
+			op++
+		}
+		b.pushExpr(&Binary{Op: b.toks[op], X: x.expr, Y: y.expr}, x.from, y.to)
+	case "@push_relop":
+		b.push(item{tok: b.toks[last]})
+	case "@rel":
+		y, op, x := b.pop(), b.pop(), b.pop()
+		b.pushExpr(&Binary{Op: op.tok, X: x.expr, Y: y.expr}, x.from, y.to)
+	case "@not", "@neg", "@plus":
+		x := b.pop()
+		op := x.from - 1
+		for op > 0 && b.toks[op].Type == lexer.LPAREN {
+			op--
+		}
+		b.pushExpr(&Unary{Op: b.toks[op], X: x.expr}, op, x.to)
+
+	case "@len":
+		x, start := b.pop(), b.start()
+		b.pushExpr(&Len{X: x.expr, Keyword: b.toks[start].Pos, Rparen: b.toks[last].Pos}, start, last)
+
+	case "@index":
+		index, x := b.pop(), b.pop()
+		b.pushExpr(&Index{X: x.expr, Index: index.expr, Rbrack: b.toks[last].Pos}, x.from, last)
+	case "@array_start":
+		b.push(item{lit: &ArrayLit{Lbrack: b.toks[last].Pos}, index: last})
+	case "@array_lit":
+		elems := make([]Expr, 0)
+		for len(b.stack) > 0 && b.top().lit == nil {
+			elems = append([]Expr{b.pop().expr}, elems...)
+		}
+		lit := b.pop()
+		if lit.lit == nil {
+			return
+		}
+		lit.lit.Elems = elems
+		lit.lit.Rbrack = b.toks[last].Pos
+		b.pushExpr(lit.lit, lit.index, last)
+
+	case "@call_start":
+		b.push(item{call: &Call{Name: b.toks[last]}, index: last})
+	case "@arg":
+		arg := b.pop()
+		switch top := b.top(); {
+		case top.call != nil:
+			top.call.Args = append(top.call.Args, arg.expr)
+		case top.target != nil:
+			top.target.Call.Args = append(top.target.Call.Args, arg.expr)
+		}
+	case "@method_call":
+		x := b.pop()
+		b.push(item{call: &Call{Recv: x.expr, Name: b.toks[last]}, index: x.from})
+	case "@call_end":
+		c := b.pop()
+		c.call.Rparen = b.toks[last].Pos
+		b.pushExpr(c.call, c.index, last)
+
+	case "@func_start":
+		fn := &FuncDecl{Name: b.toks[last]}
+		fn.Pos.Start = b.toks[b.start()].Pos
+		b.push(item{fn: fn})
+	case "@method_start":
+		typ, name := b.pop(), b.pop()
+		end := typ.tok.Pos
+		if typ.list != nil {
+			end = typ.list.Pos
+		}
+		recv := &Param{Name: name.tok, Type: typ.tok, Len: typ.size, List: typ.list, Pos: Span{name.tok.Pos, end}}
+		fn := &FuncDecl{Recv: recv, Name: b.toks[last]}
+		fn.Pos.Start = b.toks[b.start()].Pos
+		b.push(item{fn: fn})
+	case "@capture_param_name", "@capture_decl_var", "@capture_field_name":
+		b.push(item{tok: b.toks[last], index: last})
+	case "@array_len":
+		b.push(item{size: &b.toks[last], index: last})
+	case "@list_type":
+		b.push(item{tok: b.toks[last], index: last})
+	case "@capture_type":
+		// a list type is on the stack from @list_type, up to its closing bracket
+		if b.toks[last].Type == lexer.RSBRACE {
+			b.top().list = &b.toks[last]
+			return
+		}
+		// an array type has its length on the stack
+		typ := item{tok: b.toks[last], index: last}
+		if b.top().size != nil {
+			typ.size = b.pop().size
+		}
+		b.push(typ)
+	case "@param":
+		typ, name := b.pop(), b.pop()
+		p := &Param{Name: name.tok, Type: typ.tok, Len: typ.size, List: typ.list, Pos: Span{name.tok.Pos, b.toks[last].Pos}}
+		fn := b.top().fn
+		fn.Params = append(fn.Params, p)
+	case "@func_return_type":
+		if b.toks[last].Type == lexer.RSBRACE {
+			typ := b.pop()
+			b.top().fn.Result = typ.tok
+			b.top().fn.ResultList = &b.toks[last]
+			b.open()
+			return
+		}
+		if b.top().size != nil {
+			size := b.pop().size
+			b.top().fn.ResultLen = size
+		}
+		b.top().fn.Result = b.toks[last]
+		b.open()
+	case "@func_end":
+		fn := b.pop().fn
+		fn.Body = b.close()
+		fn.Pos.End = b.toks[last].Pos
+		b.prog.Decls = append(b.prog.Decls, fn)
+
+	case "@struct_decl":
+		s := &StructDecl{Name: b.toks[last]}
+		s.Pos.Start = b.toks[b.start()].Pos
+		b.push(item{sdecl: s})
+	case "@struct_field":
+		typ, name := b.pop(), b.pop()
+		fd := &FieldDecl{Name: name.tok, Type: typ.tok, Len: typ.size, List: typ.list, Pos: Span{name.tok.Pos, b.toks[last].Pos}}
+		s := b.top().sdecl
+		s.Fields = append(s.Fields, fd)
+	case "@struct_end":
+		s := b.pop().sdecl
+		s.Pos.End = b.toks[last].Pos
+		b.prog.Decls = append(b.prog.Decls, s)
+
+	case "@enum_decl":
+		e := &EnumDecl{Name: b.toks[last]}
+		e.Pos.Start = b.toks[b.start()].Pos
+		b.push(item{edecl: e})
+	case "@variant":
+		e := b.top().edecl
+		e.Variants = append(e.Variants, b.toks[last])
+	case "@enum_end":
+		e := b.pop().edecl
+		e.Pos.End = b.toks[last].Pos
+		b.prog.Decls = append(b.prog.Decls, e)
+
+	case "@define":
+		value, typ, name := b.pop(), b.pop(), b.pop()
+		b.add(&VarDecl{Name: name.tok, Type: typ.tok, Len: typ.size, List: typ.list, Value: value.expr, Pos: Span{b.toks[b.start()].Pos, b.toks[last].Pos}})
+
+	case "@capture_assign_target":
+		b.push(item{tok: b.toks[last], index: last, target: &CallStmt{Call: &Call{Name: b.toks[last]}}})
+	case "@assign":
+		value, target := b.pop(), b.pop()
+		s := &Assign{Target: target.tok, Value: value.expr, Pos: Span{target.tok.Pos, b.toks[last].Pos}}
+		b.add(s)
+		b.semi = &s.Pos
+	case "@bounds":
+		// checks the index, the element is read or written by the next action
+	case "@index_assign":
+		value, index, target := b.pop(), b.pop(), b.pop()
+		s := &Assign{Target: target.tok, Index: index.expr, Rbrack: b.toks[index.to+1].Pos, Value: value.expr, Pos: Span{target.tok.Pos, b.toks[last].Pos}}
+		b.add(s)
+		b.semi = &s.Pos
+	case "@field":
+		// a field read, or one selected on the way to an assigned field
+		if b.top().expr == nil {
+			b.top().fields = append(b.top().fields, b.toks[last])
+			return
+		}
+		x := b.pop()
+		b.pushExpr(&Selector{X: x.expr, Name: b.toks[last]}, x.from, last)
+	case "@field_target":
+		b.top().fields = append(b.top().fields, b.toks[last])
+	case "@field_assign":
+		value, target := b.pop(), b.pop()
+		s := &Assign{Target: target.tok, Fields: target.fields, Value: value.expr, Pos: Span{target.tok.Pos, b.toks[last].Pos}}
+		b.add(s)
+		b.semi = &s.Pos
+	case "@struct_lit_start":
+		b.push(item{slit: &StructLit{Type: b.toks[last]}, index: last})
+	case "@struct_lit":
+		fields := make([]*FieldInit, 0)
+		for len(b.stack) > 1 && b.top().slit == nil {
+			value, name := b.pop(), b.pop()
+			fields = append([]*FieldInit{{Name: name.tok, Value: value.expr}}, fields...)
+		}
+		lit := b.pop()
+		if lit.slit == nil {
+			return
+		}
+		lit.slit.Fields = fields
+		lit.slit.Rbrace = b.toks[last].Pos
+		b.pushExpr(lit.slit, lit.index, last)
+
+	case "@call":
+		target := b.pop()
+		s := target.target
+		s.Call.Rparen = b.toks[last].Pos
+		s.Pos = Span{target.tok.Pos, b.toks[last].Pos}
+		b.add(s)
+		b.semi = &s.Pos
+
+	case "@label_while":
+		b.push(item{loop: &While{Pos: Span{Start: b.toks[last].Pos}}})
+	case "@save":
+		switch {
+		case b.top().ifs != nil:
+			// the else branch of an if
+			b.open()
+		case len(b.stack) > 1 && b.stack[len(b.stack)-2].loop != nil && b.stack[len(b.stack)-2].loop.Cond == nil:
+			cond := b.pop()
+			b.top().loop.Cond = cond.expr
+			b.open()
+		default:
+			cond := b.pop()
+			s := &If{Cond: cond.expr, Pos: Span{Start: b.toks[b.start()].Pos}}
+			b.push(item{ifs: s})
+			b.open()
+		}
+	case "@jmpf", "@jmpf_normal":
+		s := b.top().ifs
+		s.Then = b.close()
+		s.Pos.End = b.toks[last].Pos
+		if actionName == "@jmpf_normal" {
+			b.pop()
+			b.add(s)
+		}
+	case "@jmp":
+		s := b.pop().ifs
+		s.Else = b.close()
+		s.Pos.End = b.toks[last].Pos
+		b.add(s)
+	case "@jmpf_break":
+		s := b.top().loop
+		s.Body = b.close()
+		s.Pos.End = b.toks[last].Pos
+	case "@jmp_nonbackpatch":
+		b.add(b.pop().loop)
+
+	case "@match_start":
+		value := b.pop()
+		s := &Match{Value: value.expr, Rparen: b.toks[last].Pos, Pos: Span{Start: b.toks[b.start()].Pos}}
+		b.push(item{match: s})
+	case "@arm":
+		b.push(item{arm: &Arm{Variant: b.toks[last]}})
+		// the body opens after the =>
+		b.open()
+		b.opens[len(b.opens)-1]++
+	case "@arm_end":
+		a := b.pop().arm
+		a.Body = b.close()
+		a.Pos = Span{a.Variant.Pos, b.toks[last].Pos}
+		s := b.top().match
+		s.Arms = append(s.Arms, a)
+	case "@match_end":
+		s := b.pop().match
+		s.Pos.End = b.toks[last].Pos
+		b.add(s)
+
+	case "@print":
+		value := b.pop()
+		b.add(&Print{Value: value.expr, Pos: Span{b.toks[b.start()].Pos, b.toks[last].Pos}})
+	case "@append":
+		value, list := b.pop(), b.pop()
+		b.add(&Append{List: list.expr, Value: value.expr, Pos: Span{b.toks[b.start()].Pos, b.toks[last].Pos}})
+	case "@return":
+		// a value sits between the keyword and the semicolon
+		start := b.start()
+		s := &Return{Pos: Span{b.toks[start].Pos, b.toks[last].Pos}}
+		if start < last-1 {
+			s.Value = b.pop().expr
+		}
+		b.add(s)
+	case "@continue":
+		b.add(&Continue{Pos: Span{b.toks[b.start()].Pos, b.toks[last].Pos}})
+	case "@save_break":
+		b.add(&Break{Pos: Span{b.toks[b.start()].Pos, b.toks[last].Pos}})
+
+	case "@label":
+		// only positions code, nothing to build
+
+	default:
+		log.Error("Unknown semantic action", "action", actionName)
+	}
+}
+
+// start removes the innermost open construct and returns the index of its keyword
+func (b *Builder) start() int {
+	if len(b.starts) == 0 {
+		return 0
+	}
+	k := b.starts[len(b.starts)-1]
+	b.starts = b.starts[:len(b.starts)-1]
+	return k
+}
+
+// push puts an item on the semantic stack
+func (b *Builder) push(it item) {
+	b.stack = append(b.stack, it)
+}
+
+// pushExpr puts a finished expression covering tokens from..to on the stack
+func (b *Builder) pushExpr(e Expr, from, to int) {
+	b.push(item{expr: e, from: from, to: to})
+}
+
+// pop removes the top of the semantic stack, or returns an empty item
+func (b *Builder) pop() item {
+	if len(b.stack) == 0 {
+		return item{}
+	}
+	it := b.stack[len(b.stack)-1]
+	b.stack = b.stack[:len(b.stack)-1]
+	return it
+}
+
+// top returns the top of the semantic stack, or an empty item
+func (b *Builder) top() *item {
+	if len(b.stack) == 0 {
+		return &item{}
+	}
+	return &b.stack[len(b.stack)-1]
+}
+
+// open starts a block whose opening brace is the next token
+func (b *Builder) open() {
+	b.blocks = append(b.blocks, &Block{})
+	b.opens = append(b.opens, len(b.toks))
+}
+
+// close ends the innermost block at the closing brace just matched
+func (b *Builder) close() *Block {
+	if len(b.blocks) == 0 {
+		return &Block{}
+	}
+	blk := b.blocks[len(b.blocks)-1]
+	open := b.opens[len(b.opens)-1]
+	b.blocks = b.blocks[:len(b.blocks)-1]
+	b.opens = b.opens[:len(b.opens)-1]
+
+	if open < len(b.toks) {
+		blk.Pos = Span{b.toks[open].Pos, b.toks[len(b.toks)-1].Pos}
+	}
+	return blk
+}
+
+// add appends a finished statement to the innermost block or to the program
+func (b *Builder) add(s Stmt) {
+	if len(b.blocks) > 0 {
+		blk := b.blocks[len(b.blocks)-1]
+		blk.Stmts = append(blk.Stmts, s)
+		return
+	}
+	b.prog.Decls = append(b.prog.Decls, s)
+}
@@ -0,0 +1,349 @@
+package ast
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"dolme/pkg/parser/codegen"
+)
+
+// binaryActions maps arithmetic and logical operators to their semantic action
+var binaryActions = map[lexer.TokenType]string{
+	lexer.PLUS:  "@add",
+	lexer.MINUS: "@sub",
+	lexer.MULT:  "@mul",
+	lexer.DIV:   "@div",
+	lexer.MOD:   "@mod",
+	lexer.AND:   "@and",
+	lexer.OR:    "@or",
+}
+
+// unaryActions maps prefix operators to their semantic action
+var unaryActions = map[lexer.TokenType]string{
+	lexer.NOT:   "@not",
+	lexer.MINUS: "@neg",
+	lexer.PLUS:  "@plus",
+}
+
+// lowerer replays a tree as the tokens and semantic actions the parser would emit for it
+type lowerer struct {
+	t parser.Translator
+}
+
+// Lower feeds prog to t as semantic actions in the order the parser emits
+// them. Only the tokens the actions read are sent, each right before the
+// action that reads it, so a code generator fed this way produces the same
+// instructions and the same semantic errors as the parse that built prog.
+func Lower(prog *Program, t parser.Translator) {
+	l := &lowerer{t: t}
+	for _, d := range prog.Decls {
+		l.decl(d)
+	}
+}
+
+// Compile lowers prog into a fresh code generator and returns the program
+// block, ending with a nop like parser.GetIRCode, and the code generator
+func Compile(prog *Program) ([]codegen.Instruction, *codegen.Codegen) {
+	cg := codegen.NewCodegen()
+	Lower(prog, cg)
+
+	return append(cg.GetProgram(), codegen.Instruction{Op: codegen.OpNop, Type: lexer.EOF}), cg
+}
+
+// token sends a matched token
+func (l *lowerer) token(tok lexer.Token) {
+	l.t.SetCurrentToken(tok)
+}
+
+// punct sends a matched punctuation token at pos
+func (l *lowerer) punct(typ lexer.TokenType, lexeme string, pos lexer.Position) {
+	l.t.SetCurrentToken(lexer.NewToken(typ, lexeme, "", pos))
+}
+
+// action sends a semantic action
+func (l *lowerer) action(name string) {
+	l.t.ExecuteAction(name)
+}
+
+func (l *lowerer) decl(d Decl) {
+	if s, ok := d.(*StructDecl); ok {
+		l.structDecl(s)
+		return
+	}
+	if e, ok := d.(*EnumDecl); ok {
+		l.enumDecl(e)
+		return
+	}
+	fn, ok := d.(*FuncDecl)
+	if !ok {
+		l.stmt(d.(Stmt))
+		return
+	}
+
+	if fn.Recv != nil {
+		l.token(fn.Recv.Name)
+		l.action("@capture_param_name")
+		l.typ(fn.Recv.Type, fn.Recv.Len, fn.Recv.List)
+		l.token(fn.Name)
+		l.action("@method_start")
+	} else {
+		l.token(fn.Name)
+		l.action("@func_start")
+	}
+	for _, p := range fn.Params {
+		l.token(p.Name)
+		l.action("@capture_param_name")
+		l.typ(p.Type, p.Len, p.List)
+		l.action("@param")
+	}
+	if fn.ResultLen != nil {
+		l.token(*fn.ResultLen)
+		l.action("@array_len")
+	}
+	l.token(fn.Result)
+	if fn.ResultList != nil {
+		l.action("@list_type")
+		l.token(*fn.ResultList)
+	}
+	l.action("@func_return_type")
+	l.block(fn.Body)
+	l.action("@func_end")
+}
+
+func (l *lowerer) structDecl(s *StructDecl) {
+	l.token(s.Name)
+	l.action("@struct_decl")
+	for _, f := range s.Fields {
+		l.token(f.Name)
+		l.action("@capture_field_name")
+		l.typ(f.Type, f.Len, f.List)
+		l.action("@struct_field")
+	}
+	l.punct(lexer.RBRACE, "}", s.Pos.End)
+	l.action("@struct_end")
+}
+
+func (l *lowerer) enumDecl(e *EnumDecl) {
+	l.token(e.Name)
+	l.action("@enum_decl")
+	for _, v := range e.Variants {
+		l.token(v)
+		l.action("@variant")
+	}
+	l.punct(lexer.RBRACE, "}", e.Pos.End)
+	l.action("@enum_end")
+}
+
+// typ sends a type, with the length first for an array and the element
+// type first for a list
+func (l *lowerer) typ(typ lexer.Token, size, list *lexer.Token) {
+	if size != nil {
+		l.token(*size)
+		l.action("@array_len")
+	}
+	l.token(typ)
+	if list != nil {
+		l.action("@list_type")
+		l.token(*list)
+	}
+	l.action("@capture_type")
+}
+
+// block sends the statements of b followed by its closing brace
+func (l *lowerer) block(b *Block) {
+	l.punct(lexer.LBRACE, "{", b.Pos.Start)
+	for _, s := range b.Stmts {
+		l.stmt(s)
+	}
+	l.punct(lexer.RBRACE, "}", b.Pos.End)
+}
+
+func (l *lowerer) stmt(s Stmt) {
+	switch s := s.(type) {
+	case *VarDecl:
+		l.token(s.Name)
+		l.action("@capture_decl_var")
+		l.typ(s.Type, s.Len, s.List)
+		l.expr(s.Value)
+		l.punct(lexer.SEMICOLON, ";", s.Pos.End)
+		l.action("@define")
+
+	case *Assign:
+		l.token(s.Target)
+		l.action("@capture_assign_target")
+		if n := len(s.Fields); n > 0 {
+			for _, f := range s.Fields[:n-1] {
+				l.token(f)
+				l.action("@field")
+			}
+			l.token(s.Fields[n-1])
+			l.action("@field_target")
+			l.expr(s.Value)
+			l.action("@field_assign")
+			return
+		}
+		if s.Index != nil {
+			l.expr(s.Index)
+			l.punct(lexer.RSBRACE, "]", s.Rbrack)
+			l.action("@bounds")
+			l.expr(s.Value)
+			l.action("@index_assign")
+			return
+		}
+		l.expr(s.Value)
+		l.action("@assign")
+
+	case *CallStmt:
+		l.token(s.Call.Name)
+		l.action("@capture_assign_target")
+		for _, a := range s.Call.Args {
+			l.expr(a)
+			l.action("@arg")
+		}
+		l.punct(lexer.RPAREN, ")", s.Call.Rparen)
+		l.action("@call")
+
+	case *If:
+		l.expr(s.Cond)
+		l.action("@save")
+		l.block(s.Then)
+		if s.Else == nil {
+			l.action("@jmpf_normal")
+			return
+		}
+		l.action("@jmpf")
+		l.action("@save")
+		l.block(s.Else)
+		l.action("@jmp")
+
+	case *While:
+		l.action("@label_while")
+		l.expr(s.Cond)
+		l.action("@save")
+		l.block(s.Body)
+		l.action("@jmpf_break")
+		l.action("@jmp_nonbackpatch")
+
+	case *Match:
+		l.expr(s.Value)
+		l.punct(lexer.RPAREN, ")", s.Rparen)
+		l.action("@match_start")
+		for _, a := range s.Arms {
+			l.token(a.Variant)
+			l.action("@arm")
+			l.block(a.Body)
+			l.action("@arm_end")
+		}
+		l.punct(lexer.RBRACE, "}", s.Pos.End)
+		l.action("@match_end")
+
+	case *Print:
+		l.expr(s.Value)
+		l.action("@print")
+
+	case *Append:
+		l.expr(s.List)
+		l.expr(s.Value)
+		l.punct(lexer.SEMICOLON, ";", s.Pos.End)
+		l.action("@append")
+
+	case *Return:
+		if s.Value != nil {
+			l.expr(s.Value)
+		}
+		l.action("@return")
+
+	case *Continue:
+		l.action("@continue")
+
+	case *Break:
+		l.action("@save_break")
+	}
+}
+
+func (l *lowerer) expr(e Expr) {
+	switch e := e.(type) {
+	case *Literal:
+		l.token(e.Value)
+		l.action("@push")
+
+	case *Ident:
+		l.token(e.Name)
+		l.action("@load")
+
+	case *Paren:
+		l.punct(lexer.LPAREN, "(", e.Lparen)
+		l.expr(e.X)
+		l.punct(lexer.RPAREN, ")", e.Rparen)
+
+	case *Call:
+		if e.Recv != nil {
+			l.expr(e.Recv)
+			l.token(e.Name)
+			l.action("@method_call")
+		} else {
+			l.token(e.Name)
+			l.action("@call_start")
+		}
+		for _, a := range e.Args {
+			l.expr(a)
+			l.action("@arg")
+		}
+		l.punct(lexer.RPAREN, ")", e.Rparen)
+		l.action("@call_end")
+
+	case *Index:
+		l.expr(e.X)
+		l.expr(e.Index)
+		l.punct(lexer.RSBRACE, "]", e.Rbrack)
+		l.action("@bounds")
+		l.action("@index")
+
+	case *ArrayLit:
+		l.punct(lexer.LSBRACE, "[", e.Lbrack)
+		l.action("@array_start")
+		for _, el := range e.Elems {
+			l.expr(el)
+		}
+		l.punct(lexer.RSBRACE, "]", e.Rbrack)
+		l.action("@array_lit")
+
+	case *Selector:
+		l.expr(e.X)
+		l.token(e.Name)
+		l.action("@field")
+
+	case *StructLit:
+		l.token(e.Type)
+		l.action("@struct_lit_start")
+		for _, f := range e.Fields {
+			l.token(f.Name)
+			l.action("@capture_field_name")
+			l.expr(f.Value)
+		}
+		l.punct(lexer.RBRACE, "}", e.Rbrace)
+		l.action("@struct_lit")
+
+	case *Len:
+		l.expr(e.X)
+		l.punct(lexer.RPAREN, ")", e.Rparen)
+		l.action("@len")
+
+	case *Unary:
+		l.token(e.Op)
+		l.expr(e.X)
+		l.action(unaryActions[e.Op.Type])
+
+	case *Binary:
+		l.expr(e.X)
+		if action, ok := binaryActions[e.Op.Type]; ok {
+			l.expr(e.Y)
+			l.action(action)
+			return
+		}
+		// relational operators are pushed before the right operand
+		l.token(e.Op)
+		l.action("@push_relop")
+		l.expr(e.Y)
+		l.action("@rel")
+	}
+}
@@ -0,0 +1,301 @@
+package ast_test
+
+import (
+	"dolme/pkg/ast"
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const program = `func pow(a: float, b: int): float {
+    let c : float = 1.0;
+    while (b > 0) {
+        c = c * a;
+        b = b - 1;
+    }
+    return c;
+}
+
+let x : float = pow(2.0, 10) * (3 - 1);
+if (not x < 3 and true) {
+    print(x);
+} else {
+    x = 0.0;
+}
+while (x > 0) {
+    if (x == 5) {
+        break;
+    }
+    x = x - 1;
+    continue;
+}
+`
+
+// build parses src into a syntax tree
+func build(t *testing.T, src string) *ast.Program {
+	t.Helper()
+
+	b := ast.NewBuilder()
+	p := parser.NewParser(lexer.NewLexer(src), parser.WithTranslator(b))
+	p.Parse()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("syntax errors: %v", errs)
+	}
+
+	return b.Program()
+}
+
+func TestBuilder(t *testing.T) {
+	prog := build(t, program)
+
+	if len(prog.Decls) != 4 {
+		t.Fatalf("expected 4 declarations, got %d", len(prog.Decls))
+	}
+
+	fn, ok := prog.Decls[0].(*ast.FuncDecl)
+	if !ok {
+		t.Fatalf("expected a function, got %T", prog.Decls[0])
+	}
+	if fn.Name.Lexeme != "pow" || len(fn.Params) != 2 || fn.Params[1].Name.Lexeme != "b" || fn.Params[1].Type.Type != lexer.INT || fn.Result.Type != lexer.FLOAT {
+		t.Errorf("unexpected signature for %s", fn.Name.Lexeme)
+	}
+	if len(fn.Body.Stmts) != 3 {
+		t.Fatalf("expected 3 statements in pow, got %d", len(fn.Body.Stmts))
+	}
+	if loop, ok := fn.Body.Stmts[1].(*ast.While); !ok || len(loop.Body.Stmts) != 2 {
+		t.Errorf("expected a while with two statements, got %#v", fn.Body.Stmts[1])
+	}
+	if ret, ok := fn.Body.Stmts[2].(*ast.Return); !ok || ret.Value.(*ast.Ident).Name.Lexeme != "c" {
+		t.Errorf("expected return c, got %#v", fn.Body.Stmts[2])
+	}
+
+	// pow(2.0, 10) * (3 - 1)
+	decl := prog.Decls[1].(*ast.VarDecl)
+	mul, ok := decl.Value.(*ast.Binary)
+	if !ok || mul.Op.Type != lexer.MULT {
+		t.Fatalf("expected a multiplication, got %#v", decl.Value)
+	}
+	if call, ok := mul.X.(*ast.Call); !ok || call.Name.Lexeme != "pow" || len(call.Args) != 2 {
+		t.Errorf("expected a call to pow with two arguments, got %#v", mul.X)
+	}
+	paren, ok := mul.Y.(*ast.Paren)
+	if !ok {
+		t.Fatalf("expected a parenthesized operand, got %#v", mul.Y)
+	}
+	if sub, ok := paren.X.(*ast.Binary); !ok || sub.Op.Type != lexer.MINUS {
+		t.Errorf("expected a subtraction inside the parentheses, got %#v", paren.X)
+	}
+
+	// not x < 3 and true
+	cond := prog.Decls[2].(*ast.If)
+	and, ok := cond.Cond.(*ast.Binary)
+	if !ok || and.Op.Type != lexer.AND {
+		t.Fatalf("expected an and, got %#v", cond.Cond)
+	}
+	if not, ok := and.X.(*ast.Unary); !ok || not.Op.Type != lexer.NOT || not.X.(*ast.Binary).Op.Type != lexer.LT {
+		t.Errorf("expected not applied to a comparison, got %#v", and.X)
+	}
+	if cond.Else == nil || len(cond.Else.Stmts) != 1 {
+		t.Errorf("expected an else branch with one statement")
+	}
+
+	loop := prog.Decls[3].(*ast.While)
+	if _, ok := loop.Body.Stmts[0].(*ast.If).Then.Stmts[0].(*ast.Break); !ok {
+		t.Errorf("expected a break inside the nested if")
+	}
+	if _, ok := loop.Body.Stmts[2].(*ast.Continue); !ok {
+		t.Errorf("expected a continue at the end of the loop")
+	}
+
+	calls := 0
+	ast.Inspect(prog, func(n ast.Node) bool {
+		if _, ok := n.(*ast.Call); ok {
+			calls++
+		}
+		return true
+	})
+	if calls != 1 {
+		t.Errorf("expected Inspect to find 1 call, got %d", calls)
+	}
+}
+
+func TestSpans(t *testing.T) {
+	prog := build(t, program)
+
+	fn := prog.Decls[0].(*ast.FuncDecl)
+	body := fn.Body.Span()
+	if fn.Span().Start.Line != 1 || fn.Span().End.Line != 8 || body.Start.Line != 1 || body.End != fn.Span().End {
+		t.Errorf("unexpected function span %v, body %v", fn.Span(), body)
+	}
+
+	decl := prog.Decls[1].(*ast.VarDecl)
+	start, end := decl.Span().Start, decl.Span().End
+	if start.Line != 10 || end.Line != 10 || end.Column <= start.Column {
+		t.Errorf("unexpected declaration span %v", decl.Span())
+	}
+	paren := decl.Value.(*ast.Binary).Y.(*ast.Paren)
+	if decl.Value.Span().End != paren.Rparen {
+		t.Errorf("expected the product to end at the closing parenthesis")
+	}
+
+	assign := prog.Decls[2].(*ast.If).Else.Stmts[0].(*ast.Assign)
+	if assign.Span().Start != assign.Target.Pos || assign.Span().End.Line != 14 || assign.Span().End.Column <= assign.Value.Span().End.Column {
+		t.Errorf("expected the assignment to run up to its semicolon, got %v", assign.Span())
+	}
+
+	// constructs start at their keyword whatever their operand starts with
+	nested := build(t, "if ((1 < 2)) {\n    print((len(\"ab\")));\n}\n")
+	cond := nested.Decls[0].(*ast.If)
+	if start := cond.Span().Start; start.Line != 1 || start.Column != 1 {
+		t.Errorf("expected the if to start at its keyword, got %v", cond.Span())
+	}
+	print := cond.Then.Stmts[0].(*ast.Print)
+	if start := print.Span().Start; start.Line != 2 || start.Column != 5 {
+		t.Errorf("expected the print to start at its keyword, got %v", print.Span())
+	}
+	if n := print.Value.(*ast.Paren).X.(*ast.Len); n.Keyword.Line != 2 || n.Keyword.Column != 12 {
+		t.Errorf("expected len to start at its keyword, got %v", n.Span())
+	}
+}
+
+func TestLowerMatchesParser(t *testing.T) {
+	sources := map[string]string{
+		"program": program,
+		"errors": `
+let a : int = 1;
+let a : int = 2.0 + b;
+a = 1.5;
+print(c);
+`,
+		"strings": `
+func twice(s: string): string {
+    return s + s;
+}
+let s : string = twice("ab\n");
+print(len((s)) + -len(s + "c"));
+print(s == "x" or not (s != "y"));
+`,
+		"arrays": `
+func sum(xs: [3]float): float {
+    let s : float = 0.0;
+    let i : int = 0;
+    while (i < len(xs)) {
+        s = s + xs[i];
+        i = i + 1;
+    }
+    return s;
+}
+let xs : [3]float = [1, 2.5, -(3)];
+let ys : [2]int = [];
+xs[ys[1]] = xs[(2)] * 2;
+print(sum(xs));
+let b : [2]bool = [true, 1];
+`,
+		"lists": `
+func evens(xs: list[int]): list[float] {
+    let out : list[float] = [];
+    let i : int = 0;
+    while (i < len(xs)) {
+        if (xs[i] % 2 == 0) {
+            append(out, xs[i]);
+        }
+        i = i + 1;
+    }
+    return out;
+}
+let xs : list[int] = [1, 2, 4];
+append(xs, len(xs));
+xs[0] = xs[(1)] + 1;
+let ys : list[float] = evens(xs);
+print(ys[0]);
+append(xs, 1.5);
+append(len(xs), 1);
+`,
+		"structs": `
+struct Point { x: float, y: float }
+struct Body { pos: Point, hits: list[int], name: string }
+func shift(b: Body, d: float): Point {
+    b.pos.x = b.pos.x + d;
+    append(b.hits, 1);
+    return b.pos;
+}
+let b : Body = Body { pos: Point { x: 1, y: (2) }, name: "b" };
+let p : Point = shift(b, 0.5);
+print(p.x + b.pos.y);
+b.name = b.name + "!";
+let q : Point = Point { z: 1 };
+print(b.mass);
+`,
+		"methods": `
+struct Point { x: float, y: float }
+func (p: Point) norm2(): float {
+    return p.x * p.x + p.y * p.y;
+}
+func (p: Point) add(q: Point): Point {
+    return Point { x: p.x + q.x, y: p.y + q.y };
+}
+func (n: int) twice(): int { return n * 2; }
+let a : Point = Point { x: 1, y: 2 };
+print(a.add(Point { x: 3, y: (4) }).norm2() + a.norm2());
+print(a.y.norm2());
+print(a.scale(2));
+`,
+		"enums": `
+enum Color { Red, Green, Blue }
+struct Light { color: Color }
+func next(c: Color): Color {
+    match (c) {
+        Red => { return Green; }
+        Green => { return Blue; }
+        Blue => { return Red; }
+    }
+    return Red;
+}
+let l : Light = Light { color: Red };
+let i : int = 0;
+while (i < 3) {
+    match ((l.color)) {
+        Red => { print("red"); }
+        Green => {
+            match (next(l.color)) {
+                Blue => { break; }
+            }
+        }
+        Purple => {}
+    }
+    l.color = next(l.color);
+    i = i + 1;
+}
+print(l.color == Blue);
+match (i) {}
+`,
+	}
+
+	examples, _ := filepath.Glob("../../../example/*.dolme")
+	for _, path := range examples {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sources[filepath.Base(path)] = string(src)
+	}
+
+	for name, src := range sources {
+		p := parser.NewParser(lexer.NewLexer(src))
+		p.Parse()
+		if errs := p.Errors(); len(errs) > 0 {
+			t.Fatalf("%s: syntax errors: %v", name, errs)
+		}
+
+		pb, cg := ast.Compile(build(t, src))
+		if want := p.GetIRCode(); !reflect.DeepEqual(pb, want) {
+			t.Errorf("%s: lowering differs from the parser\nwant %v\ngot  %v", name, want, pb)
+		}
+		if want := p.GetSemanticErrors(); !reflect.DeepEqual(cg.GetErrors(), want) || (name == "errors" && len(want) != 3) {
+			t.Errorf("%s: semantic errors differ\nwant %v\ngot  %v", name, want, cg.GetErrors())
+		}
+	}
+}
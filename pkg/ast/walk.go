@@ -0,0 +1,95 @@
+package ast
+
+// Inspect visits node and its children depth-first, in source order. When f
+// returns false the children of that node are skipped.
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		for _, d := range n.Decls {
+			Inspect(d, f)
+		}
+	case *StructDecl:
+		for _, fd := range n.Fields {
+			Inspect(fd, f)
+		}
+	case *FuncDecl:
+		if n.Recv != nil {
+			Inspect(n.Recv, f)
+		}
+		for _, p := range n.Params {
+			Inspect(p, f)
+		}
+		Inspect(n.Body, f)
+	case *Block:
+		for _, s := range n.Stmts {
+			Inspect(s, f)
+		}
+	case *VarDecl:
+		Inspect(n.Value, f)
+	case *Assign:
+		if n.Index != nil {
+			Inspect(n.Index, f)
+		}
+		Inspect(n.Value, f)
+	case *CallStmt:
+		Inspect(n.Call, f)
+	case *If:
+		Inspect(n.Cond, f)
+		Inspect(n.Then, f)
+		if n.Else != nil {
+			Inspect(n.Else, f)
+		}
+	case *While:
+		Inspect(n.Cond, f)
+		Inspect(n.Body, f)
+	case *Match:
+		Inspect(n.Value, f)
+		for _, a := range n.Arms {
+			Inspect(a, f)
+		}
+	case *Arm:
+		Inspect(n.Body, f)
+	case *Print:
+		Inspect(n.Value, f)
+	case *Append:
+		Inspect(n.List, f)
+		Inspect(n.Value, f)
+	case *Return:
+		if n.Value != nil {
+			Inspect(n.Value, f)
+		}
+	case *Binary:
+		Inspect(n.X, f)
+		Inspect(n.Y, f)
+	case *Unary:
+		Inspect(n.X, f)
+	case *Call:
+		if n.Recv != nil {
+			Inspect(n.Recv, f)
+		}
+		for _, a := range n.Args {
+			Inspect(a, f)
+		}
+	case *Index:
+		Inspect(n.X, f)
+		Inspect(n.Index, f)
+	case *ArrayLit:
+		for _, e := range n.Elems {
+			Inspect(e, f)
+		}
+	case *Selector:
+		Inspect(n.X, f)
+	case *StructLit:
+		for _, fi := range n.Fields {
+			Inspect(fi.Value, f)
+		}
+	case *Len:
+		Inspect(n.X, f)
+	case *Paren:
+		Inspect(n.X, f)
+	}
+}
@@ -0,0 +1,182 @@
+package interpreter
+
+import (
+	"dolme/pkg/lexer"
+	"fmt"
+)
+
+// Sizes the heap accounts for: a list header and each element of a list or
+// field of a struct, the way the native backends lay them out
+const (
+	listHeaderSize = 24
+	elemSize       = 8
+
+	minCollectAt = 64 << 10 // heap size of the first collection
+)
+
+// object is the heap object behind a KindList, KindStruct or KindArray
+// value: the elements of a list or an array or the fields of a struct
+type object struct {
+	elems  []Value
+	header int // bytes held besides the elements, the header of a list
+	marked bool
+}
+
+// size returns the bytes held by the object
+func (o *object) size() int {
+	return o.header + elemSize*len(o.elems)
+}
+
+// heap holds the lists, structs and arrays of a run. They are never freed by the
+// program: a mark-and-sweep collection frees those no variable reaches any more.
+type heap struct {
+	objects   map[int]*object // Ref of a list, struct or array value -> its object
+	next      int             // Ref of the next object
+	size      int             // bytes held by the objects
+	collectAt int             // size that triggers the next collection
+	limit     int             // largest size, 0 = unlimited
+}
+
+// newHeap creates an empty heap of at most limit bytes, 0 = unlimited
+func newHeap(limit int) *heap {
+	return &heap{objects: make(map[int]*object), next: 1, collectAt: minCollectAt, limit: limit}
+}
+
+// WithHeapLimit caps the bytes held by lists, structs and arrays; an append, a new
+// list, a new struct or a new array beyond it fails with
+// ErrHeapLimitExceeded once a collection cannot make room
+func WithHeapLimit(n int) Option {
+	return func(i *Interpreter) { i.heap.limit = n }
+}
+
+// HeapSize returns the bytes held by the lists, structs and arrays not collected yet
+func (i *Interpreter) HeapSize() int {
+	return i.heap.size
+}
+
+// reserve makes room for n more bytes on the heap, collecting first when
+// the heap has grown enough since the last collection or would pass its limit
+func (i *Interpreter) reserve(n int) error {
+	h := i.heap
+	if h.size+n > h.collectAt || (h.limit > 0 && h.size+n > h.limit) {
+		i.Collect()
+	}
+	if h.limit > 0 && h.size+n > h.limit {
+		return fmt.Errorf("%w: %d bytes in use, %d more requested, limit %d", ErrHeapLimitExceeded, h.size, n, h.limit)
+	}
+
+	h.size += n
+	return nil
+}
+
+// allocate puts o on the heap and returns a value of kind referring to it
+func (i *Interpreter) allocate(kind ValueKind, o *object) (Value, error) {
+	if err := i.reserve(o.size()); err != nil {
+		return Value{}, err
+	}
+
+	h := i.heap
+	ref := h.next
+	h.next++
+	h.objects[ref] = o
+	return Value{Kind: kind, Ref: ref, Valid: true}, nil
+}
+
+// newList allocates an empty list on the heap
+func (i *Interpreter) newList() (Value, error) {
+	return i.allocate(KindList, &object{header: listHeaderSize})
+}
+
+// newStruct allocates a struct of size bytes on the heap, its fields zero
+// ints until the stores that follow it
+func (i *Interpreter) newStruct(size int) (Value, error) {
+	fields := make([]Value, size/elemSize)
+	for k := range fields {
+		fields[k] = newInt(0)
+	}
+	return i.allocate(KindStruct, &object{elems: fields})
+}
+
+// newArray allocates an array of n zero elements of type elem on the heap
+func (i *Interpreter) newArray(n int, elem lexer.TokenType) (Value, error) {
+	arr := make([]Value, n)
+	for k := range arr {
+		arr[k] = zeroValue(elem)
+	}
+	v, err := i.allocate(KindArray, &object{elems: arr})
+	v.Arr = arr
+	return v, err
+}
+
+// object returns the heap object of a value of kind, a list or a struct
+func (i *Interpreter) object(v Value, kind ValueKind) (*object, error) {
+	if v.Kind != kind {
+		return nil, fmt.Errorf("%v is not a %v", v.Kind, kind)
+	}
+	o, ok := i.heap.objects[v.Ref]
+	if !ok {
+		return nil, fmt.Errorf("object %d was collected", v.Ref)
+	}
+	return o, nil
+}
+
+// appendValue adds v at the end of the list l
+func (i *Interpreter) appendValue(l *object, v Value) error {
+	if err := i.reserve(elemSize); err != nil {
+		return err
+	}
+	l.elems = append(l.elems, v)
+	return nil
+}
+
+// Collect frees the lists, structs and arrays that cannot be reached any more. The
+// roots are the globals, the locals and temps of every frame and the staged
+// call arguments; an object is reached from a root or from the elements or
+// fields of a reached list, struct or array.
+func (i *Interpreter) Collect() {
+	h := i.heap
+	for _, v := range i.globals {
+		i.mark(v)
+	}
+	for _, f := range i.stack {
+		for _, v := range f.Locals {
+			i.mark(v)
+		}
+	}
+	for _, v := range i.argBuf {
+		i.mark(v)
+	}
+
+	for ref, o := range h.objects {
+		if !o.marked {
+			h.size -= o.size()
+			delete(h.objects, ref)
+			continue
+		}
+		o.marked = false
+	}
+
+	h.collectAt = max(2*h.size, minCollectAt)
+}
+
+// mark marks the lists, structs and arrays reached from v
+func (i *Interpreter) mark(v Value) {
+	switch v.Kind {
+	case KindList, KindStruct, KindArray:
+		o, ok := i.heap.objects[v.Ref]
+		if !ok {
+			// the elements of a list seen as an array belong to the list
+			for _, e := range v.Arr {
+				i.mark(e)
+			}
+			return
+		}
+		if o.marked {
+			return
+		}
+		o.marked = true
+		for _, e := range o.elems {
+			i.mark(e)
+		}
+	}
+}
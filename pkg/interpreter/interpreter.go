@@ -13,8 +13,6 @@ import (
 	"dolme/pkg/parser/codegen"
 )
 
-const LocalAddrBase = 800
-
 // Interpreter executes three-address IR (PB) produced by codegen
 type Interpreter struct {
 	pb []codegen.Instruction // program block (list of instructions)
@@ -22,6 +20,8 @@ type Interpreter struct {
 
 	globals map[int]Value // global variables (addr -> value)
 
+	heap *heap // lists, with their collector
+
 	stack []*Frame // call stack (frames)
 
 	argBuf map[int]Value // argument staging buffer (pos -> value)
@@ -58,6 +58,7 @@ func NewInterpreter(pb []codegen.Instruction, opts ...Option) *Interpreter {
 		pb:         append([]codegen.Instruction(nil), pb...),
 		ip:         0,
 		globals:    make(map[int]Value),
+		heap:       newHeap(0),
 		stack:      make([]*Frame, 0, 8),
 		argBuf:     make(map[int]Value),
 		labelIndex: make(map[int]string),
@@ -91,10 +92,11 @@ func (i *Interpreter) Load(pb []codegen.Instruction) {
 	i.indexProgram()
 }
 
-// Reset clears runtime state (globals, call stack, IP, counters)
+// Reset clears runtime state (globals, heap, call stack, IP, counters)
 func (i *Interpreter) Reset() {
 	i.ip = 0
 	i.globals = make(map[int]Value)
+	i.heap = newHeap(i.heap.limit)
 	i.stack = i.stack[:0]
 	i.argBuf = make(map[int]Value)
 	i.steps = 0
@@ -198,42 +200,25 @@ func (i *Interpreter) PopFrame() *Frame {
 	return f
 }
 
-// SetVar writes a value to an address, considering frame scoping for locals (>= LocalAddrBase)
+// SetVar writes a value to an address, considering frame scoping for temps and locals (>= TempAddrBase)
 func (i *Interpreter) SetVar(addr int, v Value) {
-	if f := i.currentFrame(); f != nil && addr >= LocalAddrBase {
+	// Temps and locals belong to the active frame, so recursive calls and
+	// reused temp addresses never clobber the caller's values.
+	if f := i.currentFrame(); f != nil && addr >= codegen.TempAddrBase {
 		f.Locals[addr] = v
 		return
 	}
 
-	// Temps (<800) used inside functions can be treated as frame-locals too, but
-	// we'll keep them in frame by preference if present.
-	if f := i.currentFrame(); f != nil {
-		if addr < LocalAddrBase {
-			if _, ok := f.Locals[addr]; ok {
-				f.Locals[addr] = v
-				return
-			}
-		}
-	}
-
 	i.globals[addr] = v
 }
 
-// GetVar reads a value from an address, considering frame scoping for locals (>= LocalAddrBase)
+// GetVar reads a value from an address, considering frame scoping for temps and locals (>= TempAddrBase)
 func (i *Interpreter) GetVar(addr int) (Value, bool) {
-	if f := i.currentFrame(); f != nil && addr >= LocalAddrBase {
+	if f := i.currentFrame(); f != nil && addr >= codegen.TempAddrBase {
 		v, ok := f.Locals[addr]
 		return v, ok
 	}
 
-	if f := i.currentFrame(); f != nil {
-		if addr < LocalAddrBase {
-			if v, ok := f.Locals[addr]; ok {
-				return v, ok
-			}
-		}
-	}
-
 	v, ok := i.globals[addr]
 	return v, ok
 }
@@ -302,12 +287,12 @@ func (i *Interpreter) SetVarTyped(addr int, typ lexer.TokenType, raw any) error
 			return fmt.Errorf("unsupported raw for BOOL: %T", raw)
 		}
 
-	case lexer.STRING:
+	case lexer.STR, lexer.STRING:
 		switch x := raw.(type) {
 		case string:
 			i.SetVar(addr, newString(x))
 		default:
-			return fmt.Errorf("unsupported raw for STRING: %T", raw)
+			return fmt.Errorf("unsupported raw for STR: %T", raw)
 		}
 
 	default:
@@ -372,6 +357,7 @@ func (i *Interpreter) ClearArgs() {
 }
 
 var (
-	ErrNotImplemented   = errors.New("interpreter step function not linked")
-	ErrMaxStepsExceeded = errors.New("maximum steps exceeded")
+	ErrNotImplemented    = errors.New("interpreter step function not linked")
+	ErrMaxStepsExceeded  = errors.New("maximum steps exceeded")
+	ErrHeapLimitExceeded = errors.New("heap limit exceeded")
 )
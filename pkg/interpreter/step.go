@@ -58,11 +58,15 @@ func coreStep(i *Interpreter) (bool, error) {
 		if err != nil {
 			return false, err
 		}
+		if in.Type == lexer.FLOAT && val.Kind == KindInt {
+			val = newFloat(float64(val.I64)) // int widened to float
+		}
 		i.SetVar(dst, val)
 		i.SetPC(pc + 1)
 		return false, nil
 
 	case codegen.OpAdd, codegen.OpSub, codegen.OpMul, codegen.OpDiv, codegen.OpMod,
+		codegen.OpShl, codegen.OpShr, codegen.OpUShr,
 		codegen.OpAnd, codegen.OpOr,
 		codegen.OpEq, codegen.OpNeq, codegen.OpLt, codegen.OpLe, codegen.OpGt, codegen.OpGe:
 		// Arg1, Arg2 operands; Arg3 destination
@@ -83,6 +87,225 @@ func coreStep(i *Interpreter) (bool, error) {
 		i.SetPC(pc + 1)
 		return false, nil
 
+	case codegen.OpNot:
+		// Arg1 operand; Arg3 destination
+		dst, _ := in.Arg3.(int)
+		v, err := i.loadOperand(in.Arg1, lexer.BOOL)
+		if err != nil {
+			return false, err
+		}
+		b, err := v.AsBool()
+		if err != nil {
+			return false, err
+		}
+		i.SetVar(dst, newBool(!b))
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpNeg:
+		// Arg1 operand; Arg3 destination
+		dst, _ := in.Arg3.(int)
+		v, err := i.loadOperand(in.Arg1, in.Type)
+		if err != nil {
+			return false, err
+		}
+		switch v.Kind {
+		case KindInt:
+			i.SetVar(dst, newInt(-v.I64))
+		case KindFloat:
+			i.SetVar(dst, newFloat(-v.F64))
+		default:
+			return false, fmt.Errorf("cannot negate %v", v.Kind)
+		}
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpConcat:
+		// Arg1, Arg2 strings; Arg3 destination
+		dst, _ := in.Arg3.(int)
+		v1, err := i.loadOperand(in.Arg1, lexer.STR)
+		if err != nil {
+			return false, err
+		}
+		v2, err := i.loadOperand(in.Arg2, lexer.STR)
+		if err != nil {
+			return false, err
+		}
+		if v1.Kind != KindString || v2.Kind != KindString {
+			return false, fmt.Errorf("cannot concatenate %v and %v", v1.Kind, v2.Kind)
+		}
+		i.SetVar(dst, newString(v1.Str+v2.Str))
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpLen:
+		// Arg1 string; Arg3 destination
+		dst, _ := in.Arg3.(int)
+		v, err := i.loadOperand(in.Arg1, lexer.STR)
+		if err != nil {
+			return false, err
+		}
+		if v.Kind != KindString {
+			return false, fmt.Errorf("cannot take the length of %v", v.Kind)
+		}
+		i.SetVar(dst, newInt(int64(len(v.Str))))
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpAlloc:
+		// Arg1 length immediate; Arg3 destination; Type element type
+		dst, _ := in.Arg3.(int)
+		n, err := i.loadOperand(in.Arg1, lexer.INT)
+		if err != nil {
+			return false, err
+		}
+		arr, err := i.newArray(int(n.I64), in.Type)
+		if err != nil {
+			return false, err
+		}
+		i.SetVar(dst, arr)
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpIndexLoad:
+		// Arg1 array, Arg2 index; Arg3 destination
+		dst, _ := in.Arg3.(int)
+		arr, idx, err := i.element(in.Arg1, in.Arg2)
+		if err != nil {
+			return false, err
+		}
+		i.SetVar(dst, arr.Arr[idx])
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpIndexStore:
+		// Arg1 value, Arg2 index, Arg3 array
+		arr, idx, err := i.element(in.Arg3, in.Arg2)
+		if err != nil {
+			return false, err
+		}
+		val, err := i.loadOperand(in.Arg1, in.Type)
+		if err != nil {
+			return false, err
+		}
+		if in.Type == lexer.FLOAT && val.Kind == KindInt {
+			val = newFloat(float64(val.I64)) // int widened to float
+		}
+		arr.Arr[idx] = val
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpBounds:
+		// Arg1 index, Arg2 length; Arg3 source line
+		idx, err := i.loadOperand(in.Arg1, lexer.INT)
+		if err != nil {
+			return false, err
+		}
+		n, err := i.loadOperand(in.Arg2, lexer.INT)
+		if err != nil {
+			return false, err
+		}
+		if idx.I64 < 0 || idx.I64 >= n.I64 {
+			return false, fmt.Errorf("index out of range [%d] with length %d at line %v", idx.I64, n.I64, in.Arg3)
+		}
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpList:
+		// Arg3 destination
+		dst, _ := in.Arg3.(int)
+		v, err := i.newList()
+		if err != nil {
+			return false, err
+		}
+		i.SetVar(dst, v)
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpAppend:
+		// Arg1 value, Arg3 list
+		l, err := i.listOperand(in.Arg3)
+		if err != nil {
+			return false, err
+		}
+		val, err := i.loadOperand(in.Arg1, in.Type)
+		if err != nil {
+			return false, err
+		}
+		if in.Type == lexer.FLOAT && val.Kind == KindInt {
+			val = newFloat(float64(val.I64)) // int widened to float
+		}
+		if err := i.appendValue(l, val); err != nil {
+			return false, err
+		}
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpListLen:
+		// Arg1 list; Arg3 destination
+		dst, _ := in.Arg3.(int)
+		l, err := i.listOperand(in.Arg1)
+		if err != nil {
+			return false, err
+		}
+		i.SetVar(dst, newInt(int64(len(l.elems))))
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpElems:
+		// Arg1 list; Arg3 destination, an array sharing the elements for ldx and stx
+		dst, _ := in.Arg3.(int)
+		l, err := i.listOperand(in.Arg1)
+		if err != nil {
+			return false, err
+		}
+		i.SetVar(dst, Value{Kind: KindArray, Arr: l.elems, Valid: true})
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpStruct:
+		// Arg1 size in bytes; Arg3 destination
+		dst, _ := in.Arg3.(int)
+		n, err := i.loadOperand(in.Arg1, lexer.INT)
+		if err != nil {
+			return false, err
+		}
+		v, err := i.newStruct(int(n.I64))
+		if err != nil {
+			return false, err
+		}
+		i.SetVar(dst, v)
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpFieldLoad:
+		// Arg1 struct, Arg2 offset; Arg3 destination
+		dst, _ := in.Arg3.(int)
+		s, k, err := i.field(in.Arg1, in.Arg2)
+		if err != nil {
+			return false, err
+		}
+		i.SetVar(dst, s.elems[k])
+		i.SetPC(pc + 1)
+		return false, nil
+
+	case codegen.OpFieldStore:
+		// Arg1 value, Arg2 offset, Arg3 struct
+		s, k, err := i.field(in.Arg3, in.Arg2)
+		if err != nil {
+			return false, err
+		}
+		val, err := i.loadOperand(in.Arg1, in.Type)
+		if err != nil {
+			return false, err
+		}
+		if in.Type == lexer.FLOAT && val.Kind == KindInt {
+			val = newFloat(float64(val.I64)) // int widened to float
+		}
+		s.elems[k] = val
+		i.SetPC(pc + 1)
+		return false, nil
+
 	case codegen.OpPrint:
 		// ensure writer
 		if i.out == nil {
@@ -133,24 +356,21 @@ func coreStep(i *Interpreter) (bool, error) {
 		funcName, _ := in.Arg1.(string)
 		argCount, _ := in.Arg2.(int)
 		retTemp, _ := in.Arg3.(int)
-		// Determine return-to IP (next instruction)
-		returnTo := pc + 1
-		// Push callee frame
-		callee := i.PushFrame(funcName, returnTo, retTemp)
-		// Move staged args into callee parameter slots 800,801,...
-		for p := 0; p < argCount; p++ {
-			if v, ok := i.ConsumeArg(p); ok {
-				callee.Locals[LocalAddrBase+p] = v
-			} else {
-				// default missing args to 0 (int)
-				callee.Locals[LocalAddrBase+p] = newInt(0)
-			}
+		// return to the next instruction
+		i.enterFunction(funcName, argCount, pc+1, retTemp)
+		return false, nil
+
+	case codegen.OpTailCall:
+		// Arg1 funcName, Arg2 argCount; the callee takes over the current frame's return
+		funcName, _ := in.Arg1.(string)
+		argCount, _ := in.Arg2.(int)
+		if i.currentFrame() != nil {
+			done := i.PopFrame()
+			i.enterFunction(funcName, argCount, done.ReturnToIP, done.RetTemp)
+		} else {
+			// nothing to replace at top-level, behave like a call without a result
+			i.enterFunction(funcName, argCount, pc+1, -1)
 		}
-		// clear any remaining staged args
-		i.ClearArgs()
-		// set callee PC to first instruction after label
-		start := i.funcIndex[funcName]
-		i.SetPC(start + 1)
 		return false, nil
 
 	case codegen.OpRet:
@@ -168,11 +388,7 @@ func coreStep(i *Interpreter) (bool, error) {
 			done := i.PopFrame()
 			// if the caller expects a return temp, store there in caller scope (globals or caller locals)
 			if done.RetTemp != 0 && done.RetTemp != -1 {
-				if cf := i.currentFrame(); cf != nil {
-					cf.Locals[done.RetTemp] = retVal
-				} else {
-					i.globals[done.RetTemp] = retVal
-				}
+				i.SetVar(done.RetTemp, retVal)
 			}
 			// continue at return location
 			i.SetPC(done.ReturnToIP)
@@ -226,6 +442,76 @@ func coreStep(i *Interpreter) (bool, error) {
 	}
 }
 
+// enterFunction pushes a frame for funcName, moves the staged args into its
+// parameter slots 800,801,... and jumps to its first instruction
+func (i *Interpreter) enterFunction(funcName string, argCount, returnTo, retTemp int) {
+	callee := i.PushFrame(funcName, returnTo, retTemp)
+	for p := 0; p < argCount; p++ {
+		if v, ok := i.ConsumeArg(p); ok {
+			callee.Locals[codegen.LocalAddrBase+p] = v
+		} else {
+			// default missing args to 0 (int)
+			callee.Locals[codegen.LocalAddrBase+p] = newInt(0)
+		}
+	}
+	// clear any remaining staged args
+	i.ClearArgs()
+	// set callee PC to first instruction after label
+	start := i.funcIndex[funcName]
+	i.SetPC(start + 1)
+}
+
+// element loads the array at base and the index at index, failing when the
+// index is outside the array
+func (i *Interpreter) element(base, index any) (Value, int, error) {
+	arr, err := i.loadOperand(base, lexer.LSBRACE)
+	if err != nil {
+		return Value{}, 0, err
+	}
+	if arr.Kind != KindArray {
+		return Value{}, 0, fmt.Errorf("cannot index %v", arr.Kind)
+	}
+	idx, err := i.loadOperand(index, lexer.INT)
+	if err != nil {
+		return Value{}, 0, err
+	}
+	if idx.I64 < 0 || idx.I64 >= int64(len(arr.Arr)) {
+		return Value{}, 0, fmt.Errorf("index out of range [%d] with length %d", idx.I64, len(arr.Arr))
+	}
+	return arr, int(idx.I64), nil
+}
+
+// listOperand loads the list at op and returns its heap object
+func (i *Interpreter) listOperand(op any) (*object, error) {
+	v, err := i.loadOperand(op, lexer.LIST)
+	if err != nil {
+		return nil, err
+	}
+	return i.object(v, KindList)
+}
+
+// field loads the struct at base and returns its heap object with the index
+// of the field at the byte offset off
+func (i *Interpreter) field(base, off any) (*object, int, error) {
+	v, err := i.loadOperand(base, lexer.STRUCT)
+	if err != nil {
+		return nil, 0, err
+	}
+	s, err := i.object(v, KindStruct)
+	if err != nil {
+		return nil, 0, err
+	}
+	n, err := i.loadOperand(off, lexer.INT)
+	if err != nil {
+		return nil, 0, err
+	}
+	k := int(n.I64) / elemSize
+	if k < 0 || k >= len(s.elems) {
+		return nil, 0, fmt.Errorf("no field at offset %d of a struct of %d bytes", n.I64, elemSize*len(s.elems))
+	}
+	return s, k, nil
+}
+
 // loadOperand resolves an operand that may be:
 // - immediate string "#..."
 // - address int
@@ -251,7 +537,7 @@ func (i *Interpreter) loadOperand(op any, hint lexer.TokenType) (Value, error) {
 				return newFloat(0), nil
 			case lexer.INT, lexer.BOOL:
 				return newInt(0), nil
-			case lexer.STRING:
+			case lexer.STR:
 				return newString(""), nil
 			default:
 				return Value{}, nil
@@ -323,6 +609,28 @@ func (i *Interpreter) evalBinary(op codegen.Operation, a, b Value, hint lexer.To
 			}
 		}
 
+	case codegen.OpShl, codegen.OpShr, codegen.OpUShr:
+		// shifts only exist on ints
+		ai, err := a.AsInt64()
+		if err != nil {
+			return Value{}, err
+		}
+		bi, err := b.AsInt64()
+		if err != nil {
+			return Value{}, err
+		}
+		if bi < 0 || bi > 63 {
+			return Value{}, fmt.Errorf("shift count out of range: %d", bi)
+		}
+		switch op {
+		case codegen.OpShl:
+			return newInt(ai << bi), nil
+		case codegen.OpShr:
+			return newInt(ai >> bi), nil
+		case codegen.OpUShr:
+			return newInt(int64(uint64(ai) >> bi)), nil
+		}
+
 	case codegen.OpAnd, codegen.OpOr:
 		ab, err := a.AsBool()
 		if err != nil {
@@ -340,6 +648,16 @@ func (i *Interpreter) evalBinary(op codegen.Operation, a, b Value, hint lexer.To
 		}
 
 	case codegen.OpEq, codegen.OpNeq, codegen.OpLt, codegen.OpLe, codegen.OpGt, codegen.OpGe:
+		// strings only compare for equality
+		if a.Kind == KindString && b.Kind == KindString {
+			switch op {
+			case codegen.OpEq:
+				return newBool(a.Str == b.Str), nil
+			case codegen.OpNeq:
+				return newBool(a.Str != b.Str), nil
+			}
+			return Value{}, fmt.Errorf("cannot order strings with %s", op)
+		}
 		// comparison: use float if any float or hint float, else int
 		useFloat := hint == lexer.FLOAT || a.Kind == KindFloat || b.Kind == KindFloat
 		if useFloat {
@@ -1,6 +1,7 @@
 package interpreter
 
 import (
+	"dolme/pkg/lexer"
 	"fmt"
 	"math"
 	"strconv"
@@ -15,8 +16,31 @@ const (
 	KindFloat
 	KindBool
 	KindString
+	KindArray
+	KindList
+	KindStruct
 )
 
+// kindNames names each kind the way the language writes its type
+var kindNames = [...]string{
+	KindUnknown: "unknown",
+	KindInt:     "int",
+	KindFloat:   "float",
+	KindBool:    "bool",
+	KindString:  "string",
+	KindArray:   "array",
+	KindList:    "list",
+	KindStruct:  "struct",
+}
+
+// String returns the name of the kind
+func (k ValueKind) String() string {
+	if k >= 0 && int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("ValueKind(%d)", int(k))
+}
+
 // Value represents a dynamically-typed value in the interpreter.
 type Value struct {
 	Kind  ValueKind
@@ -24,6 +48,8 @@ type Value struct {
 	F64   float64
 	Bool  bool
 	Str   string
+	Arr   []Value // elements of an array, shared by every copy of the value
+	Ref   int     // heap object of a list, a struct or an array, shared by every copy of the value
 	Valid bool
 }
 
@@ -41,6 +67,16 @@ func (v Value) String() string {
 		return "false"
 	case KindString:
 		return v.Str
+	case KindArray:
+		elems := make([]string, len(v.Arr))
+		for k, e := range v.Arr {
+			elems[k] = e.String()
+		}
+		return "[" + strings.Join(elems, " ") + "]"
+	case KindList:
+		return fmt.Sprintf("<list %d>", v.Ref)
+	case KindStruct:
+		return fmt.Sprintf("<struct %d>", v.Ref)
 	default:
 		return "<nil>"
 	}
@@ -114,6 +150,20 @@ func newString(s string) Value {
 	return Value{Kind: KindString, Str: s, Valid: true}
 }
 
+// zeroValue returns the value a variable of type t starts with.
+func zeroValue(t lexer.TokenType) Value {
+	switch t {
+	case lexer.FLOAT:
+		return newFloat(0)
+	case lexer.BOOL:
+		return newBool(false)
+	case lexer.STR:
+		return newString("")
+	default:
+		return newInt(0)
+	}
+}
+
 // ParseImmediate parses a codegen immediate like "#1", "#3.14", "#true", or a quoted string (with leading '#').
 func parseImmediate(imm string) (Value, error) {
 	if !strings.HasPrefix(imm, "#") {
@@ -129,9 +179,12 @@ func parseImmediate(imm string) (Value, error) {
 		return newBool(false), nil
 	}
 
-	// quoted strings (already include quotes as produced by lexer)
+	// quoted strings, with escapes the way Go quotes them
 	if len(body) >= 2 && body[0] == '"' && body[len(body)-1] == '"' {
-		unquoted := body[1 : len(body)-1]
+		unquoted, err := strconv.Unquote(body)
+		if err != nil {
+			return Value{}, fmt.Errorf("invalid string immediate %q: %w", imm, err)
+		}
 		return newString(unquoted), nil
 	}
 
@@ -1,23 +1,23 @@
 package lexer
 
+import "strings"
+
 type Lexer struct {
-	input        string // input string to be tokenized
-	length       int    // length of the input string
-	position     int    // current position in the input string
-	line         int    // current line number for error reporting
-	column       int    // current column number for error reporting
-	currentToken Token  // current token for context (e.g., unary minus handling)
+	input    string // input string to be tokenized
+	length   int    // length of the input string
+	position int    // current position in the input string
+	line     int    // current line number for error reporting
+	column   int    // current column number for error reporting
 }
 
 // Create a new lexer instance
 func NewLexer(s string) *Lexer {
 	return &Lexer{
-		input:        s,
-		length:       len(s),
-		position:     0,
-		line:         1,
-		column:       1,
-		currentToken: Token{},
+		input:    s,
+		length:   len(s),
+		position: 0,
+		line:     1,
+		column:   1,
 	}
 }
 
@@ -27,30 +27,7 @@ func (l *Lexer) NextToken() Token {
 
 	// End of input
 	if l.position >= l.length {
-		tok := NewToken(EOF, "", "", l.currentPosition())
-		l.currentToken = tok
-		return tok
-	}
-
-	// Handle signed numbers in contexts that allow unary minus.
-	// We only treat '-' + number as a single NUM when:
-	// - previous token allows unary (start of input or after operators/delimiters)
-	// - '-' is immediately followed by a digit and the remainder matches NUM
-	if l.input[l.position] == '-' && l.prevAllowsUnary() {
-		if l.position+1 < l.length && isDigit(l.input[l.position+1]) {
-			remaining := l.input[l.position+1:]
-			t, lex, matched := MatchToken(remaining)
-			if matched && t == NUM && lex != "" {
-				lexeme := "-" + lex
-
-				tok := NewToken(NUM, lexeme, lexeme, l.currentPosition())
-
-				l.advance(len(lexeme))
-				l.currentToken = tok
-
-				return tok
-			}
-		}
+		return NewToken(EOF, "", "", l.currentPosition())
 	}
 
 	// Regex match the first token it sees from the remaining input from current position to the end
@@ -63,11 +40,9 @@ func (l *Lexer) NextToken() Token {
 			return l.NextToken()
 		}
 
-		char := string(l.input[l.position])
+		tok := NewToken(ILLEGAL, string(l.input[l.position]), "", l.currentPosition())
 		l.advance(1)
 
-		tok := NewToken(ILLEGAL, char, "", l.currentPosition())
-		l.currentToken = tok
 		return tok
 	}
 
@@ -80,26 +55,57 @@ func (l *Lexer) NextToken() Token {
 	case FALSE:
 		literal = "false"
 	case STRING:
-		// Remove the surrounding quotes from the lexeme
-		literal = lexeme[1 : len(lexeme)-1]
+		// Remove the surrounding quotes from the lexeme and decode its escapes
+		var ok bool
+		if literal, ok = unescape(lexeme[1 : len(lexeme)-1]); !ok {
+			tok := NewToken(ILLEGAL, lexeme, "", l.currentPosition())
+			l.advance(len(lexeme))
+			return tok
+		}
 	default:
 		literal = lexeme
 	}
 
 	tok := NewToken(token_type, lexeme, literal, l.currentPosition())
 	l.advance(len(lexeme))
-	l.currentToken = tok
 
 	return tok
 }
 
+// escapes maps the character after a backslash in a string literal to the
+// character it stands for
+var escapes = map[byte]byte{'n': '\n', 't': '\t', 'r': '\r', '"': '"', '\\': '\\'}
+
+// unescape decodes the escape sequences of a string literal body; it fails
+// on a backslash followed by anything else
+func unescape(body string) (string, bool) {
+	if !strings.Contains(body, "\\") {
+		return body, true
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(body); i++ {
+		if body[i] != '\\' {
+			b.WriteByte(body[i])
+			continue
+		}
+		i++
+		ch, ok := escapes[body[i]]
+		if !ok {
+			return "", false
+		}
+		b.WriteByte(ch)
+	}
+
+	return b.String(), true
+}
+
 // View next token without advancing the position
 func (l *Lexer) Peek() Token {
 	// save state
 	cpos := l.position
 	cline := l.line
 	ccol := l.column
-	ctok := l.currentToken
 
 	token := l.NextToken()
 
@@ -107,7 +113,6 @@ func (l *Lexer) Peek() Token {
 	l.position = cpos
 	l.line = cline
 	l.column = ccol
-	l.currentToken = ctok
 
 	return token
 }
@@ -177,25 +182,3 @@ func (l *Lexer) currentPosition() Position {
 		Offset: l.position,
 	}
 }
-
-// Check if the previous token allows a unary operator (like - or +)
-func (l *Lexer) prevAllowsUnary() bool {
-	switch l.currentToken.Type {
-	case EOF,
-		ASSIGN,    // =
-		LPAREN,    // (
-		COMMA,     // ,
-		COLON,     // :
-		SEMICOLON, // ;
-		LBRACE,    // {
-		// arithmetic operators
-		PLUS, MINUS, MULT, DIV, MOD,
-		// relational operators
-		LT, GT, LE, GE, EQ, NE,
-		// logical operators / keywords that can precede expr
-		AND, OR, NOT, RETURN, IF, WHILE, PRINT:
-		return true
-	default:
-		return false
-	}
-}
@@ -16,6 +16,8 @@ var tokenRegexes = map[TokenType]tokenRegex{
 	EQ: {regexp.MustCompile(`^==`), `^==`},
 	NE: {regexp.MustCompile(`^!=`), `^!=`},
 
+	ARROW: {regexp.MustCompile(`^=>`), `^=>`},
+
 	LET:      {regexp.MustCompile(`^let\b`), `^let\b`},
 	FUNC:     {regexp.MustCompile(`^func\b`), `^func\b`},
 	RETURN:   {regexp.MustCompile(`^return\b`), `^return\b`},
@@ -33,6 +35,13 @@ var tokenRegexes = map[TokenType]tokenRegex{
 	INT:      {regexp.MustCompile(`^int\b`), `^int\b`},
 	FLOAT:    {regexp.MustCompile(`^float\b`), `^float\b`},
 	BOOL:     {regexp.MustCompile(`^bool\b`), `^bool\b`},
+	STR:      {regexp.MustCompile(`^string\b`), `^string\b`},
+	LEN:      {regexp.MustCompile(`^len\b`), `^len\b`},
+	LIST:     {regexp.MustCompile(`^list\b`), `^list\b`},
+	APPEND:   {regexp.MustCompile(`^append\b`), `^append\b`},
+	STRUCT:   {regexp.MustCompile(`^struct\b`), `^struct\b`},
+	ENUM:     {regexp.MustCompile(`^enum\b`), `^enum\b`},
+	MATCH:    {regexp.MustCompile(`^match\b`), `^match\b`},
 
 	ASSIGN: {regexp.MustCompile(`^=`), `^=`},
 	PLUS:   {regexp.MustCompile(`^\+`), `^\+`},
@@ -52,6 +61,7 @@ var tokenRegexes = map[TokenType]tokenRegex{
 	RBRACE:    {regexp.MustCompile(`^\}`), `^\}`},
 	LSBRACE:   {regexp.MustCompile(`^\[`), `^\[`},
 	RSBRACE:   {regexp.MustCompile(`^\]`), `^\]`},
+	DOT:       {regexp.MustCompile(`^\.`), `^\.`},
 
 	NUM:    {regexp.MustCompile(`^\d+(\.\d+)?([eE][+-]?\d+)?`), `^\d+(\.\d+)?([eE][+-]?\d+)?`},
 	STRING: {regexp.MustCompile(`^"([^"\\]|\\.)*"`), `^"([^"\\]|\\.)*"`},
@@ -65,11 +75,11 @@ var (
 
 // Token precedence order for matching (longer patterns first)
 var tokenPrecedenceOrder = []TokenType{
-	CONTINUE, RETURN, BREAK, FALSE, FLOAT, PRINT, WHILE, ELSE, FUNC,
-	TRUE, BOOL, AND, INT, LET, NOT, IF, OR, LE, GE, EQ, NE, ASSIGN, PLUS,
+	CONTINUE, RETURN, APPEND, STRUCT, STR, BREAK, FALSE, FLOAT, PRINT, WHILE, MATCH, ELSE, FUNC,
+	ENUM, TRUE, BOOL, AND, INT, LIST, LEN, LET, NOT, IF, OR, LE, GE, EQ, NE, ARROW, ASSIGN, PLUS,
 	MINUS, MULT, DIV, MOD, LT, GT, SEMICOLON, COMMA, COLON,
 	LPAREN, RPAREN, LBRACE, RBRACE, LSBRACE,
-	RSBRACE, NUM, STRING, ID,
+	RSBRACE, DOT, NUM, STRING, ID,
 }
 
 // Get the regex pattern for a token type
@@ -110,8 +120,3 @@ func MatchToken(s string) (TokenType, string, bool) {
 
 	return ILLEGAL, string(s[0]), false
 }
-
-// Check if a byte is a digit
-func isDigit(b byte) bool {
-	return b >= '0' && b <= '9'
-}
@@ -0,0 +1,39 @@
+package lexer_test
+
+import (
+	"dolme/pkg/lexer"
+	"testing"
+)
+
+func TestStrings(t *testing.T) {
+	tests := []struct {
+		input       string
+		expected    lexer.TokenType
+		literal     string
+		description string
+	}{
+		{`"hi"`, lexer.STRING, "hi", "plain string"},
+		{`""`, lexer.STRING, "", "empty string"},
+		{`"a\nb"`, lexer.STRING, "a\nb", "newline escape"},
+		{`"\t\r"`, lexer.STRING, "\t\r", "tab and carriage return escapes"},
+		{`"say \"hi\""`, lexer.STRING, `say "hi"`, "escaped quotes"},
+		{`"C:\\dir"`, lexer.STRING, `C:\dir`, "escaped backslash"},
+		{`"bad \q"`, lexer.ILLEGAL, "", "unknown escape"},
+		{"string", lexer.STR, "string", "type keyword"},
+		{"len", lexer.LEN, "len", "len keyword"},
+		{"length", lexer.ID, "length", "identifier starting with len"},
+	}
+
+	for _, test := range tests {
+		tok := lexer.NewLexer(test.input).NextToken()
+		if tok.Type != test.expected {
+			t.Errorf("Input %s (%s): expected %s, got %s", test.input, test.description, test.expected, tok.Type)
+		}
+		if tok.Literal != test.literal {
+			t.Errorf("Input %s (%s): expected literal %q, got %q", test.input, test.description, test.literal, tok.Literal)
+		}
+		if tok.Lexeme != test.input {
+			t.Errorf("Input %s (%s): expected lexeme %s, got %s", test.input, test.description, test.input, tok.Lexeme)
+		}
+	}
+}
@@ -27,3 +27,82 @@ func TestTokens(t *testing.T) {
 		}
 	}
 }
+
+func TestSigns(t *testing.T) {
+	// a sign is always its own token; the parser decides whether it is unary
+	input := "-5 a -1 x=-2.5 +3"
+	mylexer := lexer.NewLexer(input)
+
+	expectedTokens := []lexer.TokenType{
+		lexer.MINUS, lexer.NUM,
+		lexer.ID, lexer.MINUS, lexer.NUM,
+		lexer.ID, lexer.ASSIGN, lexer.MINUS, lexer.NUM,
+		lexer.PLUS, lexer.NUM,
+		lexer.EOF,
+	}
+
+	for i, expected := range expectedTokens {
+		token := mylexer.NextToken()
+		if token.Type != expected {
+			t.Errorf("Token %d: expected %s, got %s", i, expected, token.Type)
+		}
+	}
+}
+
+func TestLists(t *testing.T) {
+	input := "let xs : list[int] = []; append(xs, 3); lists appended"
+	mylexer := lexer.NewLexer(input)
+
+	expectedTokens := []lexer.TokenType{
+		lexer.LET, lexer.ID, lexer.COLON, lexer.LIST, lexer.LSBRACE, lexer.INT, lexer.RSBRACE,
+		lexer.ASSIGN, lexer.LSBRACE, lexer.RSBRACE, lexer.SEMICOLON,
+		lexer.APPEND, lexer.LPAREN, lexer.ID, lexer.COMMA, lexer.NUM, lexer.RPAREN, lexer.SEMICOLON,
+		lexer.ID, lexer.ID,
+		lexer.EOF,
+	}
+
+	for i, expected := range expectedTokens {
+		token := mylexer.NextToken()
+		if token.Type != expected {
+			t.Errorf("Token %d: expected %s, got %s", i, expected, token.Type)
+		}
+	}
+}
+
+func TestStructs(t *testing.T) {
+	input := "struct P { x: float } p.x = 1.5; structs"
+	mylexer := lexer.NewLexer(input)
+
+	expectedTokens := []lexer.TokenType{
+		lexer.STRUCT, lexer.ID, lexer.LBRACE, lexer.ID, lexer.COLON, lexer.FLOAT, lexer.RBRACE,
+		lexer.ID, lexer.DOT, lexer.ID, lexer.ASSIGN, lexer.NUM, lexer.SEMICOLON,
+		lexer.ID,
+		lexer.EOF,
+	}
+
+	for i, expected := range expectedTokens {
+		token := mylexer.NextToken()
+		if token.Type != expected {
+			t.Errorf("Token %d: expected %s, got %s", i, expected, token.Type)
+		}
+	}
+}
+
+func TestEnums(t *testing.T) {
+	input := "enum C { A } match (c) { A => {} } a >= b => = matches"
+	mylexer := lexer.NewLexer(input)
+
+	expectedTokens := []lexer.TokenType{
+		lexer.ENUM, lexer.ID, lexer.LBRACE, lexer.ID, lexer.RBRACE,
+		lexer.MATCH, lexer.LPAREN, lexer.ID, lexer.RPAREN, lexer.LBRACE, lexer.ID, lexer.ARROW, lexer.LBRACE, lexer.RBRACE, lexer.RBRACE,
+		lexer.ID, lexer.GE, lexer.ID, lexer.ARROW, lexer.ASSIGN, lexer.ID,
+		lexer.EOF,
+	}
+
+	for i, expected := range expectedTokens {
+		token := mylexer.NextToken()
+		if token.Type != expected {
+			t.Errorf("Token %d: expected %s, got %s", i, expected, token.Type)
+		}
+	}
+}
@@ -53,6 +53,13 @@ const (
 	INT      // int
 	FLOAT    // float
 	BOOL     // bool
+	STR      // string
+	LEN      // len
+	LIST     // list
+	APPEND   // append
+	STRUCT   // struct
+	ENUM     // enum
+	MATCH    // match
 
 	ID     // id (identifier)
 	NUM    // num (number)
@@ -70,6 +77,7 @@ const (
 	GE     // >=
 	EQ     // ==
 	NE     // !=
+	ARROW  // =>
 
 	SEMICOLON // ;
 	COMMA     // ,
@@ -80,6 +88,7 @@ const (
 	RBRACE    // }
 	LSBRACE   // [
 	RSBRACE   // ]
+	DOT       // .
 
 	ILLEGAL // illegal token
 )
@@ -102,6 +111,13 @@ var Keywords = map[string]TokenType{
 	"int":      INT,
 	"float":    FLOAT,
 	"bool":     BOOL,
+	"string":   STR,
+	"len":      LEN,
+	"list":     LIST,
+	"append":   APPEND,
+	"struct":   STRUCT,
+	"enum":     ENUM,
+	"match":    MATCH,
 }
 
 // TokenToString converts a TokenType to its string representation
@@ -121,6 +137,13 @@ func (t Token) TokenToString() (string, bool) {
 		INT:       "int",
 		FLOAT:     "float",
 		BOOL:      "bool",
+		STR:       "string",
+		LEN:       "len",
+		LIST:      "list",
+		APPEND:    "append",
+		STRUCT:    "struct",
+		ENUM:      "enum",
+		MATCH:     "match",
 		NOT:       "not",
 		AND:       "and",
 		OR:        "or",
@@ -128,6 +151,9 @@ func (t Token) TokenToString() (string, bool) {
 		RPAREN:    ")",
 		LBRACE:    "{",
 		RBRACE:    "}",
+		LSBRACE:   "[",
+		RSBRACE:   "]",
+		DOT:       ".",
 		SEMICOLON: ";",
 		COMMA:     ",",
 		COLON:     ":",
@@ -143,9 +169,10 @@ func (t Token) TokenToString() (string, bool) {
 		GE:        ">=",
 		EQ:        "==",
 		NE:        "!=",
+		ARROW:     "=>",
 		ID:        "id",
 		NUM:       "num",
-		STRING:    "string",
+		STRING:    "strlit",
 		EOF:       "$",
 	}
 
@@ -177,7 +204,7 @@ func (t TokenType) String() string {
 // GetCategory returns the category of the token
 func (t TokenType) GetCategory() TokenCategory {
 	switch t {
-	case LET, FUNC, RETURN, IF, ELSE, WHILE, BREAK, CONTINUE, PRINT, AND, OR, NOT, TRUE, FALSE, INT, FLOAT, BOOL:
+	case LET, FUNC, RETURN, IF, ELSE, WHILE, BREAK, CONTINUE, PRINT, AND, OR, NOT, TRUE, FALSE, INT, FLOAT, BOOL, STR, LEN, LIST, APPEND, STRUCT, ENUM, MATCH:
 		return KEYWORD
 	case ID:
 		return IDENTIFIER
@@ -185,7 +212,7 @@ func (t TokenType) GetCategory() TokenCategory {
 		return LITERAL
 	case ASSIGN, PLUS, MINUS, MULT, DIV, MOD, LT, GT, LE, GE, EQ, NE:
 		return OPERATOR
-	case SEMICOLON, COMMA, COLON, LPAREN, RPAREN, LBRACE, RBRACE:
+	case SEMICOLON, COMMA, COLON, LPAREN, RPAREN, LBRACE, RBRACE, LSBRACE, RSBRACE, DOT, ARROW:
 		return DELIMITER
 	default:
 		return NONE
@@ -126,11 +126,34 @@ func (c *Codegen) binaryOpAction(op Operation) {
 	if c.ss.Size() >= 2 {
 		op2 := c.top()
 		op1 := c.topMinus(1)
+		t1, t2 := c.checkScalar(op1), c.checkScalar(op2)
+
+		// + on two strings concatenates them, no other arithmetic applies to strings
+		if t1 == lexer.EOF || t2 == lexer.EOF {
+			// an operand of unknown type was reported where it came from
+		} else if t1 == lexer.STR || t2 == lexer.STR {
+			other := t1
+			if t1 == lexer.STR {
+				other = t2
+			}
+			switch {
+			case op != OpAdd:
+				c.addStringOperatorError(op, "`+`", c.currentToken.Pos)
+			case other != lexer.STR:
+				c.addTypeMismatchError(lexer.STR, other, c.currentToken.Pos)
+			}
+			op = OpConcat
+		}
+
 		t := c.getTemp()
 
 		newType := lexer.FLOAT
-		if c.GetVariableType(op1) == lexer.INT && c.GetVariableType(op2) == lexer.INT {
+		if t1 == lexer.EOF || t2 == lexer.EOF {
+			newType = lexer.EOF
+		} else if t1 == lexer.INT && t2 == lexer.INT {
 			newType = lexer.INT
+		} else if op == OpConcat {
+			newType = lexer.STR
 		}
 
 		c.setVariableType(t, newType)
@@ -142,33 +165,88 @@ func (c *Codegen) binaryOpAction(op Operation) {
 	}
 }
 
+// logicalOpAction generates code for `and` and `or`, whose operands and result are bools
+func (c *Codegen) logicalOpAction(op Operation) {
+	if c.ss.Size() >= 2 {
+		op2 := c.top()
+		op1 := c.topMinus(1)
+		c.checkBool(op1)
+		c.checkBool(op2)
+
+		t := c.getTemp()
+		c.setVariableType(t, lexer.BOOL)
+
+		c.pb = append(c.pb, Instruction{Op: op, Arg1: op1, Arg2: op2, Arg3: t, Type: lexer.BOOL})
+		c.pop(2)
+		c.push(t)
+		c.i++
+	}
+}
+
+// checkBool reports a type mismatch when the value at addr is not a bool
+func (c *Codegen) checkBool(addr int) {
+	if t := c.GetVariableType(addr); t != lexer.BOOL && t != lexer.EOF {
+		c.addTypeMismatchError(lexer.BOOL, c.typeOf(addr), c.currentToken.Pos)
+	}
+}
+
+// checkScalar reports a type mismatch when no operator applies to the value at addr and returns its type
+func (c *Codegen) checkScalar(addr int) lexer.TokenType {
+	t := c.GetVariableType(addr)
+	if reference(t) || t == lexer.ENUM {
+		c.addTypeMismatchError(lexer.INT, c.typeOf(addr), c.currentToken.Pos)
+		return lexer.INT
+	}
+	return t
+}
+
+// assignable reports whether a value of type from can be stored in a variable of type to
+func assignable(to, from lexer.TokenType) bool {
+	return to == from || (to == lexer.FLOAT && from == lexer.INT) || from == lexer.EOF
+}
+
+// store copies value into dst of type t
+func (c *Codegen) store(value, dst int, t lexer.TokenType) {
+	if t == c.GetVariableType(value) && c.retargetLast(value, dst) {
+		return
+	}
+	c.pb = append(c.pb, Instruction{Op: OpAssign, Arg1: value, Arg2: nil, Arg3: dst, Type: t})
+	c.i++
+}
+
+// widen copies an int value into a float temp when it is passed or returned as a float
+func (c *Codegen) widen(value int, t lexer.TokenType) int {
+	if t != lexer.FLOAT || c.GetVariableType(value) != lexer.INT {
+		return value
+	}
+	temp := c.getTemp()
+	c.setVariableType(temp, lexer.FLOAT)
+	c.pb = append(c.pb, Instruction{Op: OpAssign, Arg1: value, Arg2: nil, Arg3: temp, Type: lexer.FLOAT})
+	c.i++
+	return temp
+}
+
 // pushAction pushes a literal value onto the stack and generates an assignment instruction
 func (c *Codegen) pushAction() {
-	var value string
+	value := "#" + c.currentToken.Lexeme
+	type_ := lexer.EOF
 
 	switch c.currentToken.Type {
-	case lexer.NUM:
-		value = "#" + c.currentToken.Lexeme
-	case lexer.TRUE:
-		value = "#true"
-	case lexer.FALSE:
-		value = "#false"
+	case lexer.TRUE, lexer.FALSE:
+		type_ = lexer.BOOL
 	case lexer.STRING:
-		value = "#" + c.currentToken.Lexeme
+		// the escapes are decoded by the lexer and quoted again the way Go does
+		value = "#" + strconv.Quote(c.currentToken.Literal)
+		type_ = lexer.STR
 	default:
-		value = "#" + c.currentToken.Lexeme
-	}
-
-	value_ := value[1:]
-	type_ := lexer.EOF
-	if value_ == "true" || value_ == "false" {
-		type_ = lexer.BOOL
-	} else if strings.Contains(value_, ".") {
-		type_ = lexer.FLOAT
-	} else if _, err := strconv.ParseInt(value_, 10, 64); err == nil {
-		type_ = lexer.INT
-	} else if _, err := strconv.ParseFloat(value_, 64); err == nil {
-		type_ = lexer.FLOAT
+		value_ := value[1:]
+		if strings.Contains(value_, ".") {
+			type_ = lexer.FLOAT
+		} else if _, err := strconv.ParseInt(value_, 10, 64); err == nil {
+			type_ = lexer.INT
+		} else if _, err := strconv.ParseFloat(value_, 64); err == nil {
+			type_ = lexer.FLOAT
+		}
 	}
 
 	t := c.getTemp()
@@ -180,12 +258,12 @@ func (c *Codegen) pushAction() {
 	c.i++
 }
 
-// loadAction looks up a variable's address and pushes it onto the stack
+// loadAction looks up a variable's address, or an enum variant, and pushes it onto the stack
 func (c *Codegen) loadAction() {
 	varName := c.currentToken.Lexeme
 	if addr, exists := c.getVariableAddress(varName); exists {
 		c.push(addr)
-	} else {
+	} else if !c.loadVariant(varName) {
 		c.addUndefinedVariableError(varName, c.currentToken.Pos)
 	}
 }
@@ -195,15 +273,16 @@ func (c *Codegen) assignAction() {
 	if c.ss.Size() >= 2 {
 		value := c.top()
 		targetAddr := c.topMinus(1)
+		targetType := c.GetVariableType(targetAddr)
 
-		if c.GetVariableType(value) != c.GetVariableType(targetAddr) {
-			c.addTypeMismatchError(c.GetVariableType(targetAddr), c.GetVariableType(value), c.currentToken.Pos)
+		if !c.fitsType(c.typeOf(targetAddr), value) {
+			c.addTypeMismatchError(c.typeOf(targetAddr), c.typeOf(value), c.currentToken.Pos)
+			c.pop(2)
 			return
 		}
 
-		c.pb = append(c.pb, Instruction{Op: OpAssign, Arg1: value, Arg2: nil, Arg3: targetAddr, Type: c.GetVariableType(value)})
+		c.store(value, targetAddr, targetType)
 		c.pop(2)
-		c.i++
 	}
 }
 
@@ -211,20 +290,45 @@ func (c *Codegen) assignAction() {
 func (c *Codegen) defineAction() {
 	if c.ss.Size() >= 3 {
 		value := c.top()
+		typ := c.topStringMinus(1)
 		varName := c.topStringMinus(2)
 
 		if c.isVariableDeclared(varName) {
 			c.addRedeclarationError(varName, c.currentToken.Pos)
 		}
+		c.checkNotVariant("variable", varName)
+		if !c.fits(typ, value) {
+			c.addTypeMismatchError(c.declaredType(typ), c.typeOf(value), c.currentToken.Pos)
+		}
 
 		varAddr := c.getVariable()
 		c.declareVariable(varName, varAddr)
+		declared := c.declareType(varAddr, typ)
 
-		c.setVariableType(varAddr, c.GetVariableType(value))
-
-		c.pb = append(c.pb, Instruction{Op: OpAssign, Arg1: value, Arg2: nil, Arg3: varAddr, Type: c.GetVariableType(value)})
+		c.store(value, varAddr, declared)
 		c.pop(3)
-		c.i++
+	}
+}
+
+// retargetLast makes the last instruction write straight into dst when it only computed value
+func (c *Codegen) retargetLast(value, dst int) bool {
+	if !IsTemp(value) || len(c.pb) == 0 {
+		return false
+	}
+
+	last := &c.pb[len(c.pb)-1]
+	if t, ok := last.Arg3.(int); !ok || t != value {
+		return false
+	}
+
+	switch last.Op {
+	case OpAssign, OpAdd, OpSub, OpMul, OpDiv, OpMod, OpAnd, OpOr, OpNot, OpNeg, OpConcat, OpLen,
+		OpEq, OpNeq, OpLt, OpLe, OpGt, OpGe, OpCall, OpAlloc, OpIndexLoad, OpList, OpListLen,
+		OpStruct, OpFieldLoad:
+		last.Arg3 = dst
+		return true
+	default:
+		return false
 	}
 }
 
@@ -232,6 +336,7 @@ func (c *Codegen) defineAction() {
 func (c *Codegen) printAction() {
 	if c.ss.Size() >= 1 {
 		a := c.top()
+		c.checkScalar(a)
 		c.pb = append(c.pb, Instruction{Op: OpPrint, Arg1: a, Arg2: nil, Arg3: nil, Type: c.GetVariableType(a)})
 		c.pop(1)
 		c.i++
@@ -242,18 +347,78 @@ func (c *Codegen) printAction() {
 func (c *Codegen) notAction() {
 	if c.ss.Size() >= 1 {
 		op1 := c.top()
+		c.checkBool(op1)
+
 		temp := c.getTemp()
+		c.setVariableType(temp, lexer.BOOL)
 
-		c.pb = append(c.pb, Instruction{Op: OpNot, Arg1: op1, Arg2: nil, Arg3: temp, Type: lexer.EOF})
+		c.pb = append(c.pb, Instruction{Op: OpNot, Arg1: op1, Arg2: nil, Arg3: temp, Type: lexer.BOOL})
 		c.pop(1)
 		c.push(temp)
 		c.i++
 	}
 }
 
+// negAction generates code for unary minus on an int or a float
+func (c *Codegen) negAction() {
+	if c.ss.Size() >= 1 {
+		op1 := c.top()
+		t := c.checkNumeric(op1)
+
+		temp := c.getTemp()
+		c.setVariableType(temp, t)
+
+		c.pb = append(c.pb, Instruction{Op: OpNeg, Arg1: op1, Arg2: nil, Arg3: temp, Type: t})
+		c.pop(1)
+		c.push(temp)
+		c.i++
+	}
+}
+
+// plusAction handles unary plus, which leaves its operand unchanged
+func (c *Codegen) plusAction() {
+	if c.ss.Size() >= 1 {
+		c.checkNumeric(c.top())
+	}
+}
+
+// lenAction generates code for len of a string, an array or a list
+func (c *Codegen) lenAction() {
+	if c.ss.Size() >= 1 {
+		op1 := c.top()
+		instr := Instruction{Op: OpLen, Arg1: op1, Arg2: nil, Arg3: nil, Type: lexer.INT}
+		if arr, ok := c.arrays[op1]; ok {
+			instr = Instruction{Op: OpAssign, Arg1: "#" + strconv.Itoa(arr.Len), Arg2: nil, Arg3: nil, Type: lexer.INT}
+		} else if _, ok := c.lists[op1]; ok {
+			instr.Op = OpListLen
+		} else if t := c.GetVariableType(op1); t != lexer.STR && t != lexer.EOF {
+			c.addTypeMismatchError(lexer.STR, c.typeOf(op1), c.currentToken.Pos)
+		}
+
+		temp := c.getTemp()
+		c.setVariableType(temp, lexer.INT)
+
+		instr.Arg3 = temp
+		c.pb = append(c.pb, instr)
+		c.pop(1)
+		c.push(temp)
+		c.i++
+	}
+}
+
+// checkNumeric reports a type mismatch when the value at addr is neither an int nor a float and returns its type
+func (c *Codegen) checkNumeric(addr int) lexer.TokenType {
+	t := c.GetVariableType(addr)
+	if t != lexer.INT && t != lexer.FLOAT && t != lexer.EOF {
+		c.addTypeMismatchError(lexer.INT, c.typeOf(addr), c.currentToken.Pos)
+		return lexer.INT
+	}
+	return t
+}
+
 // relAction generates code for relational operations
 func (c *Codegen) relAction() {
-	// Parsing order: BoolPrimary RelOp BoolPrimary @rel
+	// Parsing order: ArithExpr RelOp ArithExpr @rel
 	// Stack order: [op1, operator_string, op2] (top to bottom)
 	if c.ss.Size() >= 3 {
 		op2 := c.top()               // Second operand (right side)
@@ -280,9 +445,42 @@ func (c *Codegen) relAction() {
 
 		temp := c.getTemp()
 
+		// operands are compared as floats unless both are ints or both bools;
+		// a bool only compares with another bool, strings only compare for
+		// equality, with another string, and enums with a value of the same enum
 		newType := lexer.FLOAT
-		if c.GetVariableType(op1) == lexer.INT && c.GetVariableType(op2) == lexer.INT {
-			newType = lexer.INT
+		var t1, t2 lexer.TokenType
+		if e, ok := c.enums[op1]; ok && (relOp == OpEq || relOp == OpNeq) {
+			if !c.fitsType(e, op2) {
+				c.addTypeMismatchError(e, c.typeOf(op2), c.currentToken.Pos)
+			}
+			t1, t2 = lexer.INT, lexer.INT
+		} else {
+			t1, t2 = c.checkScalar(op1), c.checkScalar(op2)
+		}
+		if t1 == lexer.EOF || t2 == lexer.EOF {
+			// an operand of unknown type was reported where it came from
+		} else if t1 == t2 && (t1 == lexer.INT || t1 == lexer.BOOL) {
+			newType = t1
+		} else if t1 == lexer.STR || t2 == lexer.STR {
+			other := t1
+			if t1 == lexer.STR {
+				other = t2
+			}
+			switch {
+			case relOp != OpEq && relOp != OpNeq:
+				c.addStringOperatorError(relOp, "`==` and `!=`", c.currentToken.Pos)
+			case other != lexer.STR:
+				c.addTypeMismatchError(lexer.STR, other, c.currentToken.Pos)
+			}
+			newType = lexer.STR
+		} else if t1 == lexer.BOOL || t2 == lexer.BOOL {
+			other := t1
+			if t1 == lexer.BOOL {
+				other = t2
+			}
+			c.addTypeMismatchError(lexer.BOOL, other, c.currentToken.Pos)
+			newType = lexer.BOOL
 		}
 		c.setVariableType(temp, lexer.BOOL)
 
@@ -295,9 +493,15 @@ func (c *Codegen) relAction() {
 
 // functionStartAction handles the start of a function definition
 func (c *Codegen) functionStartAction() {
-	funcName := c.currentToken.Lexeme
+	c.checkNotVariant("function", c.currentToken.Lexeme)
+	c.startFunction(c.currentToken.Lexeme)
+}
+
+// startFunction opens the body of the function funcName
+func (c *Codegen) startFunction(funcName string) {
 	c.pb = append(c.pb, Instruction{Op: OpLabel, Arg1: funcName, Arg2: nil, Arg3: nil, Type: lexer.EOF})
 	c.setInFunction(true)
+	c.function = funcName
 	c.pushString(funcName)
 	c.i++
 }
@@ -323,10 +527,15 @@ func (c *Codegen) paramAction() {
 		paramName := c.topStringMinus(1)
 		paramAddr := c.getLocalVariable()
 
+		c.checkNotVariant("parameter", paramName)
 		c.declareVariable(paramName, paramAddr)
-		c.setVariableType(paramAddr, lexer.Keywords[typeStr])
+		t := c.declareType(paramAddr, typeStr)
+		if c.ss.Size() >= 3 {
+			funcName := c.topStringMinus(2)
+			c.functionParams[funcName] = append(c.functionParams[funcName], typeStr)
+		}
 
-		c.pb = append(c.pb, Instruction{Op: OpParam, Arg1: paramAddr, Arg2: c.paramCounter, Arg3: nil, Type: lexer.Keywords[typeStr]})
+		c.pb = append(c.pb, Instruction{Op: OpParam, Arg1: paramAddr, Arg2: c.paramCounter, Arg3: nil, Type: t})
 		c.paramCounter += 1
 		c.pop(2)
 		c.i++
@@ -345,12 +554,16 @@ func (c *Codegen) callEndAction() {
 		funcName := c.topString()
 		returnTemp := c.getTemp()
 
+		// a missing method was reported by @method_call
 		ret, ok := c.functionReturns[funcName]
-		if !ok {
+		if !ok && !strings.Contains(funcName, ".") {
 			c.addUndefinedFunctionError(funcName, c.currentToken.Pos)
 		}
 
 		c.setVariableType(returnTemp, ret)
+		if full, ok := c.returnTypes[funcName]; ok {
+			c.setType(returnTemp, full)
+		}
 
 		c.pb = append(c.pb, Instruction{Op: OpCall, Arg1: funcName, Arg2: c.argsCounter, Arg3: returnTemp, Type: c.functionReturns[funcName]})
 		c.argsCounter = 0
@@ -365,6 +578,19 @@ func (c *Codegen) callEndAction() {
 func (c *Codegen) argAction() {
 	if c.ss.Size() >= 1 {
 		arg := c.top()
+
+		// strings, arrays, lists, structs and enums are checked against the parameter they are passed as
+		if params := c.functionParams[c.topStringMinus(1)]; c.argsCounter < len(params) {
+			typ := params[c.argsCounter]
+			t, _ := c.parseType(typ)
+			if argT := c.GetVariableType(arg); checked(t) || checked(argT) {
+				if !c.fits(typ, arg) {
+					c.addTypeMismatchError(c.declaredType(typ), c.typeOf(arg), c.currentToken.Pos)
+				}
+			}
+			arg = c.widen(arg, t)
+		}
+
 		c.pb = append(c.pb, Instruction{Op: OpArg, Arg1: arg, Arg2: c.argsCounter, Arg3: nil, Type: c.GetVariableType(arg)})
 		c.argsCounter += 1
 		c.pop(1)
@@ -372,10 +598,30 @@ func (c *Codegen) argAction() {
 	}
 }
 
+// checked reports whether an argument or parameter of type t is checked when a function is called
+func checked(t lexer.TokenType) bool {
+	return reference(t) || t == lexer.ENUM || t == lexer.STR
+}
+
+// returnType returns the declared return type of the function funcName, nil when it is unknown
+func (c *Codegen) returnType(funcName string) fmt.Stringer {
+	if full, ok := c.returnTypes[funcName]; ok {
+		return full
+	}
+	if t, ok := c.functionReturns[funcName]; ok {
+		return t
+	}
+	return nil
+}
+
 // returnAction generates the return instruction for a function
 func (c *Codegen) returnAction() {
 	if c.ss.Size() >= 1 {
 		returnVal := c.top()
+		if want := c.returnType(c.function); want != nil && !c.fitsType(want, returnVal) {
+			c.addTypeMismatchError(want, c.typeOf(returnVal), c.currentToken.Pos)
+		}
+		returnVal = c.widen(returnVal, c.functionReturns[c.function])
 		c.pb = append(c.pb, Instruction{Op: OpRet, Arg1: returnVal, Arg2: nil, Arg3: nil, Type: c.GetVariableType(returnVal)})
 		c.pop(1)
 	} else {
@@ -415,11 +661,48 @@ func (c *Codegen) captureParamNameAction() {
 
 // captureTypeAction stores the type for variable/parameter declaration
 func (c *Codegen) captureTypeAction() {
-	c.pushString(c.currentToken.Lexeme)
+	if c.currentToken.Type == lexer.RSBRACE {
+		return
+	}
+	typ := c.currentToken.Lexeme
+	if c.currentToken.Type == lexer.ID {
+		if _, ok := c.checkNamedType(typ); !ok {
+			// an int after an error, so that it is not reported again
+			typ = "int"
+		}
+	}
+	if c.ss.Size() >= 1 && strings.HasPrefix(c.topString(), "[") {
+		typ = c.topString() + typ
+		c.popString(1)
+	}
+	c.pushString(typ)
 }
 
 // funcReturnTypeAction stores the return type for a function
 func (c *Codegen) funcReturnTypeAction() {
+	if c.ss.Size() >= 2 && strings.HasPrefix(c.topString(), "[") {
+		c.addArrayReturnError(c.topStringMinus(1), c.currentToken.Pos)
+		c.popString(1)
+	}
+	if c.ss.Size() >= 2 && c.currentToken.Type == lexer.RSBRACE {
+		funcName := c.topStringMinus(1)
+		t, l := c.parseType(c.topString())
+		c.functionReturns[funcName] = t
+		c.returnTypes[funcName] = l
+		c.pop(2)
+		return
+	}
+	if c.ss.Size() >= 1 && c.currentToken.Type == lexer.ID {
+		funcName := c.topString()
+		if full, ok := c.checkNamedType(c.currentToken.Lexeme); ok {
+			c.functionReturns[funcName] = entry(full)
+			c.returnTypes[funcName] = full
+		} else {
+			c.functionReturns[funcName] = lexer.INT
+		}
+		c.pop(1)
+		return
+	}
 	if c.ss.Size() >= 1 {
 		funcName := c.topString()
 		c.functionReturns[funcName] = lexer.Keywords[c.currentToken.Lexeme]
@@ -449,6 +732,9 @@ func (c *Codegen) callAction() {
 	returnTemp := c.getTemp()
 
 	c.setVariableType(returnTemp, c.functionReturns[funcName])
+	if full, ok := c.returnTypes[funcName]; ok {
+		c.setType(returnTemp, full)
+	}
 
 	c.pb = append(c.pb, Instruction{Op: OpCall, Arg1: funcName, Arg2: 0, Arg3: returnTemp, Type: c.functionReturns[funcName]})
 	c.push(returnTemp)
@@ -480,9 +766,12 @@ func (c *Codegen) ExecuteAction(actionName string) {
 		"@assign":                c.assignAction,
 		"@define":                c.defineAction,
 		"@print":                 c.printAction,
-		"@or":                    func() { c.binaryOpAction(OpOr) },
-		"@and":                   func() { c.binaryOpAction(OpAnd) },
+		"@or":                    func() { c.logicalOpAction(OpOr) },
+		"@and":                   func() { c.logicalOpAction(OpAnd) },
 		"@not":                   c.notAction,
+		"@neg":                   c.negAction,
+		"@plus":                  c.plusAction,
+		"@len":                   c.lenAction,
 		"@rel":                   c.relAction,
 		"@func_start":            c.functionStartAction,
 		"@func_end":              c.funcEndAction,
@@ -501,10 +790,36 @@ func (c *Codegen) ExecuteAction(actionName string) {
 		"@push_relop":            c.pushRelOpAction,
 		"@save_break":            c.saveBreakAction,
 		"@label_while":           c.labelWhileAction,
+		"@array_len":             c.arrayLenAction,
+		"@array_start":           c.arrayStartAction,
+		"@array_lit":             c.arrayLitAction,
+		"@bounds":                c.boundsAction,
+		"@index":                 c.indexAction,
+		"@index_assign":          c.indexAssignAction,
+		"@list_type":             c.listTypeAction,
+		"@append":                c.appendAction,
+		"@struct_decl":           c.structDeclAction,
+		"@capture_field_name":    c.captureFieldNameAction,
+		"@struct_field":          c.structFieldAction,
+		"@struct_end":            c.structEndAction,
+		"@field":                 c.fieldAction,
+		"@field_target":          c.fieldTargetAction,
+		"@field_assign":          c.fieldAssignAction,
+		"@struct_lit_start":      c.structLitStartAction,
+		"@struct_lit":            c.structLitAction,
+		"@method_start":          c.methodStartAction,
+		"@method_call":           c.methodCallAction,
+		"@enum_decl":             c.enumDeclAction,
+		"@variant":               c.variantAction,
+		"@enum_end":              c.enumEndAction,
+		"@match_start":           c.matchStartAction,
+		"@arm":                   c.armAction,
+		"@arm_end":               c.armEndAction,
+		"@match_end":             c.matchEndAction,
 	}
 
 	if action, exists := SemanticActions[actionName]; exists {
-		action()
+		c.traceAction(actionName, action)
 	} else {
 		log.Error("Unknown semantic action", "action", actionName)
 	}
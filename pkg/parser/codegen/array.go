@@ -0,0 +1,328 @@
+package codegen
+
+import (
+	"dolme/pkg/lexer"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// maxArrayLen is the longest array a type may declare
+const maxArrayLen = 1 << 20
+
+// ArrayType is the type of a fixed-size array such as [10]float
+type ArrayType struct {
+	Elem lexer.TokenType
+	Len  int
+}
+
+// String renders the type the way it is written, [] for an empty literal
+func (a ArrayType) String() string {
+	if a.Elem == lexer.EOF {
+		return "[]"
+	}
+	return fmt.Sprintf("[%d]%v", a.Len, a.Elem)
+}
+
+// parseType reads a type captured by @capture_type into its type table entry and the type itself
+func (c *Codegen) parseType(typ string) (lexer.TokenType, fmt.Stringer) {
+	if elem, ok := strings.CutPrefix(typ, "list["); ok {
+		return lexer.LIST, ListType{Elem: lexer.Keywords[strings.TrimSuffix(elem, "]")]}
+	}
+	if s, ok := c.structTypes[typ]; ok {
+		return lexer.STRUCT, s
+	}
+	if e, ok := c.enumTypes[typ]; ok {
+		return lexer.ENUM, e
+	}
+
+	n, elem, ok := strings.Cut(strings.TrimPrefix(typ, "["), "]")
+	if !strings.HasPrefix(typ, "[") || !ok {
+		return lexer.Keywords[typ], lexer.Keywords[typ]
+	}
+
+	length, _ := strconv.Atoi(n)
+	return lexer.LSBRACE, ArrayType{Elem: lexer.Keywords[elem], Len: length}
+}
+
+// declaredType returns a captured type for error messages
+func (c *Codegen) declaredType(typ string) fmt.Stringer {
+	_, full := c.parseType(typ)
+	return full
+}
+
+// reference reports whether values of type t share their elements when copied
+func reference(t lexer.TokenType) bool {
+	return t == lexer.LSBRACE || t == lexer.LIST || t == lexer.STRUCT
+}
+
+// entry returns the type table entry of the type full
+func entry(full fmt.Stringer) lexer.TokenType {
+	switch full := full.(type) {
+	case ArrayType:
+		return lexer.LSBRACE
+	case ListType:
+		return lexer.LIST
+	case *StructType:
+		return lexer.STRUCT
+	case *EnumType:
+		return lexer.ENUM
+	case lexer.TokenType:
+		return full
+	}
+	return lexer.EOF
+}
+
+// typeOf returns the full type of the value at addr
+func (c *Codegen) typeOf(addr int) fmt.Stringer {
+	if arr, ok := c.arrays[addr]; ok {
+		return arr
+	}
+	if list, ok := c.lists[addr]; ok {
+		return list
+	}
+	if s, ok := c.structs[addr]; ok {
+		return s
+	}
+	if e, ok := c.enums[addr]; ok {
+		return e
+	}
+	return c.GetVariableType(addr)
+}
+
+// setType gives addr the type full, dropping any compound type an earlier function left at addr
+func (c *Codegen) setType(addr int, full fmt.Stringer) {
+	delete(c.arrays, addr)
+	delete(c.lists, addr)
+	delete(c.structs, addr)
+	delete(c.enums, addr)
+	c.setVariableType(addr, entry(full))
+	switch full := full.(type) {
+	case ArrayType:
+		c.arrays[addr] = full
+	case ListType:
+		c.lists[addr] = full
+	case *StructType:
+		c.structs[addr] = full
+	case *EnumType:
+		c.enums[addr] = full
+	}
+}
+
+// declareType gives addr the captured type typ and returns its type table entry
+func (c *Codegen) declareType(addr int, typ string) lexer.TokenType {
+	t, full := c.parseType(typ)
+	c.setType(addr, full)
+	return t
+}
+
+// fits reports whether the value at addr can be stored where the captured type typ is expected
+func (c *Codegen) fits(typ string, addr int) bool {
+	return c.fitsType(c.declaredType(typ), addr)
+}
+
+// fitsType reports whether the value at addr can be stored in a variable of type want, retyping literals to fit
+func (c *Codegen) fitsType(want fmt.Stringer, addr int) bool {
+	// a value of unknown type was reported where it came from
+	if c.GetVariableType(addr) == lexer.EOF {
+		return true
+	}
+
+	switch want := want.(type) {
+	case ArrayType:
+		if idx, ok := c.arrayLits[addr]; ok {
+			lit := c.arrays[addr]
+			if lit.Len != 0 && (lit.Len != want.Len || !assignable(want.Elem, lit.Elem)) {
+				return false
+			}
+
+			c.pb[idx].Arg1 = "#" + strconv.Itoa(want.Len)
+			for k := idx; k <= idx+lit.Len; k++ {
+				c.pb[k].Type = want.Elem
+			}
+			c.arrays[addr] = want
+			delete(c.arrayLits, addr)
+			return true
+		}
+
+		got, ok := c.arrays[addr]
+		return ok && got == want
+
+	case ListType:
+		if idx, ok := c.arrayLits[addr]; ok {
+			lit := c.arrays[addr]
+			if lit.Len != 0 && !assignable(want.Elem, lit.Elem) {
+				return false
+			}
+
+			c.pb[idx] = Instruction{Op: OpList, Arg1: nil, Arg2: nil, Arg3: c.pb[idx].Arg3, Type: want.Elem}
+			for k := idx + 1; k <= idx+lit.Len; k++ {
+				c.pb[k] = Instruction{Op: OpAppend, Arg1: c.pb[k].Arg1, Arg2: nil, Arg3: c.pb[k].Arg3, Type: want.Elem}
+			}
+			c.setType(addr, want)
+			delete(c.arrayLits, addr)
+			return true
+		}
+
+		got, ok := c.lists[addr]
+		return ok && got == want
+
+	case *StructType:
+		got, ok := c.structs[addr]
+		return ok && got == want
+
+	case *EnumType:
+		got, ok := c.enums[addr]
+		return ok && got == want
+
+	case lexer.TokenType:
+		return assignable(want, c.GetVariableType(addr))
+	}
+
+	return false
+}
+
+// arrayLenAction pushes [n] for @capture_type to complete with the element type
+func (c *Codegen) arrayLenAction() {
+	n, err := strconv.Atoi(c.currentToken.Lexeme)
+	if err != nil || n <= 0 || n > maxArrayLen {
+		c.addInvalidArrayLengthError(c.currentToken.Lexeme, c.currentToken.Pos)
+		n = 1
+	}
+	c.pushString(fmt.Sprintf("[%d]", n))
+}
+
+// arrayStartAction marks where the elements of an array literal start on the stack
+func (c *Codegen) arrayStartAction() {
+	c.pushString("$array")
+}
+
+// arrayLitAction generates code for an array literal
+func (c *Codegen) arrayLitAction() {
+	n := 0
+	for n < c.ss.Size() && c.topStringMinus(n) != "$array" {
+		n++
+	}
+	if n == c.ss.Size() {
+		return
+	}
+
+	elems := make([]int, n)
+	for k := range elems {
+		elems[k] = c.topMinus(n - 1 - k)
+	}
+	c.pop(n + 1)
+
+	arr := ArrayType{Elem: lexer.EOF, Len: n}
+	if n > 0 {
+		// elements of unknown type were reported where they came from
+		first := elems[0]
+		for _, e := range elems {
+			if first = e; c.GetVariableType(e) != lexer.EOF {
+				break
+			}
+		}
+		arr.Elem = c.GetVariableType(first)
+		if reference(arr.Elem) {
+			c.addTypeMismatchError(lexer.INT, c.typeOf(first), c.currentToken.Pos)
+			arr.Elem = lexer.INT
+		}
+		for _, e := range elems {
+			if arr.Elem == lexer.INT && c.GetVariableType(e) == lexer.FLOAT {
+				arr.Elem = lexer.FLOAT
+			}
+		}
+	}
+
+	t := c.getTemp()
+	c.setVariableType(t, lexer.LSBRACE)
+	c.arrays[t] = arr
+	c.arrayLits[t] = len(c.pb)
+
+	c.pb = append(c.pb, Instruction{Op: OpAlloc, Arg1: "#" + strconv.Itoa(n), Arg2: nil, Arg3: t, Type: arr.Elem})
+	c.i++
+	for k, e := range elems {
+		if !assignable(arr.Elem, c.GetVariableType(e)) {
+			c.addTypeMismatchError(arr.Elem, c.typeOf(e), c.currentToken.Pos)
+		}
+		c.pb = append(c.pb, Instruction{Op: OpIndexStore, Arg1: e, Arg2: "#" + strconv.Itoa(k), Arg3: t, Type: arr.Elem})
+		c.i++
+	}
+
+	c.push(t)
+}
+
+// boundsAction checks the index of an element access against the length of the array or list
+func (c *Codegen) boundsAction() {
+	if c.ss.Size() >= 2 {
+		index := c.top()
+		base := c.topMinus(1)
+
+		var length any
+		if arr, ok := c.arrays[base]; ok {
+			length = "#" + strconv.Itoa(arr.Len)
+		} else if _, ok := c.lists[base]; ok {
+			length = c.listLen(base)
+		} else {
+			c.addNotIndexableError(c.typeOf(base), c.currentToken.Pos)
+			return
+		}
+		if t := c.GetVariableType(index); t != lexer.INT && t != lexer.EOF {
+			c.addTypeMismatchError(lexer.INT, c.typeOf(index), c.currentToken.Pos)
+		}
+
+		c.pb = append(c.pb, Instruction{Op: OpBounds, Arg1: index, Arg2: length, Arg3: c.currentToken.Pos.Line, Type: lexer.INT})
+		c.i++
+	}
+}
+
+// elements returns what ldx and stx index for the array or list at base, with the element type
+func (c *Codegen) elements(base int) (int, lexer.TokenType, bool) {
+	if arr, ok := c.arrays[base]; ok {
+		return base, arr.Elem, true
+	}
+	if l, ok := c.lists[base]; ok {
+		return c.listElems(base, l), l.Elem, true
+	}
+	return base, lexer.EOF, false
+}
+
+// indexAction generates code to read an element of an array or a list
+func (c *Codegen) indexAction() {
+	if c.ss.Size() >= 2 {
+		index := c.top()
+		base := c.topMinus(1)
+
+		// an int after an error, so that it is not reported again
+		base, elem, ok := c.elements(base)
+		if !ok {
+			elem = lexer.INT
+		}
+
+		t := c.getTemp()
+		c.setVariableType(t, elem)
+
+		c.pb = append(c.pb, Instruction{Op: OpIndexLoad, Arg1: base, Arg2: index, Arg3: t, Type: elem})
+		c.pop(2)
+		c.push(t)
+		c.i++
+	}
+}
+
+// indexAssignAction generates code to write an element of an array or a list
+func (c *Codegen) indexAssignAction() {
+	if c.ss.Size() >= 3 {
+		value := c.top()
+		index := c.topMinus(1)
+		base := c.topMinus(2)
+
+		if base, elem, ok := c.elements(base); ok {
+			if !assignable(elem, c.GetVariableType(value)) {
+				c.addTypeMismatchError(elem, c.typeOf(value), c.currentToken.Pos)
+			}
+			c.pb = append(c.pb, Instruction{Op: OpIndexStore, Arg1: value, Arg2: index, Arg3: base, Type: elem})
+			c.i++
+		}
+		c.pop(3)
+	}
+}
@@ -81,12 +81,14 @@ func (a *arm64Macos) collectStackLayout() {
 			}
 			// if this instruction writes to a local destination (Arg3) and has a known type,
 			// record that type in the per-function map to disambiguate float/int locals.
-			if inFunc && currFunc != "" && instr.Arg3 != nil {
+			// An element store, an append and a field store read their array, list or
+			// struct from Arg3 and write nothing there.
+			if inFunc && currFunc != "" && instr.Arg3 != nil && !readsArg3(instr.Op) {
 				if dst, ok := instr.Arg3.(int); ok && instr.Type != 0 {
 					if _, ok := a.funcTypes[currFunc]; !ok {
 						a.funcTypes[currFunc] = make(map[int]lexer.TokenType)
 					}
-					a.funcTypes[currFunc][dst] = instr.Type
+					a.funcTypes[currFunc][dst] = instr.ResultType()
 				}
 			}
 		}
@@ -157,8 +159,13 @@ func (a *arm64Macos) collectStackLayout() {
 	}
 }
 
+// readsArg3 reports whether op reads the array, list or struct it writes into from Arg3
+func readsArg3(op codegen.Operation) bool {
+	return op == codegen.OpIndexStore || op == codegen.OpAppend || op == codegen.OpFieldStore
+}
+
 // instructionAddresses returns a slice of integer addresses referenced by instr (Arg1, Arg2, Arg3)
-// only returns values that are of type int
+// only returns values that are of type int; Arg3 of a bounds check is a source line
 func (a *arm64Macos) instructionAddresses(instr codegen.Instruction) []int {
 	out := make([]int, 0, 3)
 	if v, ok := instr.Arg1.(int); ok {
@@ -167,7 +174,7 @@ func (a *arm64Macos) instructionAddresses(instr codegen.Instruction) []int {
 	if v, ok := instr.Arg2.(int); ok {
 		out = append(out, v)
 	}
-	if v, ok := instr.Arg3.(int); ok {
+	if v, ok := instr.Arg3.(int); ok && instr.Op != codegen.OpBounds {
 		out = append(out, v)
 	}
 	return out
@@ -196,7 +203,7 @@ func (a *arm64Macos) collectLabelsAndCallArgs() {
 
 	// collect call args: look backward from OpCall to collect preceding OpArg entries
 	for idx, instr := range a.pb {
-		if instr.Op == codegen.OpCall {
+		if instr.Op == codegen.OpCall || instr.Op == codegen.OpTailCall {
 			argCount := 0
 			if n, ok := instr.Arg2.(int); ok {
 				argCount = n
@@ -204,7 +211,7 @@ func (a *arm64Macos) collectLabelsAndCallArgs() {
 			args := make([]codegen.Instruction, argCount)
 			// scan backwards collecting OpArg with matching Arg2 (position)
 			for j := idx - 1; j >= 0; j-- {
-				if argCount == 0 || a.pb[j].Op == codegen.OpCall {
+				if argCount == 0 || a.pb[j].Op == codegen.OpCall || a.pb[j].Op == codegen.OpTailCall {
 					break
 				}
 				pj := a.pb[j]
@@ -255,7 +262,7 @@ func (a *arm64Macos) emitFunctions() {
 		// allocate locals for this function if any
 		size := a.localSizes[name]
 		if size > 0 {
-			a.addText(fmt.Sprintf("\tsub\tSP, SP, #%d", size))
+			a.adjustSP("sub", size)
 		}
 
 		// Emit instructions strictly in [idx+1 .. endIdx]
@@ -294,10 +301,42 @@ func (a *arm64Macos) emitFunctions() {
 				// no-op in linear emission, handled at call sites
 			case codegen.OpCall:
 				a.emitCallAtIndex(in, j, currentFunc)
+			case codegen.OpTailCall:
+				a.emitTailCallAtIndex(in, j, currentFunc)
 			case codegen.OpAssign:
 				a.emitAssign(in, currentFunc)
-			case codegen.OpAdd, codegen.OpSub, codegen.OpMul, codegen.OpDiv, codegen.OpMod, codegen.OpAnd, codegen.OpOr, codegen.OpEq, codegen.OpNeq, codegen.OpLt, codegen.OpLe, codegen.OpGt, codegen.OpGe:
+			case codegen.OpAdd, codegen.OpSub, codegen.OpMul, codegen.OpDiv, codegen.OpMod, codegen.OpShl, codegen.OpShr, codegen.OpUShr, codegen.OpAnd, codegen.OpOr, codegen.OpEq, codegen.OpNeq, codegen.OpLt, codegen.OpLe, codegen.OpGt, codegen.OpGe:
 				a.emitBinary(in, currentFunc)
+			case codegen.OpNot:
+				a.emitNot(in, currentFunc)
+			case codegen.OpNeg:
+				a.emitNeg(in, currentFunc)
+			case codegen.OpConcat:
+				a.emitConcat(in, currentFunc)
+			case codegen.OpLen:
+				a.emitLen(in, currentFunc)
+			case codegen.OpAlloc:
+				a.emitAlloc(in, j, currentFunc)
+			case codegen.OpIndexLoad:
+				a.emitIndexLoad(in, currentFunc)
+			case codegen.OpIndexStore:
+				a.emitIndexStore(in, currentFunc)
+			case codegen.OpBounds:
+				a.emitBounds(in, j, currentFunc)
+			case codegen.OpList:
+				a.emitList(in, currentFunc)
+			case codegen.OpAppend:
+				a.emitAppend(in, j, currentFunc)
+			case codegen.OpListLen:
+				a.emitListField(in, 8, currentFunc)
+			case codegen.OpElems:
+				a.emitListField(in, 0, currentFunc)
+			case codegen.OpStruct:
+				a.emitStruct(in, currentFunc)
+			case codegen.OpFieldLoad:
+				a.emitFieldLoad(in, currentFunc)
+			case codegen.OpFieldStore:
+				a.emitFieldStore(in, currentFunc)
 			case codegen.OpPrint:
 				a.emitPrint(in, currentFunc)
 			case codegen.OpJmp:
@@ -310,9 +349,7 @@ func (a *arm64Macos) emitFunctions() {
 					switch v := in.Arg1.(type) {
 					case string:
 						if strings.HasPrefix(v, "#") {
-							val := v[1:]
-							val = normalizeImmediate(val)
-							a.addText(fmt.Sprintf("\tmov\tX0, #%s", val))
+							a.loadOperandToReg("X0", v, currentFunc)
 						}
 					case int:
 						off := a.addrOffset(v, currentFunc)
@@ -337,11 +374,11 @@ func (a *arm64Macos) emitFunctions() {
 
 		// Emit epilogue (safe to emit unconditionally)
 		if currentFuncLocalsSize > 0 && false {
-			a.addText(fmt.Sprintf("\tadd\tSP, SP, #%d", currentFuncLocalsSize))
+			a.adjustSP("add", currentFuncLocalsSize)
 		}
 
 		if size > 0 {
-			a.addText(fmt.Sprintf("\tadd\tSP, SP, #%d", size))
+			a.adjustSP("add", size)
 		}
 		a.addText("\tldp\tX29, X30, [SP], #16")
 		a.addText("\tret")
@@ -364,7 +401,7 @@ func (a *arm64Macos) emitMain() {
 
 	// allocate global frame space on stack
 	if a.globalSize > 0 {
-		a.addText(fmt.Sprintf("\tsub\tSP, SP, #%d", a.globalSize))
+		a.adjustSP("sub", a.globalSize)
 	}
 
 	// iterate PB and emit only top-level instructions (skip function bodies and OpEnd)
@@ -404,8 +441,38 @@ func (a *arm64Macos) emitMain() {
 			a.emitCallAtIndex(instr, idx, "")
 		case codegen.OpAssign:
 			a.emitAssign(instr, "")
-		case codegen.OpAdd, codegen.OpSub, codegen.OpMul, codegen.OpDiv, codegen.OpMod, codegen.OpAnd, codegen.OpOr, codegen.OpEq, codegen.OpNeq, codegen.OpLt, codegen.OpLe, codegen.OpGt, codegen.OpGe:
+		case codegen.OpAdd, codegen.OpSub, codegen.OpMul, codegen.OpDiv, codegen.OpMod, codegen.OpShl, codegen.OpShr, codegen.OpUShr, codegen.OpAnd, codegen.OpOr, codegen.OpEq, codegen.OpNeq, codegen.OpLt, codegen.OpLe, codegen.OpGt, codegen.OpGe:
 			a.emitBinary(instr, "")
+		case codegen.OpNot:
+			a.emitNot(instr, "")
+		case codegen.OpNeg:
+			a.emitNeg(instr, "")
+		case codegen.OpConcat:
+			a.emitConcat(instr, "")
+		case codegen.OpLen:
+			a.emitLen(instr, "")
+		case codegen.OpAlloc:
+			a.emitAlloc(instr, idx, "")
+		case codegen.OpIndexLoad:
+			a.emitIndexLoad(instr, "")
+		case codegen.OpIndexStore:
+			a.emitIndexStore(instr, "")
+		case codegen.OpBounds:
+			a.emitBounds(instr, idx, "")
+		case codegen.OpList:
+			a.emitList(instr, "")
+		case codegen.OpAppend:
+			a.emitAppend(instr, idx, "")
+		case codegen.OpListLen:
+			a.emitListField(instr, 8, "")
+		case codegen.OpElems:
+			a.emitListField(instr, 0, "")
+		case codegen.OpStruct:
+			a.emitStruct(instr, "")
+		case codegen.OpFieldLoad:
+			a.emitFieldLoad(instr, "")
+		case codegen.OpFieldStore:
+			a.emitFieldStore(instr, "")
 		case codegen.OpPrint:
 			a.emitPrint(instr, "")
 		case codegen.OpJmp:
@@ -418,9 +485,7 @@ func (a *arm64Macos) emitMain() {
 				switch v := instr.Arg1.(type) {
 				case string:
 					if strings.HasPrefix(v, "#") {
-						val := v[1:]
-						val = normalizeImmediate(val)
-						a.addText(fmt.Sprintf("\tmov\tX0, #%s", val))
+						a.loadOperandToReg("X0", v, "")
 					}
 				case int:
 					off := a.addrOffset(v, "")
@@ -429,7 +494,7 @@ func (a *arm64Macos) emitMain() {
 			}
 			// cleanup and return from main
 			if a.globalSize > 0 {
-				a.addText(fmt.Sprintf("\tadd\tSP, SP, #%d", a.globalSize))
+				a.adjustSP("add", a.globalSize)
 			}
 			a.addText("\tldp\tX29, X30, [SP], #16")
 			a.addText("\tret")
@@ -448,7 +513,7 @@ func (a *arm64Macos) emitMain() {
 	}
 
 	if a.globalSize > 0 {
-		a.addText(fmt.Sprintf("\tadd\tSP, SP, #%d", a.globalSize))
+		a.adjustSP("add", a.globalSize)
 	}
 	a.addText("\tldp\tX29, X30, [SP], #16")
 	a.addText("\tret")
@@ -551,7 +616,7 @@ func (a *arm64Macos) emitAssign(instr codegen.Instruction, funcName string) {
 		if strings.HasPrefix(v, "#") {
 			val := normalizeImmediate(v[1:])
 			// string literal?
-			if strings.HasPrefix(val, "\"") || instr.Type == lexer.STRING {
+			if strings.HasPrefix(val, "\"") || instr.Type == lexer.STR {
 				// emit cstring and point to it
 				label := a.storeCString(val)
 				a.addText(fmt.Sprintf("\tadrp\tX0, %s@PAGE", label))
@@ -588,10 +653,18 @@ func (a *arm64Macos) emitBinary(instr codegen.Instruction, funcName string) {
 	destAddr, _ := instr.Arg3.(int)
 	destOff := a.addrOffset(destAddr, funcName)
 
+	// strings compare through strcmp
+	if instr.Type == lexer.STR {
+		a.emitStringCompare(instr, funcName)
+		return
+	}
+
 	// Determine whether to use FP path:
 	useFloat := false
-	// If instruction explicitly typed as float, prefer FP
-	if instr.Type == lexer.FLOAT {
+	// shifts only exist on ints; otherwise if instruction explicitly typed as float, prefer FP
+	if instr.Op == codegen.OpShl || instr.Op == codegen.OpShr || instr.Op == codegen.OpUShr {
+		useFloat = false
+	} else if instr.Type == lexer.FLOAT {
 		useFloat = true
 	} else {
 		// Check operand types: if either operand is float, use float path
@@ -677,6 +750,12 @@ func (a *arm64Macos) emitBinary(instr codegen.Instruction, funcName string) {
 		a.addText("\tsdiv\tX2, X0, X1")
 		a.addText("\tmul\tX2, X2, X1")
 		a.addText("\tsub\tX0, X0, X2")
+	case codegen.OpShl:
+		a.addText("\tlsl\tX0, X0, X1")
+	case codegen.OpShr:
+		a.addText("\tasr\tX0, X0, X1")
+	case codegen.OpUShr:
+		a.addText("\tlsr\tX0, X0, X1")
 	case codegen.OpAnd:
 		a.addText("\tand\tX0, X0, X1")
 	case codegen.OpOr:
@@ -707,12 +786,266 @@ func (a *arm64Macos) emitBinary(instr codegen.Instruction, funcName string) {
 	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
 }
 
+// emitNot emits logical negation; booleans are stored as 0 or 1
+func (a *arm64Macos) emitNot(instr codegen.Instruction, funcName string) {
+	destAddr, _ := instr.Arg3.(int)
+	a.loadOperandToReg("X0", instr.Arg1, funcName)
+	a.addText("\tcmp\tX0, #0")
+	a.addText("\tcset\tX0, eq")
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", a.addrOffset(destAddr, funcName)))
+}
+
+// emitNeg emits arithmetic negation, on d0 for floats and X0 otherwise
+func (a *arm64Macos) emitNeg(instr codegen.Instruction, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	if instr.Type == lexer.FLOAT || a.isOpFloat(instr.Arg1, funcName) {
+		a.loadOperandToFPReg("d0", instr, funcName)
+		a.addText("\tfneg\td0, d0")
+		a.addText(fmt.Sprintf("\tstr\td0, [SP, #%d]", destOff))
+		return
+	}
+	a.loadOperandToReg("X0", instr.Arg1, funcName)
+	a.addText("\tneg\tX0, X0")
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// emitStringCompare emits == and != on strings: strcmp returns 0 for equal strings
+func (a *arm64Macos) emitStringCompare(instr codegen.Instruction, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	a.loadOperandToReg("X0", instr.Arg1, funcName)
+	a.loadOperandToReg("X1", instr.Arg2, funcName)
+	a.addText("\tbl\t_strcmp")
+	a.addText("\tcmp\tW0, #0")
+	if instr.Op == codegen.OpNeq {
+		a.addText("\tcset\tX0, ne")
+	} else {
+		a.addText("\tcset\tX0, eq")
+	}
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// emitConcat emits string concatenation into a fresh malloc'd buffer. The
+// operands may share the destination slot, so the lengths and the buffer are
+// kept in a 16-byte scratch area below the frame until the copies are done.
+func (a *arm64Macos) emitConcat(instr codegen.Instruction, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	operand := func(reg string, op any) {
+		if addr, ok := op.(int); ok {
+			a.addText(fmt.Sprintf("\tldr\t%s, [SP, #%d]", reg, a.addrOffset(addr, funcName)+16))
+			return
+		}
+		a.loadOperandToReg(reg, op, funcName)
+	}
+
+	a.addText("\tsub\tSP, SP, #16")
+	operand("X0", instr.Arg1)
+	a.addText("\tbl\t_strlen")
+	a.addText("\tstr\tX0, [SP, #0]")
+	operand("X0", instr.Arg2)
+	a.addText("\tbl\t_strlen")
+	a.addText("\tldr\tX1, [SP, #0]")
+	a.addText("\tadd\tX0, X0, X1")
+	a.addText("\tadd\tX0, X0, #1")
+	a.addText("\tbl\t_malloc")
+	a.addText("\tstr\tX0, [SP, #8]")
+	operand("X1", instr.Arg1)
+	a.addText("\tbl\t_strcpy")
+	a.addText("\tldr\tX0, [SP, #8]")
+	operand("X1", instr.Arg2)
+	a.addText("\tbl\t_strcat")
+	a.addText("\tldr\tX0, [SP, #8]")
+	a.addText("\tadd\tSP, SP, #16")
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// emitLen emits the length of a string through strlen
+func (a *arm64Macos) emitLen(instr codegen.Instruction, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	a.loadOperandToReg("X0", instr.Arg1, funcName)
+	a.addText("\tbl\t_strlen")
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// emitAlloc emits a new array zeroed by calloc, with string elements set to ""
+func (a *arm64Macos) emitAlloc(instr codegen.Instruction, idx int, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	n := 0
+	if s, ok := instr.Arg1.(string); ok {
+		n, _ = strconv.Atoi(strings.TrimPrefix(s, "#"))
+	}
+
+	a.movImm("X0", n)
+	a.addText("\tmov\tX1, #8")
+	a.addText("\tbl\t_calloc")
+
+	if instr.Type == lexer.STR {
+		empty := a.storeCString("")
+		a.addText(fmt.Sprintf("\tadrp\tX9, %s@PAGE", empty))
+		a.addText(fmt.Sprintf("\tadd\tX9, X9, %s@PAGEOFF", empty))
+		a.movImm("X10", n)
+		a.addText(fmt.Sprintf("Lfill%d:", idx))
+		a.addText(fmt.Sprintf("\tcbz\tX10, Lfilled%d", idx))
+		a.addText("\tsub\tX10, X10, #1")
+		a.addText("\tstr\tX9, [X0, X10, lsl #3]")
+		a.addText(fmt.Sprintf("\tb\tLfill%d", idx))
+		a.addText(fmt.Sprintf("Lfilled%d:", idx))
+	}
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// emitIndexLoad emits a read of an array element
+func (a *arm64Macos) emitIndexLoad(instr codegen.Instruction, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	a.loadOperandToReg("X9", instr.Arg1, funcName)
+	a.loadOperandToReg("X10", instr.Arg2, funcName)
+	a.addText("\tldr\tX0, [X9, X10, lsl #3]")
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// emitIndexStore emits a write of an array element, converting an int stored into a float array
+func (a *arm64Macos) emitIndexStore(instr codegen.Instruction, funcName string) {
+	if instr.Type == lexer.FLOAT {
+		// loads through x9, so before the array and the index
+		a.loadOperandToFPReg("d0", codegen.Instruction{Op: codegen.OpNop, Arg1: instr.Arg1, Type: lexer.INT}, funcName)
+		a.loadOperandToReg("X9", instr.Arg3, funcName)
+		a.loadOperandToReg("X10", instr.Arg2, funcName)
+		a.addText("\tstr\td0, [X9, X10, lsl #3]")
+		return
+	}
+	a.loadOperandToReg("X0", instr.Arg1, funcName)
+	a.loadOperandToReg("X9", instr.Arg3, funcName)
+	a.loadOperandToReg("X10", instr.Arg2, funcName)
+	a.addText("\tstr\tX0, [X9, X10, lsl #3]")
+}
+
+// emitList emits an empty list header from calloc
+func (a *arm64Macos) emitList(instr codegen.Instruction, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	a.addText("\tmov\tX0, #1")
+	a.addText("\tmov\tX1, #24")
+	a.addText("\tbl\t_calloc")
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// emitListField emits a read of the list header word at off
+func (a *arm64Macos) emitListField(instr codegen.Instruction, off int, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	a.loadOperandToReg("X9", instr.Arg1, funcName)
+	a.addText(fmt.Sprintf("\tldr\tX0, [X9, #%d]", off))
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// emitAppend emits the append at PB index idx, growing the list through realloc when it is full
+func (a *arm64Macos) emitAppend(instr codegen.Instruction, idx int, funcName string) {
+	a.loadOperandToReg("X9", instr.Arg3, funcName)
+	a.addText("\tldr\tX10, [X9, #8]")
+	a.addText("\tldr\tX11, [X9, #16]")
+	a.addText("\tcmp\tX10, X11")
+	a.addText(fmt.Sprintf("\tb.lo\tLappend%d", idx))
+	a.addText("\tlsl\tX11, X11, #1")
+	a.addText("\tmov\tX12, #4")
+	a.addText("\tcmp\tX11, #0")
+	a.addText("\tcsel\tX11, X12, X11, eq")
+	a.addText("\tstr\tX11, [X9, #16]")
+	a.addText("\tldr\tX0, [X9]")
+	a.addText("\tlsl\tX1, X11, #3")
+	a.addText("\tbl\t_realloc")
+	a.loadOperandToReg("X9", instr.Arg3, funcName)
+	a.addText("\tstr\tX0, [X9]")
+	a.addText(fmt.Sprintf("Lappend%d:", idx))
+
+	if instr.Type == lexer.FLOAT {
+		// loads through x9, so before the list
+		a.loadOperandToFPReg("d0", codegen.Instruction{Op: codegen.OpNop, Arg1: instr.Arg1, Type: lexer.INT}, funcName)
+		a.loadOperandToReg("X9", instr.Arg3, funcName)
+		a.addText("\tldp\tX10, X11, [X9]")
+		a.addText("\tstr\td0, [X10, X11, lsl #3]")
+	} else {
+		a.loadOperandToReg("X0", instr.Arg1, funcName)
+		a.loadOperandToReg("X9", instr.Arg3, funcName)
+		a.addText("\tldp\tX10, X11, [X9]")
+		a.addText("\tstr\tX0, [X10, X11, lsl #3]")
+	}
+	a.addText("\tadd\tX11, X11, #1")
+	a.addText("\tstr\tX11, [X9, #8]")
+}
+
+// emitStruct emits a new struct zeroed by calloc
+func (a *arm64Macos) emitStruct(instr codegen.Instruction, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	n := 0
+	if s, ok := instr.Arg1.(string); ok {
+		n, _ = strconv.Atoi(strings.TrimPrefix(s, "#"))
+	}
+
+	a.addText("\tmov\tX0, #1")
+	a.movImm("X1", n)
+	a.addText("\tbl\t_calloc")
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// fieldOffset returns the byte offset of a field access, an immediate in Arg2
+func fieldOffset(instr codegen.Instruction) int {
+	s, _ := instr.Arg2.(string)
+	off, _ := strconv.Atoi(strings.TrimPrefix(s, "#"))
+	return off
+}
+
+// emitFieldLoad emits a read of a struct field
+func (a *arm64Macos) emitFieldLoad(instr codegen.Instruction, funcName string) {
+	destOff := a.addrOffset(instr.Arg3.(int), funcName)
+	a.loadOperandToReg("X9", instr.Arg1, funcName)
+	a.addText(fmt.Sprintf("\tldr\tX0, [X9, #%d]", fieldOffset(instr)))
+	a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", destOff))
+}
+
+// emitFieldStore emits a write of a struct field, converting an int stored into a float field
+func (a *arm64Macos) emitFieldStore(instr codegen.Instruction, funcName string) {
+	off := fieldOffset(instr)
+	if instr.Type == lexer.FLOAT {
+		// loads through x9, so before the struct
+		a.loadOperandToFPReg("d0", codegen.Instruction{Op: codegen.OpNop, Arg1: instr.Arg1, Type: lexer.INT}, funcName)
+		a.loadOperandToReg("X9", instr.Arg3, funcName)
+		a.addText(fmt.Sprintf("\tstr\td0, [X9, #%d]", off))
+		return
+	}
+	a.loadOperandToReg("X0", instr.Arg1, funcName)
+	a.loadOperandToReg("X9", instr.Arg3, funcName)
+	a.addText(fmt.Sprintf("\tstr\tX0, [X9, #%d]", off))
+}
+
+// emitBounds emits the bounds check at PB index idx, exiting with status 1 when it fails
+func (a *arm64Macos) emitBounds(instr codegen.Instruction, idx int, funcName string) {
+	line, _ := instr.Arg3.(int)
+	a.loadOperandToReg("X0", instr.Arg1, funcName)
+	a.loadOperandToReg("X1", instr.Arg2, funcName)
+	// unsigned, so a negative index is out of range too
+	a.addText("\tcmp\tX0, X1")
+	a.addText(fmt.Sprintf("\tb.lo\tLbounds%d", idx))
+
+	fmtLabel := a.ensureBoundsFormat()
+	a.addText("\tsub\tSP, SP, #64")
+	a.addText("\tstr\tX0, [SP, #0]")
+	a.addText("\tstr\tX1, [SP, #8]")
+	a.addText(fmt.Sprintf("\tmov\tX2, #%d", line))
+	a.addText("\tstr\tX2, [SP, #16]")
+	a.addText("\tadrp\tX9, ___stderrp@GOTPAGE")
+	a.addText("\tldr\tX9, [X9, ___stderrp@GOTPAGEOFF]")
+	a.addText("\tldr\tX0, [X9]")
+	a.addText(fmt.Sprintf("\tadrp\tX1, %s@PAGE", fmtLabel))
+	a.addText(fmt.Sprintf("\tadd\tX1, X1, %s@PAGEOFF", fmtLabel))
+	a.addText("\tbl\t_fprintf")
+	a.addText("\tmov\tX0, #1")
+	a.addText("\tbl\t_exit")
+	a.addText(fmt.Sprintf("Lbounds%d:", idx))
+}
+
 // isOpFloat determines whether operand should be treated as float
 func (a *arm64Macos) isOpFloat(op any, funcName string) bool {
 	switch v := op.(type) {
 	case string:
-		// immediate literal like "#3.14" or "#3"
-		if strings.HasPrefix(v, "#") {
+		// immediate literal like "#3.14" or "#3"; string literals are pointers
+		if strings.HasPrefix(v, "#") && !strings.HasPrefix(v, "#\"") {
 			val := v[1:]
 			// consider float if contains a dot or has exponent part
 			if strings.Contains(val, ".") || strings.ContainsAny(val, "eE") {
@@ -739,6 +1072,13 @@ func (a *arm64Macos) isOpFloat(op any, funcName string) bool {
 func (a *arm64Macos) loadOperandToReg(reg string, op any, funcName string) {
 	switch v := op.(type) {
 	case string:
+		if strings.HasPrefix(v, "#\"") {
+			// string literal: the register gets a pointer to it
+			label := a.storeCString(v[1:])
+			a.addText(fmt.Sprintf("\tadrp\t%s, %s@PAGE", reg, label))
+			a.addText(fmt.Sprintf("\tadd\t%s, %s, %s@PAGEOFF", reg, reg, label))
+			return
+		}
 		if strings.HasPrefix(v, "#") {
 			val := normalizeImmediate(v[1:])
 			a.addText(fmt.Sprintf("\tmov\t%s, #%s", reg, val))
@@ -856,7 +1196,11 @@ func (a *arm64Macos) emitPrint(instr codegen.Instruction, funcName string) {
 	case int:
 		off := a.addrOffset(v, funcName)
 		// choose format depending on variable type
-		if a.getVarType(v, funcName) == lexer.FLOAT {
+		if instr.Type == lexer.STR || a.getVarType(v, funcName) == lexer.STR {
+			// strings are pointers to NUL-terminated bytes, puts adds the newline
+			a.addText(fmt.Sprintf("\tldr\tX0, [SP, #%d]", off))
+			a.addText("\tbl\t_puts")
+		} else if a.getVarType(v, funcName) == lexer.FLOAT {
 			fmtLabel := a.ensurePrintfFloatFormat()
 			// Prepare vararg area for GP+FP: we only need FP saved for printf
 			a.addText("\t// prepare register-save / vararg area for printf (float)")
@@ -890,6 +1234,40 @@ func (a *arm64Macos) emitPrint(instr codegen.Instruction, funcName string) {
 // emitCallAtIndex handles OpCall at PB index idx, setting up arguments and calling the function
 func (a *arm64Macos) emitCallAtIndex(instr codegen.Instruction, idx int, funcName string) {
 	funcNameStr, _ := instr.Arg1.(string)
+	a.emitCallArgs(idx, funcName, funcNameStr)
+
+	// now call the function
+	a.addText(fmt.Sprintf("\t// call %s", funcNameStr))
+	a.addText(fmt.Sprintf("\tbl\t_%s", funcNameStr))
+
+	// deallocate the 192-byte register-save area
+	a.addText("\tadd\tSP, SP, #192")
+
+	// store return value (X0 or d0) into return-temp slot if provided
+	if retAddr, ok := instr.Arg3.(int); ok {
+		// determine return type
+		retIsFloat := false
+		if instr.Type == lexer.FLOAT {
+			retIsFloat = true
+		} else if a.getVarType(retAddr, funcName) == lexer.FLOAT {
+			retIsFloat = true
+		}
+
+		if retIsFloat {
+			off := a.addrOffset(retAddr, funcName)
+			a.addText(fmt.Sprintf("\tstr\td0, [SP, #%d]", off))
+		} else {
+			off := a.addrOffset(retAddr, funcName)
+			a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", off))
+		}
+	}
+
+	// restore stack (undo register-save area reservation)
+}
+
+// emitCallArgs reserves the 192-byte register-save area and stores the OpArg
+// values of the call at PB index idx into it, one 16-byte slot per argument
+func (a *arm64Macos) emitCallArgs(idx int, funcName, funcNameStr string) {
 	args := a.callArgs[idx]
 
 	// Reserve register-save area on the stack (caller-side)
@@ -916,7 +1294,7 @@ func (a *arm64Macos) emitCallAtIndex(instr codegen.Instruction, idx int, funcNam
 					argIsFloat = true
 				}
 			case string:
-				if strings.HasPrefix(v, "#") {
+				if strings.HasPrefix(v, "#") && !strings.HasPrefix(v, "#\"") {
 					val := normalizeImmediate(v[1:])
 					if strings.Contains(val, ".") || strings.ContainsAny(val, "eE") {
 						argIsFloat = true
@@ -963,8 +1341,7 @@ func (a *arm64Macos) emitCallAtIndex(instr codegen.Instruction, idx int, funcNam
 			switch v := op.Arg1.(type) {
 			case string:
 				if strings.HasPrefix(v, "#") {
-					val := normalizeImmediate(v[1:])
-					a.addText(fmt.Sprintf("\tmov\tx0, #%s", val))
+					a.loadOperandToReg("x0", v, funcName)
 				} else {
 					log.Error("unexpected arg string", "arg", i, "func", funcNameStr)
 					a.addText("\tmov\tx0, #0")
@@ -984,34 +1361,29 @@ func (a *arm64Macos) emitCallAtIndex(instr codegen.Instruction, idx int, funcNam
 	if len(args) > 8 {
 		log.Warn("emitCallAtIndex: more than 8 args not supported, extras ignored", "func", funcNameStr)
 	}
+}
 
-	// now call the function
-	a.addText(fmt.Sprintf("\t// call %s", funcNameStr))
-	a.addText(fmt.Sprintf("\tbl\t_%s", funcNameStr))
+// emitTailCallAtIndex handles OpTailCall at PB index idx. The arguments are
+// built like for a call, then copied over the current function's own incoming
+// argument slots; the frame is torn down and control branches to the callee,
+// which returns straight to our caller.
+func (a *arm64Macos) emitTailCallAtIndex(instr codegen.Instruction, idx int, funcName string) {
+	funcNameStr, _ := instr.Arg1.(string)
+	a.emitCallArgs(idx, funcName, funcNameStr)
 
-	// deallocate the 192-byte register-save area
+	// incoming args live above the saved X29/X30 pair; params were already copied into locals
+	for k := range a.callArgs[idx] {
+		a.addText(fmt.Sprintf("\tldr\tx9, [SP, #%d]", k*16))
+		a.addText(fmt.Sprintf("\tstr\tx9, [X29, #%d]", 16+k*16))
+	}
 	a.addText("\tadd\tSP, SP, #192")
 
-	// store return value (X0 or d0) into return-temp slot if provided
-	if retAddr, ok := instr.Arg3.(int); ok {
-		// determine return type
-		retIsFloat := false
-		if instr.Type == lexer.FLOAT {
-			retIsFloat = true
-		} else if a.getVarType(retAddr, funcName) == lexer.FLOAT {
-			retIsFloat = true
-		}
-
-		if retIsFloat {
-			off := a.addrOffset(retAddr, funcName)
-			a.addText(fmt.Sprintf("\tstr\td0, [SP, #%d]", off))
-		} else {
-			off := a.addrOffset(retAddr, funcName)
-			a.addText(fmt.Sprintf("\tstr\tX0, [SP, #%d]", off))
-		}
+	if size := a.localSizes[funcName]; size > 0 {
+		a.adjustSP("add", size)
 	}
-
-	// restore stack (undo register-save area reservation)
+	a.addText("\tldp\tX29, X30, [SP], #16")
+	a.addText(fmt.Sprintf("\t// tail call %s", funcNameStr))
+	a.addText(fmt.Sprintf("\tb\t_%s", funcNameStr))
 }
 
 // emitJmp emits unconditional jump by mapping PB index to label
@@ -1057,8 +1429,8 @@ func (a *arm64Macos) emitJmpCond(instr codegen.Instruction) {
 func (a *arm64Macos) storeCString(lit string) string {
 	// unquote if quoted
 	val := lit
-	if strings.HasPrefix(val, "\"") && strings.HasSuffix(val, "\"") {
-		val = val[1 : len(val)-1]
+	if unquoted, err := strconv.Unquote(val); err == nil {
+		val = unquoted
 	}
 	label := fmt.Sprintf("__dolme_str_%d", a.strCounter)
 	a.strCounter++
@@ -1093,6 +1465,16 @@ func (a *arm64Macos) ensurePrintfIntFormat() string {
 	return label
 }
 
+// ensureBoundsFormat ensures we have the message of a failed bounds check available and returns its label.
+func (a *arm64Macos) ensureBoundsFormat() string {
+	label := "__dolme_bounds"
+	if !strings.Contains(a.cstring.String(), label+":") {
+		a.addCString(fmt.Sprintf("%s:", label))
+		a.addCString("\t.asciz\t\"index out of range [%lld] with length %lld at line %lld\\n\"")
+	}
+	return label
+}
+
 // ensurePrintfFloatFormat ensures we have a "%f\n" C-format string available and returns its label.
 func (a *arm64Macos) ensurePrintfFloatFormat() string {
 	label := "__dolme_printf_float"
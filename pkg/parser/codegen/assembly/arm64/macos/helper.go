@@ -2,6 +2,7 @@ package arm64_macos
 
 import (
 	"dolme/pkg/parser/codegen"
+	"fmt"
 	"strings"
 )
 
@@ -25,6 +26,24 @@ func (a *arm64Macos) findFunctionEnd(labelIdx int) int {
 	return -1
 }
 
+// movImm loads an unsigned constant into reg 16 bits at a time
+func (a *arm64Macos) movImm(reg string, val int) {
+	a.addText(fmt.Sprintf("\tmov\t%s, #%d", reg, val&0xffff))
+	for shift := 16; shift < 64 && val>>shift != 0; shift += 16 {
+		a.addText(fmt.Sprintf("\tmovk\t%s, #%d, lsl #%d", reg, (val>>shift)&0xffff, shift))
+	}
+}
+
+// adjustSP emits `op SP, SP, size` for op sub or add, through X16 for sizes beyond 12 bits
+func (a *arm64Macos) adjustSP(op string, size int) {
+	if size < 4096 {
+		a.addText(fmt.Sprintf("\t%s\tSP, SP, #%d", op, size))
+		return
+	}
+	a.movImm("X16", size)
+	a.addText(fmt.Sprintf("\t%s\tSP, SP, X16", op))
+}
+
 // normalizeImmediate normalizes boolean literals to 1 and 0
 func normalizeImmediate(val string) string {
 	if val == "true" {
@@ -36,9 +55,24 @@ func normalizeImmediate(val string) string {
 	return val
 }
 
-// escapeString escapes special characters in a string
+// escapeString escapes a string for an .asciz directive: quotes and
+// backslashes are escaped and other non-printable bytes written in octal
 func escapeString(s string) string {
-	s = strings.ReplaceAll(s, "\\", "\\\\")
-	s = strings.ReplaceAll(s, "\"", "\\\"")
-	return s
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch ch := s[i]; {
+		case ch == '\\' || ch == '"':
+			b.WriteByte('\\')
+			b.WriteByte(ch)
+		case ch == '\n':
+			b.WriteString("\\n")
+		case ch == '\t':
+			b.WriteString("\\t")
+		case ch < ' ' || ch >= 0x7f:
+			fmt.Fprintf(&b, "\\%03o", ch)
+		default:
+			b.WriteByte(ch)
+		}
+	}
+	return b.String()
 }
@@ -3,9 +3,17 @@ package codegen
 import (
 	"dolme/pkg/lexer"
 	"dolme/pkg/parser/stack"
+	"fmt"
 	"strconv"
 )
 
+// Address space layout shared by the IR consumers
+const (
+	GlobalAddrBase = 400 // global variables
+	TempAddrBase   = 600 // temporaries
+	LocalAddrBase  = 800 // function locals and parameters
+)
+
 type Codegen struct {
 	ss              *stack.Stack               // Semantic stack
 	i               int                        // Instruction counter
@@ -17,11 +25,26 @@ type Codegen struct {
 	argsCounter     int                        // Function arguments counter
 	currentToken    lexer.Token                // Current token being processed
 	inFunction      bool                       // Flag indicating if inside a function
+	function        string                     // Name of the function being generated
 	symbolTable     map[string]int             // Symbol table mapping variable names to addresses
 	functionScope   map[string]int             // Function scope symbol table
 	typeTable       map[int]lexer.TokenType    // Type table mapping addresses to types
 	functionReturns map[string]lexer.TokenType // Function return types
+	functionParams  map[string][]string        // Function parameter types as captured by @capture_type
+	arrays          map[int]ArrayType          // Array types of the addresses typed [ in the type table
+	arrayLits       map[int]int                // Temps holding an array literal -> PB index of its alloc
+	lists           map[int]ListType           // List types of the addresses typed list in the type table
+	returnTypes     map[string]fmt.Stringer    // Return types of the functions returning a list or a struct
+	structTypes     map[string]*StructType     // Declared struct types by name
+	structs         map[int]*StructType        // Struct types of the addresses typed struct in the type table
+	structDecl      *StructType                // Struct type whose fields are being declared
+	enumTypes       map[string]*EnumType       // Declared enum types by name
+	enums           map[int]*EnumType          // Enum types of the addresses typed enum in the type table
+	variants        map[string]*EnumType       // Enum of each declared variant by name
+	enumDecl        *EnumType                  // Enum type whose variants are being declared
+	matches         []*match                   // Match statements being generated, innermost last
 	errors          []string                   // List of semantic errors
+	sdt             *SDTTrace                  // Receives every semantic action when tracing
 }
 
 // NewCodegen creates a new Codegen instance
@@ -41,6 +64,16 @@ func NewCodegen() *Codegen {
 		functionScope:   make(map[string]int),
 		typeTable:       make(map[int]lexer.TokenType),
 		functionReturns: make(map[string]lexer.TokenType),
+		functionParams:  make(map[string][]string),
+		arrays:          make(map[int]ArrayType),
+		arrayLits:       make(map[int]int),
+		lists:           make(map[int]ListType),
+		returnTypes:     make(map[string]fmt.Stringer),
+		structTypes:     make(map[string]*StructType),
+		structs:         make(map[int]*StructType),
+		enumTypes:       make(map[string]*EnumType),
+		enums:           make(map[int]*EnumType),
+		variants:        make(map[string]*EnumType),
 	}
 }
 
@@ -127,18 +160,35 @@ func (c *Codegen) topStringMinus(offset int) string {
 
 // getTemp returns a new temporary variable address
 func (c *Codegen) getTemp() int {
-	addr := 600 + c.tempCounter
+	addr := TempAddrBase + c.tempCounter
 	c.tempCounter++
 	return addr
 }
 
+// NewTemp allocates a typed temporary for passes that add code after parsing
+func (c *Codegen) NewTemp(t lexer.TokenType) int {
+	addr := c.getTemp()
+	c.setVariableType(addr, t)
+	return addr
+}
+
+// TempsLeft returns how many temporaries can still be allocated before the temp range runs out
+func (c *Codegen) TempsLeft() int {
+	return LocalAddrBase - TempAddrBase - c.tempCounter
+}
+
+// IsTemp reports whether an address belongs to the temporary range
+func IsTemp(addr int) bool {
+	return addr >= TempAddrBase && addr < LocalAddrBase
+}
+
 // getVariable returns a new variable address based on the current scope
 func (c *Codegen) getVariable() int {
 	if c.inFunction {
 		return c.getLocalVariable()
 	}
 
-	addr := 400 + c.globalCounter
+	addr := GlobalAddrBase + c.globalCounter
 	c.globalCounter++
 
 	return addr
@@ -146,7 +196,7 @@ func (c *Codegen) getVariable() int {
 
 // getLocalVariable returns a new local variable address
 func (c *Codegen) getLocalVariable() int {
-	addr := 800 + c.localCounter
+	addr := LocalAddrBase + c.localCounter
 	c.localCounter++
 
 	return addr
@@ -0,0 +1,198 @@
+package codegen
+
+import (
+	"dolme/pkg/lexer"
+	"strconv"
+)
+
+// EnumType is an enum declared as `enum Color { Red, Green, Blue }`
+type EnumType struct {
+	Name     string
+	Variants []string // in declaration order
+}
+
+// String renders the type by its name
+func (e *EnumType) String() string {
+	return e.Name
+}
+
+// Variant returns the value of the variant called name
+func (e *EnumType) Variant(name string) (int, bool) {
+	for k, v := range e.Variants {
+		if v == name {
+			return k, true
+		}
+	}
+	return 0, false
+}
+
+// match is a match statement whose arms are being generated
+type match struct {
+	value int             // address of the matched value
+	enum  *EnumType       // its enum, nil when it is not one
+	seen  map[string]bool // variants with an arm
+	next  int             // PB index of the jmpf to the next arm, -1 for none
+	cond  int             // address of the condition of that jmpf
+	ends  []int           // PB indices of the jumps past the last arm
+}
+
+// enumDeclAction starts the declaration of an enum type
+func (c *Codegen) enumDeclAction() {
+	name := c.currentToken.Lexeme
+	if _, ok := c.structTypes[name]; ok {
+		c.addTypeRedeclarationError(name, c.currentToken.Pos)
+	} else if _, ok := c.enumTypes[name]; ok {
+		c.addTypeRedeclarationError(name, c.currentToken.Pos)
+	}
+	c.enumDecl = &EnumType{Name: name}
+}
+
+// variantAction adds a variant to the enum being declared
+func (c *Codegen) variantAction() {
+	e := c.enumDecl
+	if e == nil {
+		return
+	}
+
+	name := c.currentToken.Lexeme
+	if _, ok := e.Variant(name); ok {
+		c.addDuplicateVariantError(name, e.Name, c.currentToken.Pos)
+		return
+	}
+	if other, ok := c.variants[name]; ok {
+		c.addVariantClashError(name, e.Name, "variant", other.Name, c.currentToken.Pos)
+		return
+	}
+	if _, ok := c.symbolTable[name]; ok {
+		c.addVariantClashError(name, e.Name, "variable", "", c.currentToken.Pos)
+	} else if _, ok := c.functionReturns[name]; ok {
+		c.addVariantClashError(name, e.Name, "function", "", c.currentToken.Pos)
+	} else if _, ok := c.structTypes[name]; ok {
+		c.addVariantClashError(name, e.Name, "struct", "", c.currentToken.Pos)
+	}
+	e.Variants = append(e.Variants, name)
+}
+
+// enumEndAction makes the declared enum type and its variants usable
+func (c *Codegen) enumEndAction() {
+	if e := c.enumDecl; e != nil {
+		c.enumTypes[e.Name] = e
+		for _, v := range e.Variants {
+			c.variants[v] = e
+		}
+		c.enumDecl = nil
+	}
+}
+
+// checkNotVariant reports a clash when the kind of thing called name is named like a variant
+func (c *Codegen) checkNotVariant(kind, name string) {
+	if e, ok := c.variants[name]; ok {
+		c.addVariantClashError(name, e.Name, kind, "", c.currentToken.Pos)
+	}
+}
+
+// loadVariant generates code for the variant name used as a value and reports whether name is a variant
+func (c *Codegen) loadVariant(name string) bool {
+	e, ok := c.variants[name]
+	if !ok {
+		return false
+	}
+	k, _ := e.Variant(name)
+
+	t := c.getTemp()
+	c.setType(t, e)
+
+	c.pb = append(c.pb, Instruction{Op: OpAssign, Arg1: "#" + strconv.Itoa(k), Arg2: nil, Arg3: t, Type: lexer.ENUM})
+	c.push(t)
+	c.i++
+	return true
+}
+
+// matchStartAction starts a match on the enum on top of the stack
+func (c *Codegen) matchStartAction() {
+	if c.ss.Size() >= 1 {
+		value := c.top()
+		c.pop(1)
+
+		e, ok := c.enums[value]
+		if !ok && c.GetVariableType(value) != lexer.EOF {
+			c.addNotEnumError(c.typeOf(value), c.currentToken.Pos)
+		}
+		c.matches = append(c.matches, &match{value: value, enum: e, seen: make(map[string]bool), next: -1})
+	}
+}
+
+// armAction generates the test of a match arm, with a jmpf patched by @arm_end
+func (c *Codegen) armAction() {
+	if len(c.matches) == 0 {
+		return
+	}
+	m := c.matches[len(c.matches)-1]
+	if m.enum == nil {
+		return
+	}
+
+	name := c.currentToken.Lexeme
+	k, ok := m.enum.Variant(name)
+	switch {
+	case !ok:
+		c.addNoVariantError(name, m.enum, c.currentToken.Pos)
+		return
+	case m.seen[name]:
+		c.addDuplicateArmError(name, c.currentToken.Pos)
+		return
+	}
+	m.seen[name] = true
+
+	t := c.getTemp()
+	c.setVariableType(t, lexer.BOOL)
+	c.pb = append(c.pb, Instruction{Op: OpEq, Arg1: m.value, Arg2: "#" + strconv.Itoa(k), Arg3: t, Type: lexer.INT})
+	c.i++
+
+	m.next, m.cond = c.i, t
+	c.pb = append(c.pb, Instruction{OpNop, nil, nil, nil, lexer.EOF})
+	c.i++
+}
+
+// armEndAction ends the body of a match arm with a jump patched by @match_end
+func (c *Codegen) armEndAction() {
+	if len(c.matches) == 0 {
+		return
+	}
+	m := c.matches[len(c.matches)-1]
+
+	m.ends = append(m.ends, c.i)
+	c.pb = append(c.pb, Instruction{OpNop, nil, nil, nil, lexer.EOF})
+	c.i++
+
+	if m.next >= 0 {
+		c.pb[m.next] = Instruction{Op: OpJmpf, Arg1: m.cond, Arg2: nil, Arg3: c.i, Type: lexer.EOF}
+		m.next = -1
+	}
+}
+
+// matchEndAction patches the jumps past the last arm and reports the variants no arm matches
+func (c *Codegen) matchEndAction() {
+	if len(c.matches) == 0 {
+		return
+	}
+	m := c.matches[len(c.matches)-1]
+	c.matches = c.matches[:len(c.matches)-1]
+
+	for _, idx := range m.ends {
+		c.pb[idx] = Instruction{Op: OpJmp, Arg1: nil, Arg2: nil, Arg3: c.i, Type: lexer.EOF}
+	}
+
+	if m.enum == nil {
+		return
+	}
+	missing := make([]string, 0)
+	for _, v := range m.enum.Variants {
+		if !m.seen[v] {
+			missing = append(missing, v)
+		}
+	}
+	if len(missing) > 0 {
+		c.addNonExhaustiveMatchError(m.enum, missing, c.currentToken.Pos)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"dolme/pkg/color"
 	"dolme/pkg/lexer"
 	"fmt"
+	"strings"
 )
 
 func (c *Codegen) addError(e string) {
@@ -16,7 +17,7 @@ func (c *Codegen) addUndefinedVariableError(varName string, pos lexer.Position)
 	c.addError(msg)
 }
 
-func (c *Codegen) addTypeMismatchError(expected, found lexer.TokenType, pos lexer.Position) {
+func (c *Codegen) addTypeMismatchError(expected, found fmt.Stringer, pos lexer.Position) {
 	msg := color.RedText("Type mismatch") + " expected " + color.BlueText(fmt.Sprintf("%v", expected)) + ", found " + color.BlueText(fmt.Sprintf("%v", found))
 	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
 	c.addError(msg)
@@ -34,6 +35,118 @@ func (c *Codegen) addRedeclarationError(varName string, pos lexer.Position) {
 	c.addError(msg)
 }
 
+func (c *Codegen) addInvalidArrayLengthError(length string, pos lexer.Position) {
+	msg := color.RedText("Invalid array length") + " `" + color.BlueText(length) + "`"
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addNotIndexableError(found fmt.Stringer, pos lexer.Position) {
+	msg := color.RedText("Cannot index") + " a value of type " + color.BlueText(fmt.Sprintf("%v", found))
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addNotListError(found fmt.Stringer, pos lexer.Position) {
+	msg := color.RedText("Cannot append") + " to a value of type " + color.BlueText(fmt.Sprintf("%v", found))
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addStringOperatorError(op Operation, allowed string, pos lexer.Position) {
+	msg := color.RedText("Cannot apply") + " `" + color.BlueText(string(op)) + "` to strings, which only support " + allowed
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addArrayReturnError(funcName string, pos lexer.Position) {
+	msg := color.RedText("Arrays cannot be returned") + " from `" + color.BlueText(funcName) + "`"
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addUnknownTypeError(typeName string, pos lexer.Position) {
+	msg := color.RedText("Unknown type") + " `" + color.BlueText(typeName) + "`"
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addTypeRedeclarationError(typeName string, pos lexer.Position) {
+	msg := color.RedText("Redeclaration of type") + " `" + color.BlueText(typeName) + "`"
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addDuplicateFieldError(field, typeName string, pos lexer.Position) {
+	msg := color.RedText("Duplicate field") + " `" + color.BlueText(field) + "` in `" + color.BlueText(typeName) + "`"
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addArrayFieldError(field, typeName string, pos lexer.Position) {
+	msg := color.RedText("Arrays cannot be fields") + ", `" + color.BlueText(field) + "` of `" + color.BlueText(typeName) + "`"
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addNoFieldError(field string, found fmt.Stringer, pos lexer.Position) {
+	msg := color.RedText("No field") + " `" + color.BlueText(field) + "` in a value of type " + color.BlueText(fmt.Sprintf("%v", found))
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addNoMethodError(method string, found fmt.Stringer, pos lexer.Position) {
+	msg := color.RedText("No method") + " `" + color.BlueText(method) + "` on type " + color.BlueText(fmt.Sprintf("%v", found))
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addReceiverTypeError(method string, found fmt.Stringer, pos lexer.Position) {
+	msg := color.RedText("Methods need a struct receiver") + ", `" + color.BlueText(method) + "` has one of type " + color.BlueText(fmt.Sprintf("%v", found))
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addDuplicateVariantError(variant, typeName string, pos lexer.Position) {
+	msg := color.RedText("Redeclaration of variant") + " `" + color.BlueText(variant) + "` of `" + color.BlueText(typeName) + "`"
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addVariantClashError(variant, typeName, kind, otherType string, pos lexer.Position) {
+	msg := color.RedText("Name clash") + " between variant `" + color.BlueText(variant) + "` of `" + color.BlueText(typeName) + "`"
+	msg += " and " + kind + " `" + color.BlueText(variant) + "`"
+	if otherType != "" {
+		msg += " of `" + color.BlueText(otherType) + "`"
+	}
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addNotEnumError(found fmt.Stringer, pos lexer.Position) {
+	msg := color.RedText("Cannot match") + " on a value of type " + color.BlueText(fmt.Sprintf("%v", found))
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addNoVariantError(variant string, found fmt.Stringer, pos lexer.Position) {
+	msg := color.RedText("No variant") + " `" + color.BlueText(variant) + "` in " + color.BlueText(fmt.Sprintf("%v", found))
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addDuplicateArmError(variant string, pos lexer.Position) {
+	msg := color.RedText("Duplicate arm") + " `" + color.BlueText(variant) + "`"
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
+func (c *Codegen) addNonExhaustiveMatchError(found fmt.Stringer, missing []string, pos lexer.Position) {
+	msg := color.RedText("Non-exhaustive match") + " on " + color.BlueText(fmt.Sprintf("%v", found)) + ", missing " + color.BlueText(strings.Join(missing, ", "))
+	msg += " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
+	c.addError(msg)
+}
+
 func (c *Codegen) GetErrors() []string {
 	return c.errors
 }
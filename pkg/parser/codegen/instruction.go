@@ -15,9 +15,15 @@ const (
 	OpMul    Operation = "*"
 	OpDiv    Operation = "/"
 	OpMod    Operation = "%"
+	OpShl    Operation = "<<"  // shift left
+	OpShr    Operation = ">>"  // arithmetic shift right
+	OpUShr   Operation = ">>>" // logical shift right
 	OpAnd    Operation = "&&"
 	OpOr     Operation = "||"
 	OpNot    Operation = "!"
+	OpNeg    Operation = "neg"    // arithmetic negation of Arg1
+	OpConcat Operation = "concat" // Arg1 followed by Arg2, both strings
+	OpLen    Operation = "len"    // length of the string Arg1 in bytes
 	OpEq     Operation = "=="
 	OpNeq    Operation = "!="
 	OpLt     Operation = "<"
@@ -35,6 +41,32 @@ const (
 	OpPrint  Operation = "print"
 	OpNop    Operation = "nop"
 	OpEnd    Operation = "end"
+
+	// Arrays: Arg1 of an alloc is the length, Type of alloc, ldx and stx the
+	// element type. A bounds check precedes every ldx and stx.
+	OpAlloc      Operation = "alloc"  // new array of Arg1 zeroed elements
+	OpIndexLoad  Operation = "ldx"    // element Arg2 of the array Arg1
+	OpIndexStore Operation = "stx"    // stores Arg1 as element Arg2 of the array Arg3
+	OpBounds     Operation = "bounds" // fails unless 0 <= Arg1 < Arg2; Arg3 is the source line
+
+	// Lists: Type of list, append and elems is the element type. An element
+	// is read and written by ldx and stx on the elems of the list, taken
+	// right before, since an append may move them.
+	OpList    Operation = "list"   // new empty list
+	OpAppend  Operation = "append" // appends Arg1 to the list Arg3
+	OpListLen Operation = "llen"   // number of elements of the list Arg1
+	OpElems   Operation = "elems"  // elements of the list Arg1, for ldx and stx
+
+	// Structs: Arg1 of a struct is its size in bytes, Arg2 of ldf and stf the
+	// offset of the field in bytes and their Type the field type. A literal
+	// stores every field right after the struct is made.
+	OpStruct     Operation = "struct" // new struct of Arg1 bytes
+	OpFieldLoad  Operation = "ldf"    // field at offset Arg2 of the struct Arg1
+	OpFieldStore Operation = "stf"    // stores Arg1 as the field at offset Arg2 of the struct Arg3
+
+	// OpTailCall calls Arg1 with Arg2 arguments in place of the current
+	// function, which never resumes; the callee returns straight to its caller
+	OpTailCall Operation = "tailcall"
 )
 
 type Instruction struct {
@@ -67,6 +99,22 @@ func (i Instruction) String() string {
 	return fmt.Sprintf("(%s, %v, %v, %v, %v)", i.Op, arg1, arg2, arg3, i.Type)
 }
 
+// ResultType returns the type of the value an instruction writes
+func (i Instruction) ResultType() lexer.TokenType {
+	switch i.Op {
+	case OpEq, OpNeq, OpLt, OpLe, OpGt, OpGe, OpAnd, OpOr, OpNot:
+		return lexer.BOOL
+	case OpAlloc, OpElems:
+		return lexer.LSBRACE
+	case OpList:
+		return lexer.LIST
+	case OpStruct:
+		return lexer.STRUCT
+	default:
+		return i.Type
+	}
+}
+
 // GetLexOperation maps a lexer token type to an IR operation
 func GetLexOperation(t lexer.TokenType) Operation {
 	switch t {
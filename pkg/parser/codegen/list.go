@@ -0,0 +1,60 @@
+package codegen
+
+import (
+	"dolme/pkg/lexer"
+	"fmt"
+)
+
+// ListType is the type of a growable list such as list[int]
+type ListType struct {
+	Elem lexer.TokenType
+}
+
+// String renders the type the way it is written
+func (l ListType) String() string {
+	return fmt.Sprintf("list[%v]", l.Elem)
+}
+
+// listTypeAction pushes the whole list type for @capture_type and @func_return_type
+func (c *Codegen) listTypeAction() {
+	c.pushString(ListType{Elem: lexer.Keywords[c.currentToken.Lexeme]}.String())
+}
+
+// appendAction generates code to add a value at the end of a list
+func (c *Codegen) appendAction() {
+	if c.ss.Size() >= 2 {
+		value := c.top()
+		list := c.topMinus(1)
+
+		if l, ok := c.lists[list]; !ok {
+			c.addNotListError(c.typeOf(list), c.currentToken.Pos)
+		} else {
+			if !assignable(l.Elem, c.GetVariableType(value)) {
+				c.addTypeMismatchError(l.Elem, c.typeOf(value), c.currentToken.Pos)
+			}
+			c.pb = append(c.pb, Instruction{Op: OpAppend, Arg1: value, Arg2: nil, Arg3: list, Type: l.Elem})
+			c.i++
+		}
+		c.pop(2)
+	}
+}
+
+// listLen generates code for the current number of elements of a list and returns its temp
+func (c *Codegen) listLen(list int) int {
+	t := c.getTemp()
+	c.setVariableType(t, lexer.INT)
+
+	c.pb = append(c.pb, Instruction{Op: OpListLen, Arg1: list, Arg2: nil, Arg3: t, Type: lexer.INT})
+	c.i++
+	return t
+}
+
+// listElems generates code for the elements of a list, re-read before each access, and returns its temp
+func (c *Codegen) listElems(list int, l ListType) int {
+	t := c.getTemp()
+	c.setVariableType(t, lexer.LSBRACE)
+
+	c.pb = append(c.pb, Instruction{Op: OpElems, Arg1: list, Arg2: nil, Arg3: t, Type: l.Elem})
+	c.i++
+	return t
+}
@@ -0,0 +1,252 @@
+package optimizer
+
+import (
+	"bytes"
+	"dolme/pkg/parser/codegen"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// TopLevel is the call graph node standing for the code outside any function
+const TopLevel = "<main>"
+
+// CallGraph records which functions call which, from OpCall and OpTailCall
+type CallGraph struct {
+	Funcs []string                  // TopLevel followed by the functions in program order
+	Calls map[string]map[string]int // caller -> callee -> number of call sites
+}
+
+// BuildCallGraph collects the call edges between the regions of pb
+func BuildCallGraph(pb []codegen.Instruction) *CallGraph {
+	g := &CallGraph{Calls: make(map[string]map[string]int)}
+
+	for _, r := range Regions(pb) {
+		name := r.Name
+		if r.Label < 0 {
+			name = TopLevel
+		}
+		g.Funcs = append(g.Funcs, name)
+		g.Calls[name] = make(map[string]int)
+
+		for _, idx := range r.Index {
+			if in := pb[idx]; in.Op == codegen.OpCall || in.Op == codegen.OpTailCall {
+				callee, _ := in.Arg1.(string)
+				g.Calls[name][callee]++
+			}
+		}
+	}
+
+	return g
+}
+
+// callees returns the functions name calls in alphabetical order
+func (g *CallGraph) callees(name string) []string {
+	out := make([]string, 0, len(g.Calls[name]))
+	for c := range g.Calls[name] {
+		out = append(out, c)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// Reachable returns the functions the top-level code can end up calling
+func (g *CallGraph) Reachable() map[string]bool {
+	seen := map[string]bool{TopLevel: true}
+	work := []string{TopLevel}
+	for len(work) > 0 {
+		f := work[len(work)-1]
+		work = work[:len(work)-1]
+		for c := range g.Calls[f] {
+			if !seen[c] {
+				seen[c] = true
+				work = append(work, c)
+			}
+		}
+	}
+
+	return seen
+}
+
+// Cycles returns the recursive groups of functions: every strongly connected
+// component with more than one function, or a single function calling itself.
+// Each group is listed in program order, groups by their first function.
+func (g *CallGraph) Cycles() [][]string {
+	order := make(map[string]int)
+	for i, f := range g.Funcs {
+		order[f] = i
+	}
+
+	// Tarjan's algorithm
+	index := make(map[string]int)
+	low := make(map[string]int)
+	onStack := make(map[string]bool)
+	stack := make([]string, 0)
+	cycles := make([][]string, 0)
+
+	var visit func(f string)
+	visit = func(f string) {
+		index[f] = len(index)
+		low[f] = index[f]
+		stack = append(stack, f)
+		onStack[f] = true
+
+		for _, c := range g.callees(f) {
+			if _, known := g.Calls[c]; !known {
+				continue
+			}
+			if _, seen := index[c]; !seen {
+				visit(c)
+				low[f] = min(low[f], low[c])
+			} else if onStack[c] {
+				low[f] = min(low[f], index[c])
+			}
+		}
+
+		if low[f] != index[f] {
+			return
+		}
+		scc := make([]string, 0)
+		for {
+			top := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			onStack[top] = false
+			scc = append(scc, top)
+			if top == f {
+				break
+			}
+		}
+		if len(scc) > 1 || g.Calls[f][f] > 0 {
+			sort.Slice(scc, func(i, j int) bool { return order[scc[i]] < order[scc[j]] })
+			cycles = append(cycles, scc)
+		}
+	}
+
+	for _, f := range g.Funcs {
+		if _, seen := index[f]; !seen {
+			visit(f)
+		}
+	}
+	sort.Slice(cycles, func(i, j int) bool { return order[cycles[i][0]] < order[cycles[j][0]] })
+
+	return cycles
+}
+
+// DOT renders the call graph for Graphviz. Edges are labelled with their
+// number of call sites when above one, edges inside a recursive group are
+// red and functions the top level never reaches are grey and dashed.
+func (g *CallGraph) DOT() string {
+	reachable := g.Reachable()
+	group := make(map[string]int)
+	for i, c := range g.Cycles() {
+		for _, f := range c {
+			group[f] = i + 1
+		}
+	}
+
+	var sb strings.Builder
+	sb.WriteString("digraph callgraph {\n")
+	sb.WriteString("    node [shape=box];\n")
+	for _, f := range g.Funcs {
+		attrs := ""
+		switch {
+		case f == TopLevel:
+			attrs = " [shape=ellipse]"
+		case !reachable[f]:
+			attrs = " [style=dashed, color=grey, fontcolor=grey]"
+		}
+		fmt.Fprintf(&sb, "    %q%s;\n", f, attrs)
+	}
+	for _, f := range g.Funcs {
+		for _, c := range g.callees(f) {
+			attrs := make([]string, 0, 2)
+			if n := g.Calls[f][c]; n > 1 {
+				attrs = append(attrs, fmt.Sprintf("label=\"%d\"", n))
+			}
+			if group[f] > 0 && group[f] == group[c] {
+				attrs = append(attrs, "color=red")
+			}
+			if len(attrs) > 0 {
+				fmt.Fprintf(&sb, "    %q -> %q [%s];\n", f, c, strings.Join(attrs, ", "))
+			} else {
+				fmt.Fprintf(&sb, "    %q -> %q;\n", f, c)
+			}
+		}
+	}
+	sb.WriteString("}\n")
+
+	return sb.String()
+}
+
+// callEdge is one caller -> callee pair in the JSON form of a call graph
+type callEdge struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Sites int    `json:"sites"`
+}
+
+// JSON renders the call graph with its recursive groups and unreachable functions
+func (g *CallGraph) JSON() ([]byte, error) {
+	reachable := g.Reachable()
+
+	doc := struct {
+		Functions   []string   `json:"functions"`
+		Calls       []callEdge `json:"calls"`
+		Cycles      [][]string `json:"cycles"`
+		Unreachable []string   `json:"unreachable"`
+	}{
+		Functions:   g.Funcs,
+		Calls:       make([]callEdge, 0),
+		Cycles:      g.Cycles(),
+		Unreachable: make([]string, 0),
+	}
+
+	for _, f := range g.Funcs {
+		for _, c := range g.callees(f) {
+			doc.Calls = append(doc.Calls, callEdge{From: f, To: c, Sites: g.Calls[f][c]})
+		}
+		if !reachable[f] {
+			doc.Unreachable = append(doc.Unreachable, f)
+		}
+	}
+
+	// keep TopLevel readable instead of escaping its angle brackets
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(buf.Bytes(), "\n"), nil
+}
+
+// EliminateDeadFunctions removes the bodies of functions that the top-level
+// code can never call, directly or through other functions
+func EliminateDeadFunctions(pb []codegen.Instruction) []codegen.Instruction {
+	reachable := BuildCallGraph(pb).Reachable()
+
+	dead := make(map[int]bool)
+	for _, r := range Regions(pb)[1:] {
+		if reachable[r.Name] {
+			continue
+		}
+		dead[r.Label] = true
+		for _, idx := range r.Index {
+			dead[idx] = true
+		}
+		// the OpEnd closing the body
+		if end := r.Label + len(r.Index) + 1; end < len(pb) && pb[end].Op == codegen.OpEnd {
+			dead[end] = true
+		}
+	}
+
+	if len(dead) == 0 {
+		return pb
+	}
+
+	return compact(pb, func(idx int) bool { return !dead[idx] })
+}
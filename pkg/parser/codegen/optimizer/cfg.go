@@ -0,0 +1,145 @@
+package optimizer
+
+import (
+	"dolme/pkg/parser/codegen"
+	"sort"
+)
+
+// Block is a basic block: a maximal run of region positions entered only at
+// its first instruction and left only after its last one
+type Block struct {
+	ID    int   // index in CFG.Blocks
+	Start int   // first position in Region.Index
+	End   int   // one past the last position in Region.Index
+	Succs []int // successor block IDs
+	Preds []int // predecessor block IDs
+}
+
+// CFG is the control-flow graph of a single region
+type CFG struct {
+	Region Region
+	Blocks []*Block
+	block  []int // region position -> block ID
+}
+
+// BuildCFG splits a region into basic blocks and links them by jumps and fall-through
+func BuildCFG(pb []codegen.Instruction, r Region) *CFG {
+	g := &CFG{Region: r, block: make([]int, len(r.Index))}
+	if len(r.Index) == 0 {
+		return g
+	}
+
+	// find leaders: region entry, jump targets and instructions following a jump, return or tail call
+	leaders := map[int]bool{0: true}
+	for pos, idx := range r.Index {
+		in := pb[idx]
+		if isJump(in.Op) {
+			if t := g.Position(in.Arg3); t >= 0 {
+				leaders[t] = true
+			}
+		}
+		if (isJump(in.Op) || leavesFrame(in.Op)) && pos+1 < len(r.Index) {
+			leaders[pos+1] = true
+		}
+	}
+
+	starts := make([]int, 0, len(leaders))
+	for pos := range leaders {
+		starts = append(starts, pos)
+	}
+	sort.Ints(starts)
+
+	for id, start := range starts {
+		end := len(r.Index)
+		if id+1 < len(starts) {
+			end = starts[id+1]
+		}
+		g.Blocks = append(g.Blocks, &Block{ID: id, Start: start, End: end})
+		for pos := start; pos < end; pos++ {
+			g.block[pos] = id
+		}
+	}
+
+	// link successors
+	for _, b := range g.Blocks {
+		last := pb[r.Index[b.End-1]]
+		falls := b.End < len(r.Index)
+
+		switch last.Op {
+		case codegen.OpJmp:
+			falls = false
+			if t := g.Position(last.Arg3); t >= 0 {
+				g.link(b.ID, g.block[t])
+			}
+		case codegen.OpJmpf, codegen.OpJmpt:
+			if t := g.Position(last.Arg3); t >= 0 {
+				g.link(b.ID, g.block[t])
+			}
+		case codegen.OpRet, codegen.OpTailCall:
+			falls = false
+		}
+
+		if falls {
+			g.link(b.ID, b.ID+1)
+		}
+	}
+
+	return g
+}
+
+// link adds an edge between two blocks, ignoring duplicates
+func (g *CFG) link(from, to int) {
+	for _, s := range g.Blocks[from].Succs {
+		if s == to {
+			return
+		}
+	}
+
+	g.Blocks[from].Succs = append(g.Blocks[from].Succs, to)
+	g.Blocks[to].Preds = append(g.Blocks[to].Preds, from)
+}
+
+// Position maps a jump target (PB index) to the region position control lands on.
+// Targets that skip past a function body land on the next instruction of the region.
+// It returns -1 when the target leaves the region.
+func (g *CFG) Position(target any) int {
+	t, ok := target.(int)
+	if !ok {
+		return -1
+	}
+
+	pos := sort.SearchInts(g.Region.Index, t)
+	if pos >= len(g.Region.Index) {
+		return -1
+	}
+
+	return pos
+}
+
+// BlockOf returns the block containing a region position
+func (g *CFG) BlockOf(pos int) *Block {
+	return g.Blocks[g.block[pos]]
+}
+
+// Reachable returns the set of block IDs reachable from the region entry
+func (g *CFG) Reachable() map[int]bool {
+	seen := make(map[int]bool)
+	if len(g.Blocks) == 0 {
+		return seen
+	}
+
+	work := []int{0}
+	seen[0] = true
+	for len(work) > 0 {
+		b := work[len(work)-1]
+		work = work[:len(work)-1]
+		for _, s := range g.Blocks[b].Succs {
+			if !seen[s] {
+				seen[s] = true
+				work = append(work, s)
+			}
+		}
+	}
+
+	return seen
+}
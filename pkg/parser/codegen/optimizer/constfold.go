@@ -0,0 +1,289 @@
+package optimizer
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser/codegen"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// constant is an immediate operand decoded the way the interpreter reads it
+type constant struct {
+	kind lexer.TokenType // INT, FLOAT or BOOL
+	i    int64
+	f    float64
+	b    bool
+}
+
+// parseConstant decodes an immediate such as "#3", "#2.5" or "#true"
+func parseConstant(arg any) (constant, bool) {
+	s, ok := arg.(string)
+	if !ok || !strings.HasPrefix(s, "#") {
+		return constant{}, false
+	}
+
+	body := s[1:]
+	switch body {
+	case "true":
+		return constant{kind: lexer.BOOL, b: true}, true
+	case "false":
+		return constant{kind: lexer.BOOL, b: false}, true
+	}
+	if i, err := strconv.ParseInt(body, 10, 64); err == nil {
+		return constant{kind: lexer.INT, i: i}, true
+	}
+	if f, err := strconv.ParseFloat(body, 64); err == nil {
+		return constant{kind: lexer.FLOAT, f: f}, true
+	}
+
+	return constant{}, false
+}
+
+// float returns the value of a numeric constant as a float64
+func (c constant) float() float64 {
+	if c.kind == lexer.INT {
+		return float64(c.i)
+	}
+	return c.f
+}
+
+// immediate encodes a constant back into an immediate operand
+func (c constant) immediate() (string, bool) {
+	switch c.kind {
+	case lexer.BOOL:
+		return "#" + strconv.FormatBool(c.b), true
+	case lexer.INT:
+		return "#" + strconv.FormatInt(c.i, 10), true
+	case lexer.FLOAT:
+		if math.IsInf(c.f, 0) || math.IsNaN(c.f) {
+			return "", false
+		}
+		// keep a decimal point so the value is read back as a float
+		s := strconv.FormatFloat(c.f, 'f', -1, 64)
+		if !strings.Contains(s, ".") {
+			s += ".0"
+		}
+		return "#" + s, true
+	default:
+		return "", false
+	}
+}
+
+// evalConstant computes an operator over constants with the interpreter's
+// rules: arithmetic and comparisons go through float64 when the instruction
+// is typed float or either operand is a float. It fails wherever the
+// interpreter would report an error.
+func evalConstant(op codegen.Operation, typ lexer.TokenType, a, b constant) (constant, bool) {
+	numeric := a.kind != lexer.BOOL && b.kind != lexer.BOOL
+	useFloat := typ == lexer.FLOAT || a.kind == lexer.FLOAT || b.kind == lexer.FLOAT
+
+	switch op {
+	case codegen.OpAdd, codegen.OpSub, codegen.OpMul, codegen.OpDiv, codegen.OpMod:
+		if !numeric {
+			return constant{}, false
+		}
+		if useFloat {
+			x, y := a.float(), b.float()
+			r := constant{kind: lexer.FLOAT}
+			switch op {
+			case codegen.OpAdd:
+				r.f = x + y
+			case codegen.OpSub:
+				r.f = x - y
+			case codegen.OpMul:
+				r.f = x * y
+			case codegen.OpDiv:
+				r.f = x / y
+			case codegen.OpMod:
+				r.f = math.Mod(x, y)
+			}
+			return r, true
+		}
+
+		x, y := a.i, b.i
+		r := constant{kind: lexer.INT}
+		switch op {
+		case codegen.OpAdd:
+			r.i = x + y
+		case codegen.OpSub:
+			r.i = x - y
+		case codegen.OpMul:
+			r.i = x * y
+		case codegen.OpDiv, codegen.OpMod:
+			if y == 0 {
+				return constant{}, false
+			}
+			if op == codegen.OpDiv {
+				r.i = x / y
+			} else {
+				r.i = x % y
+			}
+		}
+		return r, true
+
+	case codegen.OpShl, codegen.OpShr, codegen.OpUShr:
+		if a.kind != lexer.INT || b.kind != lexer.INT || b.i < 0 || b.i > 63 {
+			return constant{}, false
+		}
+		r := constant{kind: lexer.INT}
+		switch op {
+		case codegen.OpShl:
+			r.i = a.i << b.i
+		case codegen.OpShr:
+			r.i = a.i >> b.i
+		case codegen.OpUShr:
+			r.i = int64(uint64(a.i) >> b.i)
+		}
+		return r, true
+
+	case codegen.OpEq, codegen.OpNeq, codegen.OpLt, codegen.OpLe, codegen.OpGt, codegen.OpGe:
+		if !numeric {
+			return constant{}, false
+		}
+		var cmp int
+		if useFloat {
+			x, y := a.float(), b.float()
+			if math.IsNaN(x) || math.IsNaN(y) {
+				return constant{}, false
+			}
+			switch {
+			case x < y:
+				cmp = -1
+			case x > y:
+				cmp = 1
+			}
+		} else {
+			switch {
+			case a.i < b.i:
+				cmp = -1
+			case a.i > b.i:
+				cmp = 1
+			}
+		}
+
+		r := constant{kind: lexer.BOOL}
+		switch op {
+		case codegen.OpEq:
+			r.b = cmp == 0
+		case codegen.OpNeq:
+			r.b = cmp != 0
+		case codegen.OpLt:
+			r.b = cmp < 0
+		case codegen.OpLe:
+			r.b = cmp <= 0
+		case codegen.OpGt:
+			r.b = cmp > 0
+		case codegen.OpGe:
+			r.b = cmp >= 0
+		}
+		return r, true
+
+	case codegen.OpAnd, codegen.OpOr:
+		if a.kind != lexer.BOOL || b.kind != lexer.BOOL {
+			return constant{}, false
+		}
+		if op == codegen.OpAnd {
+			return constant{kind: lexer.BOOL, b: a.b && b.b}, true
+		}
+		return constant{kind: lexer.BOOL, b: a.b || b.b}, true
+	}
+
+	return constant{}, false
+}
+
+// knownConstants tracks which addresses hold a known constant inside a basic block
+type knownConstants map[int]constant
+
+// value returns the constant an operand is known to hold
+func (k knownConstants) value(arg any) (constant, bool) {
+	if addr, ok := arg.(int); ok {
+		c, ok := k[addr]
+		return c, ok
+	}
+	return parseConstant(arg)
+}
+
+// update records the effect of an instruction on the known constants
+func (k knownConstants) update(in codegen.Instruction) {
+	d, writes := def(in)
+	if !writes {
+		return
+	}
+
+	c, isConst := k.value(in.Arg1)
+	delete(k, d)
+	if in.Op == codegen.OpAssign && isConst {
+		k[d] = c
+	}
+
+	// the callee may have written any global
+	if in.Op == codegen.OpCall {
+		for addr := range k {
+			if addr >= codegen.GlobalAddrBase && addr < codegen.TempAddrBase {
+				delete(k, addr)
+			}
+		}
+	}
+}
+
+// FoldConstants evaluates operators whose operands are known constants at
+// compile time. Values are tracked per basic block from immediate loads;
+// an instruction whose operands are all known becomes a load of its result.
+// Conditional jumps on a known condition turn into a plain jump or vanish.
+// Loads left without readers are for DCE to remove.
+func FoldConstants(pb []codegen.Instruction) []codegen.Instruction {
+	out := append([]codegen.Instruction(nil), pb...)
+	removed := make(map[int]bool)
+
+	for _, r := range Regions(out) {
+		g := BuildCFG(out, r)
+		for _, b := range g.Blocks {
+			known := knownConstants{}
+			value := known.value
+
+			for pos := b.Start; pos < b.End; pos++ {
+				idx := r.Index[pos]
+				in := out[idx]
+
+				switch {
+				case in.Op == codegen.OpJmpf || in.Op == codegen.OpJmpt:
+					if c, ok := value(in.Arg1); ok && c.kind == lexer.BOOL {
+						if c.b == (in.Op == codegen.OpJmpt) {
+							out[idx] = codegen.Instruction{Op: codegen.OpJmp, Arg3: in.Arg3}
+						} else {
+							removed[idx] = true
+						}
+					}
+					continue
+				case isBinary(in.Op):
+					x, okx := value(in.Arg1)
+					y, oky := value(in.Arg2)
+					if okx && oky {
+						// only fold when the backends see the same kind the interpreter computes
+						if c, ok := evalConstant(in.Op, in.Type, x, y); ok && c.kind == in.ResultType() {
+							if imm, ok := c.immediate(); ok {
+								out[idx] = codegen.Instruction{Op: codegen.OpAssign, Arg1: imm, Arg3: in.Arg3, Type: in.ResultType()}
+							}
+						}
+					}
+				case in.Op == codegen.OpNot:
+					if c, ok := value(in.Arg1); ok && c.kind == lexer.BOOL {
+						out[idx] = codegen.Instruction{Op: codegen.OpAssign, Arg1: "#" + strconv.FormatBool(!c.b), Arg3: in.Arg3, Type: lexer.BOOL}
+					}
+				case in.Op == codegen.OpNeg:
+					if c, ok := value(in.Arg1); ok && c.kind == in.Type {
+						c.i, c.f = -c.i, -c.f
+						if imm, ok := c.immediate(); ok {
+							out[idx] = codegen.Instruction{Op: codegen.OpAssign, Arg1: imm, Arg3: in.Arg3, Type: in.Type}
+						}
+					}
+				}
+
+				known.update(out[idx])
+			}
+		}
+	}
+
+	return compact(out, func(idx int) bool { return !removed[idx] })
+}
@@ -0,0 +1,211 @@
+package optimizer
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser/codegen"
+	"fmt"
+)
+
+// holder remembers which address holds an already computed value
+type holder struct {
+	addr int // address the value was written to
+	vn   int // value number the address had right after the write
+}
+
+// numbering is the local value-numbering state of one basic block
+type numbering struct {
+	next   int            // next fresh value number
+	vn     map[int]int    // address -> value number of its current contents
+	consts map[string]int // immediate|type -> value number
+	exprs  map[string]holder
+	copies map[int]holder          // temp -> address it was copied from
+	types  map[int]lexer.TokenType // address -> type it was last written with in the block
+}
+
+// newNumbering returns empty value-numbering state for a block
+func newNumbering() *numbering {
+	return &numbering{
+		vn:     make(map[int]int),
+		consts: make(map[string]int),
+		exprs:  make(map[string]holder),
+		copies: make(map[int]holder),
+		types:  make(map[int]lexer.TokenType),
+	}
+}
+
+// fresh hands out a new value number
+func (n *numbering) fresh() int {
+	n.next++
+	return n.next
+}
+
+// valueOf returns the value number of an operand, numbering unseen operands on first use
+func (n *numbering) valueOf(arg any, typ any) int {
+	switch v := arg.(type) {
+	case int:
+		if _, ok := n.vn[v]; !ok {
+			n.vn[v] = n.fresh()
+		}
+		return n.vn[v]
+	default:
+		key := fmt.Sprintf("%v|%v", v, typ)
+		if _, ok := n.consts[key]; !ok {
+			n.consts[key] = n.fresh()
+		}
+		return n.consts[key]
+	}
+}
+
+// holds reports whether h.addr still contains the value it was recorded with
+func (n *numbering) holds(h holder) bool {
+	return n.vn[h.addr] == h.vn
+}
+
+// isCommutative reports whether the operands of op may be swapped
+func isCommutative(op codegen.Operation) bool {
+	switch op {
+	case codegen.OpAdd, codegen.OpMul, codegen.OpAnd, codegen.OpOr, codegen.OpEq, codegen.OpNeq:
+		return true
+	default:
+		return false
+	}
+}
+
+// EliminateCommonSubexpressions reuses pure computations repeated inside a
+// basic block. It numbers values per block; an instruction whose operator,
+// type and operand values match an earlier one becomes a copy of the earlier
+// result, as long as that result has not been overwritten. Calls end every
+// value that lives in a global, since the callee may assign it.
+func EliminateCommonSubexpressions(pb []codegen.Instruction, cg *codegen.Codegen) []codegen.Instruction {
+	out := append([]codegen.Instruction(nil), pb...)
+	created := make(map[int]bool) // PB indices of copies introduced here
+
+	for _, r := range Regions(out) {
+		g := BuildCFG(out, r)
+		for _, b := range g.Blocks {
+			n := newNumbering()
+
+			for pos := b.Start; pos < b.End; pos++ {
+				idx := r.Index[pos]
+
+				// forward temps that are plain copies of a value still in place
+				out[idx] = rewriteUses(out[idx], func(addr int) int {
+					if h, ok := n.copies[addr]; ok && n.holds(h) && n.vn[addr] == h.vn {
+						return h.addr
+					}
+					return addr
+				})
+				in := out[idx]
+
+				dst, writes := def(in)
+				if !writes {
+					continue
+				}
+
+				key := ""
+				switch {
+				case isBinary(in.Op):
+					x, y := n.valueOf(in.Arg1, in.Type), n.valueOf(in.Arg2, in.Type)
+					if isCommutative(in.Op) && y < x {
+						x, y = y, x
+					}
+					key = fmt.Sprintf("%s|%v|%d|%d", in.Op, in.Type, x, y)
+				case isUnary(in.Op):
+					key = fmt.Sprintf("%s|%v|%d", in.Op, in.Type, n.valueOf(in.Arg1, in.Type))
+				case in.Op == codegen.OpAssign:
+					src, ok := in.Arg1.(int)
+					if !ok {
+						key = fmt.Sprintf("imm|%v|%v", in.Arg1, in.Type)
+						break
+					}
+					// a float copy of a source not known to be a float may widen an int
+					if t, known := n.types[src]; in.Type == lexer.FLOAT && (!known || t != lexer.FLOAT) {
+						key = fmt.Sprintf("widen|%d", n.valueOf(src, in.Type))
+						break
+					}
+					// a copy shares the value number of its source
+					n.vn[dst] = n.valueOf(src, in.Type)
+					n.types[dst] = in.Type
+					if codegen.IsTemp(dst) {
+						n.copies[dst] = holder{addr: src, vn: n.vn[dst]}
+					}
+					continue
+				}
+
+				if h, ok := n.exprs[key]; key != "" && ok && n.holds(h) {
+					out[idx] = codegen.Instruction{Op: codegen.OpAssign, Arg1: h.addr, Arg2: nil, Arg3: dst, Type: in.ResultType()}
+					created[idx] = true
+					n.vn[dst] = h.vn
+					n.types[dst] = in.ResultType()
+					if codegen.IsTemp(dst) {
+						n.copies[dst] = h
+					}
+					continue
+				}
+
+				n.vn[dst] = n.fresh()
+				n.types[dst] = in.ResultType()
+				delete(n.copies, dst)
+				if key != "" {
+					n.exprs[key] = holder{addr: dst, vn: n.vn[dst]}
+				}
+
+				// the callee may have written any global
+				if in.Op == codegen.OpCall {
+					for addr := range n.vn {
+						if addr >= codegen.GlobalAddrBase && addr < codegen.TempAddrBase && addr != dst {
+							n.vn[addr] = n.fresh()
+						}
+					}
+				}
+			}
+		}
+	}
+
+	// drop the copies that ended up unused once their readers were forwarded
+	used := make(map[int]bool)
+	for _, in := range out {
+		for _, u := range uses(in) {
+			used[u] = true
+		}
+	}
+
+	return compact(out, func(idx int) bool {
+		if !created[idx] {
+			return true
+		}
+		dst, _ := def(out[idx])
+		if out[idx].Arg1 == dst {
+			return false // copying a value onto itself
+		}
+		return !codegen.IsTemp(dst) || used[dst]
+	})
+}
+
+// rewriteUses applies f to the addresses an instruction reads
+func rewriteUses(in codegen.Instruction, f func(int) int) codegen.Instruction {
+	read := make(map[int]bool)
+	for _, u := range uses(in) {
+		read[u] = true
+	}
+
+	d, writes := def(in)
+	rewritten := rewriteOperands(in, func(addr int) int {
+		if read[addr] {
+			return f(addr)
+		}
+		return addr
+	})
+
+	// keep the destination as it was, even when it is also read
+	if writes {
+		switch in.Op {
+		case codegen.OpParam:
+			rewritten.Arg1 = d
+		default:
+			rewritten.Arg3 = d
+		}
+	}
+
+	return rewritten
+}
@@ -0,0 +1,51 @@
+package optimizer
+
+import (
+	"dolme/pkg/parser/codegen"
+)
+
+// EliminateDeadCode removes instructions that cannot affect the program:
+// blocks no path reaches, pure computations into temps or locals that are
+// never read afterwards, and jumps to the very next instruction. Globals are
+// always kept since other regions may read them. It repeats until nothing
+// else can go, as removing one reader can leave its operands dead.
+func EliminateDeadCode(pb []codegen.Instruction) []codegen.Instruction {
+	out := pb
+	frameLocal := func(addr int) bool { return addr >= codegen.TempAddrBase }
+
+	for {
+		dead := make(map[int]bool)
+
+		for _, r := range Regions(out) {
+			g := BuildCFG(out, r)
+			reachable := g.Reachable()
+			after := liveness(out, g, frameLocal)
+
+			for _, b := range g.Blocks {
+				for pos := b.Start; pos < b.End; pos++ {
+					idx := r.Index[pos]
+					in := out[idx]
+
+					if !reachable[b.ID] {
+						dead[idx] = true
+						continue
+					}
+
+					if in.Op == codegen.OpJmp && in.Arg3 == idx+1 {
+						dead[idx] = true
+						continue
+					}
+
+					if d, ok := def(in); ok && frameLocal(d) && isPure(in) && !after[pos][d] {
+						dead[idx] = true
+					}
+				}
+			}
+		}
+
+		if len(dead) == 0 {
+			return out
+		}
+		out = compact(out, func(idx int) bool { return !dead[idx] })
+	}
+}
@@ -0,0 +1,219 @@
+package optimizer
+
+import (
+	"dolme/pkg/interpreter"
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser/codegen"
+	"io"
+	"strings"
+)
+
+// DefaultEvalSteps is the interpreter step budget for evaluating one call at compile time
+const DefaultEvalSteps = 100_000
+
+// pureFunctions returns the functions whose result depends only on their
+// arguments: they never print, never read or write a global and only call
+// functions that are pure themselves. Whether they terminate is left to the
+// step budget of the evaluation.
+func pureFunctions(pb []codegen.Instruction) map[string]bool {
+	funcs := make(map[string]*callee)
+	pure := make(map[string]bool)
+
+	for _, r := range Regions(pb)[1:] {
+		funcs[r.Name] = describeCallee(pb, r)
+		pure[r.Name] = true
+
+		global := func(addr int) bool { return addr >= codegen.GlobalAddrBase && addr < codegen.TempAddrBase }
+		for _, idx := range r.Index {
+			in := pb[idx]
+			if in.Op == codegen.OpPrint {
+				pure[r.Name] = false
+			}
+			if d, ok := def(in); ok && global(d) {
+				pure[r.Name] = false
+			}
+			for _, u := range uses(in) {
+				if global(u) {
+					pure[r.Name] = false
+				}
+			}
+		}
+	}
+
+	// a function calling something impure, or something unknown, is impure too
+	for changed := true; changed; {
+		changed = false
+		for name, fn := range funcs {
+			if !pure[name] {
+				continue
+			}
+			for c := range fn.calls {
+				if !pure[c] {
+					pure[name] = false
+					changed = true
+					break
+				}
+			}
+		}
+	}
+
+	return pure
+}
+
+// evaluator runs pure functions on constant arguments
+type evaluator struct {
+	pb    []codegen.Instruction // function bodies with the top-level code blanked out
+	steps int                   // step budget for one call
+	cache map[string]constant   // function and arguments -> result
+	fail  map[string]bool       // calls that did not finish within the budget or failed
+}
+
+// newEvaluator prepares a program holding only the function bodies of pb
+func newEvaluator(pb []codegen.Instruction, steps int) *evaluator {
+	bodies := append([]codegen.Instruction(nil), pb...)
+	for _, idx := range Regions(pb)[0].Index {
+		bodies[idx] = codegen.Instruction{Op: codegen.OpNop}
+	}
+
+	return &evaluator{pb: bodies, steps: steps, cache: make(map[string]constant), fail: make(map[string]bool)}
+}
+
+// call runs name on args and returns its result as a constant
+func (e *evaluator) call(name string, args []string) (constant, bool) {
+	key := name + "(" + strings.Join(args, ",") + ")"
+	if c, ok := e.cache[key]; ok {
+		return c, true
+	}
+	if e.fail[key] {
+		return constant{}, false
+	}
+
+	// the top level is all nops, so the harness appended after it runs the call alone
+	result := codegen.GlobalAddrBase
+	prog := append([]codegen.Instruction(nil), e.pb...)
+	for k, a := range args {
+		prog = append(prog, codegen.Instruction{Op: codegen.OpArg, Arg1: a, Arg2: k})
+	}
+	prog = append(prog,
+		codegen.Instruction{Op: codegen.OpCall, Arg1: name, Arg2: len(args), Arg3: result},
+		codegen.Instruction{Op: codegen.OpRet},
+	)
+
+	it := interpreter.NewInterpreter(prog, interpreter.WithWriter(io.Discard), interpreter.WithMaxSteps(len(e.pb)+e.steps))
+	if err := it.Run(); err != nil {
+		e.fail[key] = true
+		return constant{}, false
+	}
+
+	v, _ := it.GetVar(result)
+	c := constant{}
+	switch v.Kind {
+	case interpreter.KindInt:
+		c = constant{kind: lexer.INT, i: v.I64}
+	case interpreter.KindFloat:
+		c = constant{kind: lexer.FLOAT, f: v.F64}
+	case interpreter.KindBool:
+		c = constant{kind: lexer.BOOL, b: v.Bool}
+	default:
+		e.fail[key] = true
+		return constant{}, false
+	}
+
+	e.cache[key] = c
+	return c, true
+}
+
+// EvaluatePureCalls replaces calls to pure functions whose arguments are all
+// known constants with the value the call returns. Each call is run by the
+// interpreter with a budget of steps instructions; calls that run out of
+// steps or fail at run time are left alone. The call's OpArg instructions
+// go with it, and the loads feeding them are for DCE to remove.
+func EvaluatePureCalls(pb []codegen.Instruction, steps int) []codegen.Instruction {
+	if steps <= 0 {
+		return pb
+	}
+
+	pure := pureFunctions(pb)
+	funcs := make(map[string]*callee)
+	for _, r := range Regions(pb)[1:] {
+		funcs[r.Name] = describeCallee(pb, r)
+	}
+
+	eval := newEvaluator(pb, steps)
+	replaced := make(map[int]codegen.Instruction)
+	removed := make(map[int]bool)
+
+	for _, r := range Regions(pb) {
+		g := BuildCFG(pb, r)
+		for _, b := range g.Blocks {
+			known := knownConstants{}
+			staged := make(map[int]string) // PB index of an OpArg -> immediate it passes
+
+			for pos := b.Start; pos < b.End; pos++ {
+				idx := r.Index[pos]
+				in := pb[idx]
+
+				if in.Op == codegen.OpArg {
+					if c, ok := known.value(in.Arg1); ok {
+						if imm, ok := c.immediate(); ok {
+							staged[idx] = imm
+						}
+					}
+				}
+
+				name, _ := in.Arg1.(string)
+				if in.Op == codegen.OpCall && pure[name] {
+					if seq, args, ok := constantCall(pb, g, pos, funcs[name], staged); ok {
+						if c, ok := eval.call(name, args); ok && c.kind == in.Type {
+							if imm, ok := c.immediate(); ok {
+								replaced[idx] = codegen.Instruction{Op: codegen.OpAssign, Arg1: imm, Arg3: in.Arg3, Type: in.Type}
+								for _, a := range seq {
+									removed[a] = true
+								}
+							}
+						}
+					}
+				}
+
+				if rep, ok := replaced[idx]; ok {
+					known.update(rep)
+				} else {
+					known.update(in)
+				}
+			}
+		}
+	}
+
+	if len(replaced) == 0 {
+		return pb
+	}
+
+	out := append([]codegen.Instruction(nil), pb...)
+	for idx, in := range replaced {
+		out[idx] = in
+	}
+
+	return compact(out, func(idx int) bool { return !removed[idx] })
+}
+
+// constantCall returns the OpArg instructions of the call at region position
+// pos and the immediates they pass, in parameter order, when all are known
+func constantCall(pb []codegen.Instruction, g *CFG, pos int, fn *callee, staged map[int]string) ([]int, []string, bool) {
+	args, ok := callArgs(pb, g, pos, len(fn.params))
+	if !ok {
+		return nil, nil, false
+	}
+
+	seq := make([]int, 0, len(args))
+	imms := make([]string, len(args))
+	for a, k := range args {
+		imm, ok := staged[a]
+		if !ok {
+			return nil, nil, false
+		}
+		imms[k] = imm
+		seq = append(seq, a)
+	}
+
+	return seq, imms, true
+}
@@ -0,0 +1,320 @@
+package optimizer
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser/codegen"
+)
+
+// DefaultInlineThreshold is the largest callee body, in instructions, that gets inlined by default
+const DefaultInlineThreshold = 16
+
+// callee describes a function body that may be copied into its callers
+type callee struct {
+	region Region
+	params map[int]int             // parameter position -> address
+	types  map[int]lexer.TokenType // local address -> type
+	calls  map[string]bool         // functions it calls
+	tail   bool                    // whether it hands its frame over with OpTailCall
+}
+
+// callSite is a call that will be replaced by a copy of the callee body
+type callSite struct {
+	fn      *callee
+	args    map[int]int // PB index of an OpArg -> parameter position
+	renamed map[int]int // callee address -> caller temp
+}
+
+// InlineFunctions copies the bodies of small functions into their call sites.
+// A function is inlined when its body has at most threshold instructions and
+// it is not part of a call cycle. The callee's locals and temps get fresh
+// temps in the caller, each OpArg becomes an assignment to the matching
+// parameter and each OpRet becomes an assignment to the call's return temp
+// followed by a jump past the copied body. The function itself stays in the
+// program, since other callers may still call it.
+func InlineFunctions(pb []codegen.Instruction, cg *codegen.Codegen, threshold int) []codegen.Instruction {
+	if threshold <= 0 {
+		return pb
+	}
+
+	funcs := make(map[string]*callee)
+	for _, r := range Regions(pb)[1:] {
+		funcs[r.Name] = describeCallee(pb, r)
+	}
+
+	inlinable := make(map[string]bool)
+	for name, fn := range funcs {
+		size := 0
+		for _, idx := range fn.region.Index {
+			if pb[idx].Op != codegen.OpParam && pb[idx].Op != codegen.OpNop {
+				size++
+			}
+		}
+		// a copied tail call would replace the caller's frame instead
+		inlinable[name] = size <= threshold && !fn.tail && !onCycle(funcs, name)
+	}
+
+	sites := make(map[int]*callSite) // PB index of the OpCall -> site
+	argOf := make(map[int]int)       // PB index of an OpArg -> PB index of its call
+
+	for _, r := range Regions(pb) {
+		g := BuildCFG(pb, r)
+		for pos, idx := range r.Index {
+			in := pb[idx]
+			name, _ := in.Arg1.(string)
+			if in.Op != codegen.OpCall || !inlinable[name] {
+				continue
+			}
+
+			fn := funcs[name]
+			args, ok := callArgs(pb, g, pos, len(fn.params))
+			if !ok {
+				continue
+			}
+
+			addrs := calleeAddresses(pb, fn)
+			if cg.TempsLeft() < len(addrs) {
+				continue
+			}
+			renamed := make(map[int]int, len(addrs))
+			for _, addr := range addrs {
+				t, ok := fn.types[addr]
+				if !ok {
+					t = cg.GetVariableType(addr)
+				}
+				renamed[addr] = cg.NewTemp(t)
+			}
+
+			sites[idx] = &callSite{fn: fn, args: args, renamed: renamed}
+			for a := range args {
+				argOf[a] = idx
+			}
+		}
+	}
+
+	if len(sites) == 0 {
+		return pb
+	}
+
+	out := make([]codegen.Instruction, 0, len(pb))
+	newIndex := make([]int, len(pb)+1)
+	placed := make(map[int]bool) // output indices whose jump targets are already final
+
+	for idx, in := range pb {
+		newIndex[idx] = len(out)
+
+		if call, ok := argOf[idx]; ok {
+			site := sites[call]
+			param := site.fn.params[site.args[idx]]
+			out = append(out, codegen.Instruction{
+				Op:   codegen.OpAssign,
+				Arg1: in.Arg1,
+				Arg3: site.renamed[param],
+				Type: site.fn.types[param],
+			})
+			continue
+		}
+
+		site, ok := sites[idx]
+		if !ok {
+			out = append(out, in)
+			continue
+		}
+
+		for _, copied := range expand(pb, site, in.Arg3, len(out)) {
+			if isJump(copied.Op) {
+				placed[len(out)] = true
+			}
+			out = append(out, copied)
+		}
+	}
+	newIndex[len(pb)] = len(out)
+
+	for idx, in := range out {
+		if !isJump(in.Op) || placed[idx] {
+			continue
+		}
+		if t, ok := in.Arg3.(int); ok && t >= 0 && t <= len(pb) {
+			out[idx].Arg3 = newIndex[t]
+		}
+	}
+
+	return out
+}
+
+// describeCallee collects the parameters, local types and callees of a function
+func describeCallee(pb []codegen.Instruction, r Region) *callee {
+	fn := &callee{
+		region: r,
+		params: make(map[int]int),
+		types:  make(map[int]lexer.TokenType),
+		calls:  make(map[string]bool),
+	}
+
+	for _, idx := range r.Index {
+		in := pb[idx]
+		switch in.Op {
+		case codegen.OpParam:
+			addr, _ := in.Arg1.(int)
+			pos, _ := in.Arg2.(int)
+			fn.params[pos] = addr
+			fn.types[addr] = in.Type
+		case codegen.OpCall, codegen.OpTailCall:
+			name, _ := in.Arg1.(string)
+			fn.calls[name] = true
+			fn.tail = fn.tail || in.Op == codegen.OpTailCall
+		}
+
+		// locals share addresses across functions, so their types come from the body itself
+		if d, ok := def(in); ok && d >= codegen.LocalAddrBase {
+			if _, known := fn.types[d]; !known {
+				fn.types[d] = in.ResultType()
+			}
+		}
+	}
+
+	return fn
+}
+
+// onCycle reports whether a function can reach itself through calls
+func onCycle(funcs map[string]*callee, name string) bool {
+	seen := make(map[string]bool)
+	work := []string{name}
+	for len(work) > 0 {
+		f := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		fn, ok := funcs[f]
+		if !ok {
+			continue
+		}
+		for c := range fn.calls {
+			if c == name {
+				return true
+			}
+			if !seen[c] {
+				seen[c] = true
+				work = append(work, c)
+			}
+		}
+	}
+
+	return false
+}
+
+// callArgs finds the OpArg instructions feeding the call at region position
+// pos. They must all sit in the call's basic block after any earlier call,
+// one per parameter position.
+func callArgs(pb []codegen.Instruction, g *CFG, pos int, count int) (map[int]int, bool) {
+	args := make(map[int]int)
+	found := make(map[int]bool)
+	start := g.BlockOf(pos).Start
+
+	for p := pos - 1; p >= start && len(found) < count; p-- {
+		idx := g.Region.Index[p]
+		in := pb[idx]
+		if in.Op == codegen.OpCall {
+			break
+		}
+		if in.Op != codegen.OpArg {
+			continue
+		}
+
+		k, _ := in.Arg2.(int)
+		if k < 0 || k >= count || found[k] {
+			return nil, false
+		}
+		found[k] = true
+		args[idx] = k
+	}
+
+	return args, len(found) == count
+}
+
+// calleeAddresses returns every frame-local address a function body touches
+func calleeAddresses(pb []codegen.Instruction, fn *callee) []int {
+	seen := make(map[int]bool)
+	addrs := make([]int, 0)
+	add := func(addr int) {
+		if addr >= codegen.TempAddrBase && !seen[addr] {
+			seen[addr] = true
+			addrs = append(addrs, addr)
+		}
+	}
+
+	for _, idx := range fn.region.Index {
+		in := pb[idx]
+		for _, u := range uses(in) {
+			add(u)
+		}
+		if d, ok := def(in); ok {
+			add(d)
+		}
+	}
+
+	return addrs
+}
+
+// expand returns the callee body renamed for a call site that starts at
+// output index base. Returns turn into assignments to dst and jumps past the body.
+func expand(pb []codegen.Instruction, site *callSite, dst any, base int) []codegen.Instruction {
+	body := make([]codegen.Instruction, 0, len(site.fn.region.Index))
+	offset := make(map[int]int) // callee PB index -> offset in body
+	exits := make([]int, 0)     // offsets of the jumps leaving the body
+
+	rename := func(addr int) int {
+		if t, ok := site.renamed[addr]; ok {
+			return t
+		}
+		return addr
+	}
+
+	index := site.fn.region.Index
+	for n, idx := range index {
+		offset[idx] = len(body)
+		in := pb[idx]
+
+		switch in.Op {
+		case codegen.OpParam, codegen.OpNop:
+			// parameters were assigned from the arguments
+		case codegen.OpRet:
+			if in.Arg1 != nil {
+				if _, ok := dst.(int); ok {
+					body = append(body, codegen.Instruction{Op: codegen.OpAssign, Arg1: mapAddr(in.Arg1, rename), Arg3: dst, Type: in.Type})
+				}
+			}
+			if n < len(index)-1 {
+				exits = append(exits, len(body))
+				body = append(body, codegen.Instruction{Op: codegen.OpJmp})
+			}
+		default:
+			body = append(body, rewriteOperands(in, rename))
+		}
+	}
+
+	// the OpEnd closing the callee is where falling off its body lands
+	if len(index) > 0 {
+		offset[index[len(index)-1]+1] = len(body)
+	}
+
+	for off, in := range body {
+		if !isJump(in.Op) {
+			continue
+		}
+		if t, ok := in.Arg3.(int); ok {
+			body[off].Arg3 = base + offset[t]
+		}
+	}
+	for _, off := range exits {
+		body[off].Arg3 = base + len(body)
+	}
+
+	return body
+}
+
+// mapAddr applies f to an operand when it is an address
+func mapAddr(arg any, f func(int) int) any {
+	if addr, ok := arg.(int); ok {
+		return f(addr)
+	}
+	return arg
+}
@@ -0,0 +1,146 @@
+package optimizer
+
+import (
+	"dolme/pkg/parser/codegen"
+)
+
+// isBinary reports whether op reads Arg1 and Arg2 and writes Arg3
+func isBinary(op codegen.Operation) bool {
+	switch op {
+	case codegen.OpAdd, codegen.OpSub, codegen.OpMul, codegen.OpDiv, codegen.OpMod,
+		codegen.OpShl, codegen.OpShr, codegen.OpUShr,
+		codegen.OpAnd, codegen.OpOr, codegen.OpConcat,
+		codegen.OpEq, codegen.OpNeq, codegen.OpLt, codegen.OpLe, codegen.OpGt, codegen.OpGe:
+		return true
+	default:
+		return false
+	}
+}
+
+// isUnary reports whether op reads Arg1 and writes Arg3
+func isUnary(op codegen.Operation) bool {
+	return op == codegen.OpNot || op == codegen.OpNeg || op == codegen.OpLen
+}
+
+// isJump reports whether op transfers control to the PB index in Arg3
+func isJump(op codegen.Operation) bool {
+	return op == codegen.OpJmp || op == codegen.OpJmpf || op == codegen.OpJmpt
+}
+
+// leavesFrame reports whether op ends the current function's execution
+func leavesFrame(op codegen.Operation) bool {
+	return op == codegen.OpRet || op == codegen.OpTailCall
+}
+
+// uses returns the addresses read by an instruction
+func uses(in codegen.Instruction) []int {
+	out := make([]int, 0, 2)
+	add := func(arg any) {
+		if addr, ok := arg.(int); ok {
+			out = append(out, addr)
+		}
+	}
+
+	switch {
+	case isBinary(in.Op):
+		add(in.Arg1)
+		add(in.Arg2)
+	case in.Op == codegen.OpAssign, isUnary(in.Op), in.Op == codegen.OpJmpf, in.Op == codegen.OpJmpt,
+		in.Op == codegen.OpRet, in.Op == codegen.OpArg, in.Op == codegen.OpPrint,
+		in.Op == codegen.OpListLen, in.Op == codegen.OpElems:
+		add(in.Arg1)
+	case in.Op == codegen.OpAppend:
+		add(in.Arg1)
+		add(in.Arg3)
+	case in.Op == codegen.OpIndexLoad, in.Op == codegen.OpBounds, in.Op == codegen.OpFieldLoad:
+		add(in.Arg1)
+		add(in.Arg2)
+	case in.Op == codegen.OpIndexStore, in.Op == codegen.OpFieldStore:
+		add(in.Arg1)
+		add(in.Arg2)
+		add(in.Arg3)
+	}
+
+	return out
+}
+
+// def returns the address written by an instruction, if any
+func def(in codegen.Instruction) (int, bool) {
+	switch {
+	case isBinary(in.Op), in.Op == codegen.OpAssign, isUnary(in.Op), in.Op == codegen.OpCall,
+		in.Op == codegen.OpAlloc, in.Op == codegen.OpIndexLoad,
+		in.Op == codegen.OpList, in.Op == codegen.OpListLen, in.Op == codegen.OpElems,
+		in.Op == codegen.OpStruct, in.Op == codegen.OpFieldLoad:
+		addr, ok := in.Arg3.(int)
+		return addr, ok
+	case in.Op == codegen.OpParam:
+		addr, ok := in.Arg1.(int)
+		return addr, ok
+	default:
+		return 0, false
+	}
+}
+
+// rewriteOperands applies f to every address an instruction reads or writes.
+// Jump targets, argument positions, call arities and the source line of a
+// bounds check are left untouched.
+func rewriteOperands(in codegen.Instruction, f func(int) int) codegen.Instruction {
+	mapArg := func(arg any) any {
+		if addr, ok := arg.(int); ok {
+			return f(addr)
+		}
+		return arg
+	}
+
+	switch {
+	case isBinary(in.Op), in.Op == codegen.OpIndexLoad, in.Op == codegen.OpIndexStore,
+		in.Op == codegen.OpFieldLoad, in.Op == codegen.OpFieldStore:
+		in.Arg1 = mapArg(in.Arg1)
+		in.Arg2 = mapArg(in.Arg2)
+		in.Arg3 = mapArg(in.Arg3)
+	case in.Op == codegen.OpBounds:
+		in.Arg1 = mapArg(in.Arg1)
+		in.Arg2 = mapArg(in.Arg2)
+	case in.Op == codegen.OpAssign, isUnary(in.Op),
+		in.Op == codegen.OpListLen, in.Op == codegen.OpElems, in.Op == codegen.OpAppend:
+		in.Arg1 = mapArg(in.Arg1)
+		in.Arg3 = mapArg(in.Arg3)
+	case in.Op == codegen.OpJmpf, in.Op == codegen.OpJmpt, in.Op == codegen.OpRet,
+		in.Op == codegen.OpArg, in.Op == codegen.OpPrint, in.Op == codegen.OpParam:
+		in.Arg1 = mapArg(in.Arg1)
+	case in.Op == codegen.OpCall, in.Op == codegen.OpAlloc, in.Op == codegen.OpList, in.Op == codegen.OpStruct:
+		in.Arg3 = mapArg(in.Arg3)
+	}
+
+	return in
+}
+
+// Region is the part of a program that runs in a single frame: either a
+// function body (between OpLabel and OpEnd) or the top-level code
+type Region struct {
+	Name  string // function name, empty for top-level code
+	Label int    // PB index of the OpLabel, -1 for top-level code
+	Index []int  // PB indices in program order
+}
+
+// Regions splits a program into its top-level region followed by one region per function
+func Regions(pb []codegen.Instruction) []Region {
+	top := Region{Label: -1}
+	funcs := make([]Region, 0)
+
+	for idx := 0; idx < len(pb); idx++ {
+		if pb[idx].Op != codegen.OpLabel {
+			top.Index = append(top.Index, idx)
+			continue
+		}
+
+		name, _ := pb[idx].Arg1.(string)
+		fn := Region{Name: name, Label: idx}
+		for idx++; idx < len(pb) && pb[idx].Op != codegen.OpEnd; idx++ {
+			fn.Index = append(fn.Index, idx)
+		}
+		funcs = append(funcs, fn)
+	}
+
+	return append([]Region{top}, funcs...)
+}
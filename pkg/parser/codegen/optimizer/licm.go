@@ -0,0 +1,183 @@
+package optimizer
+
+import (
+	"dolme/pkg/parser/codegen"
+	"strconv"
+	"strings"
+)
+
+// HoistLoopInvariants moves computations whose value cannot change between
+// iterations out of while loops. Loops are found from the back edges of each
+// region's dominator tree; an invariant instruction is moved into a preheader
+// placed right before the loop header. Jumps from outside the loop enter the
+// preheader while the back edges keep targeting the header, so the hoisted
+// code runs once per entry into the loop.
+//
+// Only writes to temps are hoisted, and only when that temp has a single
+// definition and is not read after the loop. It has to run before
+// RenumberTemps, which makes temps share addresses.
+func HoistLoopInvariants(pb []codegen.Instruction) []codegen.Instruction {
+	out := append([]codegen.Instruction(nil), pb...)
+
+	// hoisting shifts PB indices, so start over after every loop that changed
+	for changed := true; changed; {
+		changed = false
+
+	regions:
+		for _, r := range Regions(out) {
+			g := BuildCFG(out, r)
+			for _, l := range g.Loops() {
+				if hoisted := hoistLoop(out, g, l); hoisted != nil {
+					out = hoisted
+					changed = true
+					break regions
+				}
+			}
+		}
+	}
+
+	return out
+}
+
+// hoistLoop moves the invariant instructions of a loop into a new preheader.
+// It returns nil when nothing can be moved.
+func hoistLoop(pb []codegen.Instruction, g *CFG, l Loop) []codegen.Instruction {
+	r := g.Region
+	headerPos := g.Blocks[l.Header].Start
+	at := r.Index[headerPos]
+
+	inLoop := make(map[int]bool) // PB indices of the loop
+	for id := range l.Blocks {
+		b := g.Blocks[id]
+		for pos := b.Start; pos < b.End; pos++ {
+			inLoop[r.Index[pos]] = true
+		}
+	}
+
+	// the preheader only works when the loop is entered through jumps to the
+	// header itself or by falling into it from outside
+	if headerPos > 0 {
+		prev := r.Index[headerPos-1]
+		if inLoop[prev] && pb[prev].Op != codegen.OpJmp && !leavesFrame(pb[prev].Op) {
+			return nil
+		}
+	}
+	for idx := range inLoop {
+		in := pb[idx]
+		if isJump(in.Op) && in.Arg3 != at && g.Position(in.Arg3) == headerPos {
+			return nil
+		}
+	}
+
+	hoist := invariants(pb, r, inLoop)
+	if len(hoist) == 0 {
+		return nil
+	}
+
+	moved := make(map[int]bool)
+	preheader := make([]codegen.Instruction, 0, len(hoist))
+	for _, idx := range hoist {
+		preheader = append(preheader, pb[idx])
+		moved[idx+len(hoist)] = true // every hoisted index is at or after the header
+	}
+
+	out := insertBefore(pb, at, preheader, func(from int) bool { return !inLoop[from] })
+	return compact(out, func(idx int) bool { return !moved[idx] })
+}
+
+// invariants returns, in program order, the PB indices of the loop
+// instructions that compute the same value on every iteration
+func invariants(pb []codegen.Instruction, r Region, inLoop map[int]bool) []int {
+	defsInLoop := make(map[int]int)
+	defsInRegion := make(map[int]int)
+	usedOutside := make(map[int]bool)
+	hasCall := false
+
+	for _, idx := range r.Index {
+		in := pb[idx]
+		d, writes := def(in)
+		if writes {
+			defsInRegion[d]++
+		}
+		if !inLoop[idx] {
+			for _, u := range uses(in) {
+				usedOutside[u] = true
+			}
+			continue
+		}
+		if writes {
+			defsInLoop[d]++
+		}
+		if in.Op == codegen.OpCall {
+			hasCall = true
+		}
+	}
+
+	invariant := make(map[int]bool) // temps already known to be invariant
+	hoist := make(map[int]bool)
+
+	operandInvariant := func(arg any) bool {
+		addr, ok := arg.(int)
+		if !ok {
+			return true // immediate
+		}
+		if invariant[addr] {
+			return true
+		}
+		if defsInLoop[addr] > 0 {
+			return false
+		}
+		// a callee in the loop may write any global
+		return !hasCall || addr < codegen.GlobalAddrBase || addr >= codegen.TempAddrBase
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, idx := range r.Index {
+			in := pb[idx]
+			if !inLoop[idx] || hoist[idx] || !isPure(in) {
+				continue
+			}
+
+			d, _ := def(in)
+			if !codegen.IsTemp(d) || defsInRegion[d] != 1 || usedOutside[d] {
+				continue
+			}
+			if !operandInvariant(in.Arg1) || (isBinary(in.Op) && !operandInvariant(in.Arg2)) {
+				continue
+			}
+
+			hoist[idx] = true
+			invariant[d] = true
+			changed = true
+		}
+	}
+
+	out := make([]int, 0, len(hoist))
+	for _, idx := range r.Index {
+		if hoist[idx] {
+			out = append(out, idx)
+		}
+	}
+
+	return out
+}
+
+// isPure reports whether an instruction only computes a value and cannot
+// fail, so running it earlier or more often changes nothing
+func isPure(in codegen.Instruction) bool {
+	switch {
+	case in.Op == codegen.OpDiv, in.Op == codegen.OpMod:
+		// division traps on a zero divisor unless it is a known nonzero immediate
+		s, ok := in.Arg2.(string)
+		if !ok {
+			return false
+		}
+		v, err := strconv.ParseFloat(strings.TrimPrefix(s, "#"), 64)
+		return err == nil && v != 0
+	case isBinary(in.Op), in.Op == codegen.OpAssign, isUnary(in.Op):
+		return true
+	default:
+		return false
+	}
+}
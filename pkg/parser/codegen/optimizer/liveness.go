@@ -0,0 +1,92 @@
+package optimizer
+
+import (
+	"dolme/pkg/parser/codegen"
+)
+
+// addrSet is a set of IR addresses
+type addrSet map[int]bool
+
+// liveness computes, for every position of the region, the tracked addresses
+// that are live right after the instruction executes
+func liveness(pb []codegen.Instruction, g *CFG, track func(int) bool) []addrSet {
+	n := len(g.Blocks)
+	gen := make([]addrSet, n)
+	kill := make([]addrSet, n)
+	liveIn := make([]addrSet, n)
+	liveOut := make([]addrSet, n)
+
+	// local use/def summary of each block
+	for _, b := range g.Blocks {
+		gen[b.ID], kill[b.ID] = addrSet{}, addrSet{}
+		liveIn[b.ID], liveOut[b.ID] = addrSet{}, addrSet{}
+
+		for pos := b.Start; pos < b.End; pos++ {
+			in := pb[g.Region.Index[pos]]
+			for _, u := range uses(in) {
+				if track(u) && !kill[b.ID][u] {
+					gen[b.ID][u] = true
+				}
+			}
+			if d, ok := def(in); ok && track(d) {
+				kill[b.ID][d] = true
+			}
+		}
+	}
+
+	// iterate to a fixed point, walking blocks backwards for faster convergence
+	for changed := true; changed; {
+		changed = false
+		for id := n - 1; id >= 0; id-- {
+			out := addrSet{}
+			for _, s := range g.Blocks[id].Succs {
+				for a := range liveIn[s] {
+					out[a] = true
+				}
+			}
+
+			in := addrSet{}
+			for a := range gen[id] {
+				in[a] = true
+			}
+			for a := range out {
+				if !kill[id][a] {
+					in[a] = true
+				}
+			}
+
+			if len(in) != len(liveIn[id]) || len(out) != len(liveOut[id]) {
+				changed = true
+			}
+			liveIn[id], liveOut[id] = in, out
+		}
+	}
+
+	// expand block summaries into per-instruction live-out sets
+	after := make([]addrSet, len(g.Region.Index))
+	for _, b := range g.Blocks {
+		live := addrSet{}
+		for a := range liveOut[b.ID] {
+			live[a] = true
+		}
+
+		for pos := b.End - 1; pos >= b.Start; pos-- {
+			after[pos] = addrSet{}
+			for a := range live {
+				after[pos][a] = true
+			}
+
+			in := pb[g.Region.Index[pos]]
+			if d, ok := def(in); ok {
+				delete(live, d)
+			}
+			for _, u := range uses(in) {
+				if track(u) {
+					live[u] = true
+				}
+			}
+		}
+	}
+
+	return after
+}
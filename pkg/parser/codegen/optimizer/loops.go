@@ -0,0 +1,146 @@
+package optimizer
+
+import (
+	"sort"
+)
+
+// Dominators returns the immediate dominator of every block (Cooper, Harvey and
+// Kennedy's iterative algorithm). The entry block is its own dominator and
+// unreachable blocks get -1.
+func (g *CFG) Dominators() []int {
+	idom := make([]int, len(g.Blocks))
+	for i := range idom {
+		idom[i] = -1
+	}
+	if len(g.Blocks) == 0 {
+		return idom
+	}
+
+	// reverse postorder from the entry
+	order := make([]int, 0, len(g.Blocks))
+	rank := make([]int, len(g.Blocks))
+	seen := make([]bool, len(g.Blocks))
+	var visit func(b int)
+	visit = func(b int) {
+		seen[b] = true
+		for _, s := range g.Blocks[b].Succs {
+			if !seen[s] {
+				visit(s)
+			}
+		}
+		order = append(order, b)
+	}
+	visit(0)
+	for i, j := 0, len(order)-1; i < j; i, j = i+1, j-1 {
+		order[i], order[j] = order[j], order[i]
+	}
+	for i, b := range order {
+		rank[b] = i
+	}
+
+	intersect := func(a, b int) int {
+		for a != b {
+			for rank[a] > rank[b] {
+				a = idom[a]
+			}
+			for rank[b] > rank[a] {
+				b = idom[b]
+			}
+		}
+		return a
+	}
+
+	idom[0] = 0
+	for changed := true; changed; {
+		changed = false
+		for _, b := range order[1:] {
+			newIdom := -1
+			for _, p := range g.Blocks[b].Preds {
+				if idom[p] == -1 {
+					continue
+				}
+				if newIdom == -1 {
+					newIdom = p
+				} else {
+					newIdom = intersect(p, newIdom)
+				}
+			}
+			if newIdom != idom[b] {
+				idom[b] = newIdom
+				changed = true
+			}
+		}
+	}
+
+	return idom
+}
+
+// dominates reports whether block a dominates block b given the immediate dominators
+func dominates(idom []int, a, b int) bool {
+	if idom[b] == -1 {
+		return false
+	}
+
+	for {
+		if b == a {
+			return true
+		}
+		if idom[b] == b {
+			return false
+		}
+		b = idom[b]
+	}
+}
+
+// Loop is a natural loop: a header block and every block that reaches one of
+// its back edges without passing through the header
+type Loop struct {
+	Header int          // header block ID
+	Blocks map[int]bool // block IDs in the loop, header included
+}
+
+// Loops finds the natural loops of the graph, innermost (smallest) first.
+// Back edges sharing a header are merged into one loop.
+func (g *CFG) Loops() []Loop {
+	idom := g.Dominators()
+	byHeader := make(map[int]*Loop)
+
+	for _, b := range g.Blocks {
+		for _, h := range b.Succs {
+			if !dominates(idom, h, b.ID) {
+				continue
+			}
+
+			loop, ok := byHeader[h]
+			if !ok {
+				loop = &Loop{Header: h, Blocks: map[int]bool{h: true}}
+				byHeader[h] = loop
+			}
+
+			// walk predecessors back from the latch until the header
+			work := []int{b.ID}
+			for len(work) > 0 {
+				n := work[len(work)-1]
+				work = work[:len(work)-1]
+				if loop.Blocks[n] {
+					continue
+				}
+				loop.Blocks[n] = true
+				work = append(work, g.Blocks[n].Preds...)
+			}
+		}
+	}
+
+	loops := make([]Loop, 0, len(byHeader))
+	for _, l := range byHeader {
+		loops = append(loops, *l)
+	}
+	sort.Slice(loops, func(i, j int) bool {
+		if len(loops[i].Blocks) != len(loops[j].Blocks) {
+			return len(loops[i].Blocks) < len(loops[j].Blocks)
+		}
+		return loops[i].Header < loops[j].Header
+	})
+
+	return loops
+}
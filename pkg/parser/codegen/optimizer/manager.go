@@ -0,0 +1,187 @@
+package optimizer
+
+import (
+	"dolme/pkg/parser/codegen"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Config carries what passes need besides the instructions themselves
+type Config struct {
+	CG              *codegen.Codegen // code generator owning the type table
+	InlineThreshold int              // largest function body the inliner copies
+	EvalSteps       int              // step budget for evaluating a pure call at compile time
+}
+
+// Pass is a named transformation of the program block
+type Pass struct {
+	Name        string
+	Description string
+	Run         func(pb []codegen.Instruction, cfg *Config) []codegen.Instruction
+}
+
+// registry lists every pass that can be named in a pipeline
+var registry = map[string]Pass{
+	"constfold": {"constfold", "fold operators over constants and branches on known conditions", func(pb []codegen.Instruction, _ *Config) []codegen.Instruction {
+		return FoldConstants(pb)
+	}},
+	"dce": {"dce", "remove unreachable code and unread computations", func(pb []codegen.Instruction, _ *Config) []codegen.Instruction {
+		return EliminateDeadCode(pb)
+	}},
+	"simplify": {"simplify", "drop identity operations and turn power-of-two arithmetic into shifts", func(pb []codegen.Instruction, cfg *Config) []codegen.Instruction {
+		return SimplifyAlgebra(pb, cfg.CG)
+	}},
+	"evalcalls": {"evalcalls", "evaluate calls to pure functions with constant arguments", func(pb []codegen.Instruction, cfg *Config) []codegen.Instruction {
+		return EvaluatePureCalls(pb, cfg.EvalSteps)
+	}},
+	"inline": {"inline", "copy small non-recursive functions into their callers", func(pb []codegen.Instruction, cfg *Config) []codegen.Instruction {
+		return InlineFunctions(pb, cfg.CG, cfg.InlineThreshold)
+	}},
+	"tailcall": {"tailcall", "turn tail calls into jumps or frame-reusing calls", func(pb []codegen.Instruction, cfg *Config) []codegen.Instruction {
+		return EliminateTailCalls(pb, cfg.CG)
+	}},
+	"cse": {"cse", "reuse computations repeated inside a basic block", func(pb []codegen.Instruction, cfg *Config) []codegen.Instruction {
+		return EliminateCommonSubexpressions(pb, cfg.CG)
+	}},
+	"licm": {"licm", "hoist loop-invariant computations out of loops", func(pb []codegen.Instruction, _ *Config) []codegen.Instruction {
+		return HoistLoopInvariants(pb)
+	}},
+	"deadfuncs": {"deadfuncs", "remove functions the top-level code never calls", func(pb []codegen.Instruction, _ *Config) []codegen.Instruction {
+		return EliminateDeadFunctions(pb)
+	}},
+	"temps": {"temps", "fold temps with disjoint live ranges onto shared addresses", func(pb []codegen.Instruction, cfg *Config) []codegen.Instruction {
+		return RenumberTemps(pb, cfg.CG)
+	}},
+}
+
+// presets are the pipelines behind the -O levels
+var presets = [][]string{
+	0: {},
+	1: {"constfold", "simplify", "cse", "dce", "deadfuncs", "temps"},
+	2: {"evalcalls", "inline", "tailcall", "constfold", "simplify", "cse", "licm", "dce", "deadfuncs", "temps"},
+}
+
+// PassNames returns the names of all registered passes in alphabetical order
+func PassNames() []string {
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Preset returns the pipeline of an optimization level (0, 1 or 2)
+func Preset(level int) ([]Pass, error) {
+	if level < 0 || level >= len(presets) {
+		return nil, fmt.Errorf("unknown optimization level %d", level)
+	}
+
+	return lookup(presets[level])
+}
+
+// ParsePipeline turns a comma-separated list of pass names into a pipeline
+func ParsePipeline(spec string) ([]Pass, error) {
+	names := make([]string, 0)
+	for _, name := range strings.Split(spec, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	return lookup(names)
+}
+
+// lookup resolves pass names against the registry
+func lookup(names []string) ([]Pass, error) {
+	passes := make([]Pass, 0, len(names))
+	for _, name := range names {
+		p, ok := registry[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown pass %q (available: %s)", name, strings.Join(PassNames(), ", "))
+		}
+		passes = append(passes, p)
+	}
+
+	return passes, nil
+}
+
+// Stat records what one pass run did
+type Stat struct {
+	Pass     string        // pass name
+	Duration time.Duration // wall time spent in the pass
+	Before   int           // instruction count going in
+	After    int           // instruction count coming out
+}
+
+// Manager runs a pipeline of passes over a program
+type Manager struct {
+	passes     []Pass
+	cfg        Config
+	printAfter map[string]bool
+	dump       func(pass string, pb []codegen.Instruction)
+	stats      []Stat
+}
+
+// ManagerOption configures a Manager
+type ManagerOption func(*Manager)
+
+// WithInlineThreshold sets the largest function body the inliner copies
+func WithInlineThreshold(n int) ManagerOption {
+	return func(m *Manager) { m.cfg.InlineThreshold = n }
+}
+
+// WithEvalSteps sets the step budget for evaluating a pure call at compile time
+func WithEvalSteps(n int) ManagerOption {
+	return func(m *Manager) { m.cfg.EvalSteps = n }
+}
+
+// WithPrintAfter hands the program to dump after each run of the named passes
+func WithPrintAfter(names []string, dump func(pass string, pb []codegen.Instruction)) ManagerOption {
+	return func(m *Manager) {
+		for _, name := range names {
+			m.printAfter[name] = true
+		}
+		m.dump = dump
+	}
+}
+
+// NewManager creates a Manager running passes in order
+func NewManager(cg *codegen.Codegen, passes []Pass, opts ...ManagerOption) *Manager {
+	m := &Manager{
+		passes:     passes,
+		cfg:        Config{CG: cg, InlineThreshold: DefaultInlineThreshold, EvalSteps: DefaultEvalSteps},
+		printAfter: make(map[string]bool),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	return m
+}
+
+// Run applies the pipeline to pb and returns the optimized program
+func (m *Manager) Run(pb []codegen.Instruction) []codegen.Instruction {
+	m.stats = m.stats[:0]
+
+	for _, p := range m.passes {
+		before := len(pb)
+		start := time.Now()
+		pb = p.Run(pb, &m.cfg)
+		m.stats = append(m.stats, Stat{Pass: p.Name, Duration: time.Since(start), Before: before, After: len(pb)})
+
+		if m.printAfter[p.Name] && m.dump != nil {
+			m.dump(p.Name, pb)
+		}
+	}
+
+	return pb
+}
+
+// Stats returns one entry per pass executed by the last Run
+func (m *Manager) Stats() []Stat {
+	return m.stats
+}
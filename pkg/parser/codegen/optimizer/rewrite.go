@@ -0,0 +1,86 @@
+package optimizer
+
+import (
+	"dolme/pkg/parser/codegen"
+)
+
+// compact removes the instructions for which keep returns false and remaps
+// jump targets. A jump to a removed instruction lands on the next one kept.
+func compact(pb []codegen.Instruction, keep func(idx int) bool) []codegen.Instruction {
+	// newIndex[i] is the position instruction i (or its next survivor) ends up at
+	newIndex := make([]int, len(pb)+1)
+	out := make([]codegen.Instruction, 0, len(pb))
+	for idx, in := range pb {
+		newIndex[idx] = len(out)
+		if keep(idx) {
+			out = append(out, in)
+		}
+	}
+	newIndex[len(pb)] = len(out)
+
+	for idx, in := range out {
+		if !isJump(in.Op) {
+			continue
+		}
+		if t, ok := in.Arg3.(int); ok && t >= 0 && t <= len(pb) {
+			out[idx].Arg3 = newIndex[t]
+		}
+	}
+
+	return out
+}
+
+// insertBefore places ins in front of PB index at and remaps jump targets. A
+// jump to at coming from an index for which enter returns true lands on the
+// inserted code; every other jump to at still lands on the original
+// instruction. The inserted instructions must not be jumps themselves.
+func insertBefore(pb []codegen.Instruction, at int, ins []codegen.Instruction, enter func(from int) bool) []codegen.Instruction {
+	n := len(ins)
+	out := make([]codegen.Instruction, 0, len(pb)+n)
+	out = append(out, pb[:at]...)
+	out = append(out, ins...)
+	out = append(out, pb[at:]...)
+
+	for idx, in := range pb {
+		if !isJump(in.Op) {
+			continue
+		}
+		t, ok := in.Arg3.(int)
+		if !ok || t < at || (t == at && enter(idx)) {
+			continue
+		}
+
+		moved := idx
+		if idx >= at {
+			moved += n
+		}
+		out[moved].Arg3 = t + n
+	}
+
+	return out
+}
+
+// expandEach replaces every instruction with the instructions f returns for
+// it, possibly none, and remaps jump targets. A jump to a replaced instruction
+// lands on the first instruction of its replacement, or the next one kept.
+// The replacements must not add jumps of their own.
+func expandEach(pb []codegen.Instruction, f func(idx int, in codegen.Instruction) []codegen.Instruction) []codegen.Instruction {
+	newIndex := make([]int, len(pb)+1)
+	out := make([]codegen.Instruction, 0, len(pb))
+	for idx, in := range pb {
+		newIndex[idx] = len(out)
+		out = append(out, f(idx, in)...)
+	}
+	newIndex[len(pb)] = len(out)
+
+	for idx, in := range out {
+		if !isJump(in.Op) {
+			continue
+		}
+		if t, ok := in.Arg3.(int); ok && t >= 0 && t <= len(pb) {
+			out[idx].Arg3 = newIndex[t]
+		}
+	}
+
+	return out
+}
@@ -0,0 +1,255 @@
+package optimizer
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser/codegen"
+	"math/bits"
+	"strconv"
+)
+
+// staticTypes returns the declared type of each address a region touches.
+// Frame-local addresses take the type their first definition in the region
+// produces, since locals share addresses across functions and codegen does
+// not type every temp; globals come from the code generator's type table.
+func staticTypes(pb []codegen.Instruction, r Region, cg *codegen.Codegen) func(int) lexer.TokenType {
+	types := make(map[int]lexer.TokenType)
+	for _, idx := range r.Index {
+		in := pb[idx]
+		d, ok := def(in)
+		if !ok || d < codegen.TempAddrBase {
+			continue
+		}
+		if _, known := types[d]; known {
+			continue
+		}
+		if in.Op == codegen.OpParam {
+			types[d] = in.Type
+		} else {
+			types[d] = in.ResultType()
+		}
+	}
+
+	return func(addr int) lexer.TokenType {
+		if t, ok := types[addr]; ok && t != lexer.EOF {
+			return t
+		}
+		return cg.GetVariableType(addr)
+	}
+}
+
+// powerOfTwo returns k when c is the int constant 2^k with k >= 1
+func powerOfTwo(c constant) (int, bool) {
+	if c.kind != lexer.INT || c.i < 2 || c.i&(c.i-1) != 0 {
+		return 0, false
+	}
+	return bits.TrailingZeros64(uint64(c.i)), true
+}
+
+// isValue reports whether a numeric constant equals v
+func isValue(c constant, v float64) bool {
+	switch c.kind {
+	case lexer.INT:
+		return float64(c.i) == v
+	case lexer.FLOAT:
+		return c.f == v
+	default:
+		return false
+	}
+}
+
+// SimplifyAlgebra removes identity operations and replaces int multiplies,
+// divides and remainders by powers of two with shifts. An identity such as
+// x + 0 or x * 1 only becomes a copy of x when x already has the result
+// type; on floats only x * 1, x / 1 and x - 0 are dropped, as x + 0 turns
+// -0.0 into 0.0. Signed division rounds toward zero, so a negative dividend
+// gets 2^k - 1 added before the arithmetic shift. Double negations reading a
+// bool are copies of that bool.
+func SimplifyAlgebra(pb []codegen.Instruction, cg *codegen.Codegen) []codegen.Instruction {
+	replaced := make(map[int][]codegen.Instruction)
+
+	for _, r := range Regions(pb) {
+		g := BuildCFG(pb, r)
+		typeOf := staticTypes(pb, r, cg)
+
+		for _, b := range g.Blocks {
+			known := knownConstants{}
+			negated := make(map[int]holder) // temp -> bool it negates, with that bool's version
+			version := make(map[int]int)    // address -> number of writes seen in this block
+
+			for pos := b.Start; pos < b.End; pos++ {
+				idx := r.Index[pos]
+				in := pb[idx]
+
+				if seq := simplify(in, known, typeOf, negated, version, cg); seq != nil {
+					replaced[idx] = seq
+				}
+
+				if d, ok := def(in); ok {
+					src, isAddr := in.Arg1.(int)
+					if in.Op == codegen.OpNot && isAddr {
+						negated[d] = holder{addr: src, vn: version[src]}
+					} else {
+						delete(negated, d)
+					}
+					version[d]++
+				}
+				known.update(in)
+			}
+		}
+	}
+
+	if len(replaced) == 0 {
+		return pb
+	}
+
+	return expandEach(pb, func(idx int, in codegen.Instruction) []codegen.Instruction {
+		if seq, ok := replaced[idx]; ok {
+			return seq
+		}
+		return []codegen.Instruction{in}
+	})
+}
+
+// simplify returns the replacement for a single instruction, or nil to keep it
+func simplify(in codegen.Instruction, known knownConstants, typeOf func(int) lexer.TokenType, negated map[int]holder, version map[int]int, cg *codegen.Codegen) []codegen.Instruction {
+	dst, ok := in.Arg3.(int)
+	if !ok {
+		return nil
+	}
+	result := in.ResultType()
+	copyOf := func(src int) []codegen.Instruction {
+		return []codegen.Instruction{{Op: codegen.OpAssign, Arg1: src, Arg3: dst, Type: result}}
+	}
+
+	if in.Op == codegen.OpNot {
+		inner, ok := in.Arg1.(int)
+		if !ok {
+			return nil
+		}
+		h, ok := negated[inner]
+		if !ok || version[h.addr] != h.vn || typeOf(h.addr) != lexer.BOOL {
+			return nil
+		}
+		return copyOf(h.addr)
+	}
+
+	if !isBinary(in.Op) {
+		return nil
+	}
+
+	// find the single non-constant operand and the constant on the other side
+	x, c, constLeft := 0, constant{}, false
+	c1, ok1 := known.value(in.Arg1)
+	c2, ok2 := known.value(in.Arg2)
+	switch {
+	case ok1 && !ok2:
+		addr, isAddr := in.Arg2.(int)
+		if !isAddr {
+			return nil
+		}
+		x, c, constLeft = addr, c1, true
+	case ok2 && !ok1:
+		addr, isAddr := in.Arg1.(int)
+		if !isAddr {
+			return nil
+		}
+		x, c = addr, c2
+	default:
+		return nil
+	}
+
+	if typeOf(x) != result {
+		return nil
+	}
+
+	switch result {
+	case lexer.INT:
+		if in.Type != lexer.INT || c.kind != lexer.INT {
+			return nil
+		}
+		switch in.Op {
+		case codegen.OpAdd:
+			if c.i == 0 {
+				return copyOf(x)
+			}
+		case codegen.OpSub:
+			if !constLeft && c.i == 0 {
+				return copyOf(x)
+			}
+		case codegen.OpMul:
+			if c.i == 1 {
+				return copyOf(x)
+			}
+			if c.i == 0 {
+				return []codegen.Instruction{{Op: codegen.OpAssign, Arg1: "#0", Arg3: dst, Type: lexer.INT}}
+			}
+			if k, ok := powerOfTwo(c); ok {
+				return []codegen.Instruction{{Op: codegen.OpShl, Arg1: x, Arg2: "#" + strconv.Itoa(k), Arg3: dst, Type: lexer.INT}}
+			}
+		case codegen.OpDiv, codegen.OpMod:
+			if constLeft {
+				return nil
+			}
+			if c.i == 1 {
+				if in.Op == codegen.OpDiv {
+					return copyOf(x)
+				}
+				return []codegen.Instruction{{Op: codegen.OpAssign, Arg1: "#0", Arg3: dst, Type: lexer.INT}}
+			}
+			if k, ok := powerOfTwo(c); ok && cg.TempsLeft() > 0 {
+				return divideByShift(in.Op, x, k, dst, cg.NewTemp(lexer.INT))
+			}
+		}
+
+	case lexer.FLOAT:
+		if in.Type != lexer.FLOAT {
+			return nil
+		}
+		switch in.Op {
+		case codegen.OpMul:
+			if isValue(c, 1) {
+				return copyOf(x)
+			}
+		case codegen.OpDiv:
+			if !constLeft && isValue(c, 1) {
+				return copyOf(x)
+			}
+		case codegen.OpSub:
+			if !constLeft && isValue(c, 0) {
+				return copyOf(x)
+			}
+		}
+
+	case lexer.BOOL:
+		if c.kind != lexer.BOOL {
+			return nil
+		}
+		if (in.Op == codegen.OpAnd && c.b) || (in.Op == codegen.OpOr && !c.b) {
+			return copyOf(x)
+		}
+	}
+
+	return nil
+}
+
+// divideByShift computes x / 2^k or x % 2^k for a signed int x, rounding
+// toward zero like sdiv: a negative x is biased by 2^k - 1 before shifting
+func divideByShift(op codegen.Operation, x, k, dst, t int) []codegen.Instruction {
+	imm := func(n int) string { return "#" + strconv.Itoa(n) }
+	seq := []codegen.Instruction{
+		{Op: codegen.OpShr, Arg1: x, Arg2: imm(63), Arg3: t, Type: lexer.INT},      // -1 when negative, else 0
+		{Op: codegen.OpUShr, Arg1: t, Arg2: imm(64 - k), Arg3: t, Type: lexer.INT}, // 2^k - 1 when negative, else 0
+		{Op: codegen.OpAdd, Arg1: x, Arg2: t, Arg3: t, Type: lexer.INT},
+	}
+
+	if op == codegen.OpDiv {
+		return append(seq, codegen.Instruction{Op: codegen.OpShr, Arg1: t, Arg2: imm(k), Arg3: dst, Type: lexer.INT})
+	}
+
+	// x % 2^k = x - (x / 2^k) * 2^k
+	return append(seq,
+		codegen.Instruction{Op: codegen.OpShr, Arg1: t, Arg2: imm(k), Arg3: t, Type: lexer.INT},
+		codegen.Instruction{Op: codegen.OpShl, Arg1: t, Arg2: imm(k), Arg3: t, Type: lexer.INT},
+		codegen.Instruction{Op: codegen.OpSub, Arg1: x, Arg2: t, Arg3: dst, Type: lexer.INT},
+	)
+}
@@ -0,0 +1,161 @@
+package optimizer
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser/codegen"
+)
+
+// tailSite is a call whose result is returned right away
+type tailSite struct {
+	self  bool        // the function calls itself
+	args  map[int]int // PB index of an OpArg -> parameter position
+	stage map[int]int // parameter position -> temp holding the argument
+	ret   int         // PB index of the OpRet following the call
+}
+
+// EliminateTailCalls rewrites calls whose result is immediately returned so
+// they no longer grow the call stack. A function calling itself reassigns its
+// parameters and jumps back to the start of its body. Tail calls to other
+// functions with the same return type become OpTailCall, which hands the
+// current frame over to the callee. Arguments are copied into fresh temps
+// where the OpArg stood, so parameters read by later arguments keep their
+// old values until all of them are computed.
+func EliminateTailCalls(pb []codegen.Instruction, cg *codegen.Codegen) []codegen.Instruction {
+	funcs := make(map[string]*callee)
+	for _, r := range Regions(pb)[1:] {
+		funcs[r.Name] = describeCallee(pb, r)
+	}
+
+	targeted := make(map[int]bool)
+	for _, in := range pb {
+		if t, ok := in.Arg3.(int); ok && isJump(in.Op) {
+			targeted[t] = true
+		}
+	}
+
+	sites := make(map[int]*tailSite) // PB index of the OpCall -> site
+	argOf := make(map[int]int)       // PB index of an OpArg -> PB index of its call
+	start := make(map[string]int)    // function -> PB index of its first instruction after the params
+
+	for _, r := range Regions(pb)[1:] {
+		fn := funcs[r.Name]
+		g := BuildCFG(pb, r)
+
+		retType := lexer.EOF
+		for _, idx := range r.Index {
+			if pb[idx].Op == codegen.OpRet && pb[idx].Arg1 != nil {
+				retType = pb[idx].Type
+				break
+			}
+		}
+
+		for pos, idx := range r.Index {
+			in := pb[idx]
+			if pos+1 >= len(r.Index) || in.Op != codegen.OpCall {
+				continue
+			}
+			ret := r.Index[pos+1]
+			dst, ok := in.Arg3.(int)
+			if !ok || pb[ret].Op != codegen.OpRet || pb[ret].Arg1 != dst {
+				continue
+			}
+
+			name, _ := in.Arg1.(string)
+			target, ok := funcs[name]
+			if !ok {
+				continue
+			}
+
+			site := &tailSite{self: name == r.Name, ret: ret}
+			if !site.self {
+				// the callee answers our caller directly, so it has to return the same type
+				if in.Type != retType {
+					continue
+				}
+				sites[idx] = site
+				continue
+			}
+
+			args, ok := callArgs(pb, g, pos, len(target.params))
+			if !ok || cg.TempsLeft() < len(args) {
+				continue
+			}
+			site.args = args
+			site.stage = make(map[int]int)
+			for a, k := range args {
+				if pb[a].Arg1 != fn.params[k] {
+					site.stage[k] = cg.NewTemp(fn.types[fn.params[k]])
+				}
+				argOf[a] = idx
+			}
+			sites[idx] = site
+
+			for _, i := range r.Index {
+				if pb[i].Op != codegen.OpParam {
+					start[r.Name] = i
+					break
+				}
+			}
+		}
+	}
+
+	if len(sites) == 0 {
+		return pb
+	}
+
+	drop := make(map[int]bool) // returns made unreachable by a rewritten call
+	for _, site := range sites {
+		if !targeted[site.ret] {
+			drop[site.ret] = true
+		}
+	}
+
+	out := make([]codegen.Instruction, 0, len(pb))
+	newIndex := make([]int, len(pb)+1)
+
+	for idx, in := range pb {
+		newIndex[idx] = len(out)
+
+		if call, ok := argOf[idx]; ok {
+			site := sites[call]
+			if t, ok := site.stage[site.args[idx]]; ok {
+				out = append(out, codegen.Instruction{Op: codegen.OpAssign, Arg1: in.Arg1, Arg3: t, Type: cg.GetVariableType(t)})
+			}
+			continue
+		}
+
+		if drop[idx] {
+			continue
+		}
+
+		site, ok := sites[idx]
+		switch {
+		case !ok:
+			out = append(out, in)
+		case !site.self:
+			out = append(out, codegen.Instruction{Op: codegen.OpTailCall, Arg1: in.Arg1, Arg2: in.Arg2, Type: in.Type})
+		default:
+			name, _ := in.Arg1.(string)
+			fn := funcs[name]
+			for k := 0; k < len(fn.params); k++ {
+				if t, ok := site.stage[k]; ok {
+					param := fn.params[k]
+					out = append(out, codegen.Instruction{Op: codegen.OpAssign, Arg1: t, Arg3: param, Type: fn.types[param]})
+				}
+			}
+			out = append(out, codegen.Instruction{Op: codegen.OpJmp, Arg3: start[name]})
+		}
+	}
+	newIndex[len(pb)] = len(out)
+
+	for idx, in := range out {
+		if !isJump(in.Op) {
+			continue
+		}
+		if t, ok := in.Arg3.(int); ok && t >= 0 && t <= len(pb) {
+			out[idx].Arg3 = newIndex[t]
+		}
+	}
+
+	return out
+}
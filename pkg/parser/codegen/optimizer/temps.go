@@ -0,0 +1,111 @@
+package optimizer
+
+import (
+	"dolme/pkg/parser/codegen"
+	"sort"
+)
+
+// RenumberTemps shrinks the set of temporaries a program uses. Codegen never
+// recycles temps, so every expression gets a fresh address and with it a stack
+// slot in the backends. Temps whose live ranges do not overlap are folded onto
+// a shared address; only temps of the same static type share one so the type
+// table stays valid for the backends. Copies left copying a temp onto itself
+// are removed.
+func RenumberTemps(pb []codegen.Instruction, cg *codegen.Codegen) []codegen.Instruction {
+	out := append([]codegen.Instruction(nil), pb...)
+	reps := make([]int, 0) // shared addresses handed out so far, in ascending order
+
+	for _, r := range Regions(out) {
+		g := BuildCFG(out, r)
+		after := liveness(out, g, codegen.IsTemp)
+
+		// build the interference graph: a temp interferes with every temp live after its definition
+		interferes := make(map[int]addrSet)
+		edge := func(a, b int) {
+			if interferes[a] == nil {
+				interferes[a] = addrSet{}
+			}
+			if interferes[b] == nil {
+				interferes[b] = addrSet{}
+			}
+			interferes[a][b] = true
+			interferes[b][a] = true
+		}
+
+		order := make([]int, 0)
+		seen := addrSet{}
+		for pos, idx := range r.Index {
+			in := out[idx]
+			addrs := uses(in)
+			if d, ok := def(in); ok {
+				addrs = append(addrs, d)
+			}
+			for _, a := range addrs {
+				if codegen.IsTemp(a) && !seen[a] {
+					seen[a] = true
+					order = append(order, a)
+				}
+			}
+
+			d, ok := def(in)
+			if !ok || !codegen.IsTemp(d) {
+				continue
+			}
+			for live := range after[pos] {
+				// a copy does not make its source and destination interfere
+				if src, isCopy := in.Arg1.(int); live == d || (in.Op == codegen.OpAssign && isCopy && src == live) {
+					continue
+				}
+				edge(d, live)
+			}
+		}
+
+		// greedily assign each temp the lowest shared address of its type that is free
+		mapping := make(map[int]int)
+		members := make(map[int][]int) // shared address -> temps of this region using it
+		for _, t := range order {
+			chosen := -1
+			for _, rep := range reps {
+				if cg.GetVariableType(rep) != cg.GetVariableType(t) {
+					continue
+				}
+
+				free := true
+				for _, m := range members[rep] {
+					if interferes[t][m] {
+						free = false
+						break
+					}
+				}
+				if free {
+					chosen = rep
+					break
+				}
+			}
+
+			if chosen == -1 {
+				chosen = t
+				reps = append(reps, t)
+				sort.Ints(reps)
+			}
+
+			mapping[t] = chosen
+			members[chosen] = append(members[chosen], t)
+		}
+
+		for _, idx := range r.Index {
+			out[idx] = rewriteOperands(out[idx], func(addr int) int {
+				if m, ok := mapping[addr]; ok {
+					return m
+				}
+				return addr
+			})
+		}
+	}
+
+	// copies between temps that now share an address do nothing
+	return compact(out, func(idx int) bool {
+		in := out[idx]
+		return in.Op != codegen.OpAssign || in.Arg1 != in.Arg3
+	})
+}
@@ -0,0 +1,743 @@
+package optimizer_test
+
+import (
+	"bytes"
+	"dolme/pkg/interpreter"
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"dolme/pkg/parser/codegen"
+	"dolme/pkg/parser/codegen/optimizer"
+	"strings"
+	"testing"
+)
+
+const program = `
+func pow(a: float, b: int): float {
+    let c : float = 1.0;
+    while (b > 0) {
+        c = c * a;
+        b = b - 1;
+    }
+    return c;
+}
+
+func factorial(a: int): float {
+    let c : float = 1.0;
+    while (a > 0) {
+        c = c * a;
+        a = a - 1;
+    }
+    return c;
+}
+
+func sin(x: float): float {
+    let y : float = x;
+    let e : int = 3;
+    let i : int = 1;
+
+    while (i < 50) {
+        y = y + (pow(-1.0, i) * (pow(x, e) / factorial(e)));
+        e = e + 2;
+        i = i + 1;
+    }
+
+    return y;
+}
+
+let b : float = sin(4.4249);
+print(b);
+
+let a : int = 10;
+while (a > 5) {
+    a = a - 1;
+    print(a);
+}
+`
+
+// compile parses src and returns its IR and code generator
+func compile(t *testing.T, src string) ([]codegen.Instruction, *codegen.Codegen) {
+	t.Helper()
+
+	p := parser.NewParser(lexer.NewLexer(src))
+	p.Parse()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("syntax errors: %v", errs)
+	}
+	if errs := p.GetSemanticErrors(); len(errs) > 0 {
+		t.Fatalf("semantic errors: %v", errs)
+	}
+
+	return p.GetIRCode(), p.GetCG()
+}
+
+// run interprets pb and returns everything it printed
+func run(t *testing.T, pb []codegen.Instruction) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	if err := interpreter.NewInterpreter(pb, interpreter.WithWriter(&out), interpreter.WithMaxSteps(1_000_000)).Run(); err != nil {
+		t.Fatalf("interpretation failed: %v", err)
+	}
+
+	return out.String()
+}
+
+// temps returns the number of distinct temporaries referenced by pb
+func temps(pb []codegen.Instruction) int {
+	seen := make(map[int]bool)
+	for _, in := range pb {
+		args := []any{in.Arg1, in.Arg2, in.Arg3}
+		if in.Op == codegen.OpJmp || in.Op == codegen.OpJmpf || in.Op == codegen.OpJmpt {
+			args = args[:1] // Arg3 is a jump target
+		}
+		for _, arg := range args {
+			if addr, ok := arg.(int); ok && codegen.IsTemp(addr) {
+				seen[addr] = true
+			}
+		}
+	}
+
+	return len(seen)
+}
+
+func TestRenumberTemps(t *testing.T) {
+	pb, cg := compile(t, program)
+	want := run(t, pb)
+
+	renumbered := optimizer.RenumberTemps(pb, cg)
+	if got := run(t, renumbered); got != want {
+		t.Errorf("output changed after renumbering:\nwant %q\ngot  %q", want, got)
+	}
+
+	if before, after := temps(pb), temps(renumbered); after >= before {
+		t.Errorf("expected fewer temps after renumbering, before %d, after %d", before, after)
+	}
+}
+
+func TestEliminateCommonSubexpressions(t *testing.T) {
+	src := `
+let x : int = 3;
+let y : int = 4;
+let a : int = (x * y) + (x * y);
+x = x + 1;
+let c : int = x * y;
+let f : float = 2.5;
+let g : float = f * 1.0 + f * 1.0;
+print(a);
+print(c);
+print(g);
+`
+	pb, cg := compile(t, src)
+	want := run(t, pb)
+
+	optimized := optimizer.EliminateCommonSubexpressions(pb, cg)
+	if got := run(t, optimized); got != want {
+		t.Errorf("output changed after CSE:\nwant %q\ngot  %q", want, got)
+	}
+
+	if len(optimized) >= len(pb) {
+		t.Errorf("expected CSE to remove instructions, before %d, after %d", len(pb), len(optimized))
+	}
+
+	// the program also has to survive CSE followed by temp renumbering
+	if got := run(t, optimizer.RenumberTemps(optimized, cg)); got != want {
+		t.Errorf("output changed after CSE and renumbering:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestHoistLoopInvariants(t *testing.T) {
+	src := `
+func scale(n: int, k: int): int {
+    let s : int = 0;
+    while (n > 0) {
+        s = s + k * 3;
+        n = n - 1;
+    }
+    return s;
+}
+
+let x : float = 1.5;
+let i : int = 0;
+let acc : float = 0.0;
+while (i < 4) {
+    let j : int = 0;
+    while (j < 3) {
+        acc = acc + x * 2.0;
+        j = j + 1;
+    }
+    i = i + 1;
+}
+print(acc);
+let r : int = scale(5, 2);
+print(r);
+`
+	pb, cg := compile(t, src)
+	want := run(t, pb)
+
+	hoisted := optimizer.HoistLoopInvariants(pb)
+	if got := run(t, hoisted); got != want {
+		t.Errorf("output changed after hoisting:\nwant %q\ngot  %q", want, got)
+	}
+
+	if len(hoisted) != len(pb) {
+		t.Errorf("hoisting must move instructions, not add or drop them, before %d, after %d", len(pb), len(hoisted))
+	}
+
+	// k * 3 and x * 2.0 now sit in front of the comparisons heading their loops
+	index := func(pb []codegen.Instruction, match func(codegen.Instruction) bool) int {
+		for idx, in := range pb {
+			if match(in) {
+				return idx
+			}
+		}
+		return -1
+	}
+	for _, c := range []struct {
+		name   string
+		inv    func(codegen.Instruction) bool
+		header codegen.Operation
+	}{
+		{"k * 3", func(in codegen.Instruction) bool { return in.Op == codegen.OpMul && in.Type == lexer.INT }, codegen.OpGt},
+		{"x * 2.0", func(in codegen.Instruction) bool { return in.Op == codegen.OpMul && in.Type == lexer.FLOAT }, codegen.OpLt},
+	} {
+		inv := index(hoisted, c.inv)
+		header := index(hoisted, func(in codegen.Instruction) bool { return in.Op == c.header })
+		if inv < 0 || header < 0 || inv > header {
+			t.Errorf("expected %s to be hoisted in front of its loop, found at %d, header at %d", c.name, inv, header)
+		}
+	}
+
+	full := optimizer.RenumberTemps(optimizer.HoistLoopInvariants(optimizer.EliminateCommonSubexpressions(pb, cg)), cg)
+	if got := run(t, full); got != want {
+		t.Errorf("output changed after the full pipeline:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestInlineFunctions(t *testing.T) {
+	src := `
+func clamp(v: int, hi: int): int {
+    if (v > hi) {
+        return hi;
+    }
+    return v;
+}
+
+func half(x: float): float {
+    return x / 2.0;
+}
+
+func fib(n: int): int {
+    if (n < 2) {
+        return n;
+    }
+    let a : int = fib(n - 1);
+    let b : int = fib(n - 2);
+    return a + b;
+}
+
+let i : int = 0;
+while (i < 4) {
+    let c : int = clamp(i * 3, 5);
+    print(c);
+    i = i + 1;
+}
+let h : float = half(5.0);
+print(h);
+let f : int = fib(10);
+print(f);
+`
+	pb, cg := compile(t, src)
+	want := run(t, pb)
+
+	calls := func(pb []codegen.Instruction, name string) int {
+		n := 0
+		for _, in := range pb {
+			if in.Op == codegen.OpCall && in.Arg1 == name {
+				n++
+			}
+		}
+		return n
+	}
+
+	inlined := optimizer.InlineFunctions(pb, cg, optimizer.DefaultInlineThreshold)
+	if got := run(t, inlined); got != want {
+		t.Errorf("output changed after inlining:\nwant %q\ngot  %q", want, got)
+	}
+
+	if n := calls(inlined, "clamp") + calls(inlined, "half"); n != 0 {
+		t.Errorf("expected clamp and half to be inlined, %d calls left", n)
+	}
+	if calls(inlined, "fib") != calls(pb, "fib") {
+		t.Errorf("recursive fib must not be inlined")
+	}
+
+	// a threshold of zero turns the pass off
+	if off := optimizer.InlineFunctions(pb, cg, 0); len(off) != len(pb) {
+		t.Errorf("expected no inlining with a zero threshold, before %d, after %d", len(pb), len(off))
+	}
+
+	full := optimizer.RenumberTemps(optimizer.HoistLoopInvariants(optimizer.EliminateCommonSubexpressions(inlined, cg)), cg)
+	if got := run(t, full); got != want {
+		t.Errorf("output changed after the full pipeline:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestEliminateTailCalls(t *testing.T) {
+	src := `
+func count(n: int, acc: int): int {
+    if (n == 0) {
+        return acc;
+    }
+    return count(n - 1, acc + 1);
+}
+
+func swap(a: int, b: int, n: int): int {
+    if (n == 0) {
+        return a * 10 + b;
+    }
+    return swap(b, a, n - 1);
+}
+
+func total(n: int): int {
+    return count(n, 0);
+}
+
+func parity(n: int): int {
+    return swap(n % 2, 0, 1);
+}
+
+let c : int = count(20000, 0);
+print(c);
+let s : int = swap(1, 2, 3);
+print(s);
+let e : int = total(7);
+print(e);
+let o : int = parity(5);
+print(o);
+`
+	pb, cg := compile(t, src)
+	want := "20000\n21\n7\n1\n"
+	if got := run(t, pb); got != want {
+		t.Fatalf("unexpected output before the pass:\nwant %q\ngot  %q", want, got)
+	}
+
+	optimized := optimizer.EliminateTailCalls(pb, cg)
+	if got := run(t, optimized); got != want {
+		t.Errorf("output changed after eliminating tail calls:\nwant %q\ngot  %q", want, got)
+	}
+
+	calls, tails := 0, 0
+	for _, in := range optimized {
+		switch in.Op {
+		case codegen.OpCall:
+			calls++
+		case codegen.OpTailCall:
+			tails++
+		}
+	}
+	// only the top-level calls remain; total and parity hand their frames over
+	if calls != 4 || tails != 2 {
+		t.Errorf("expected 4 calls and 2 tail calls, got %d and %d", calls, tails)
+	}
+
+	full := optimizer.RenumberTemps(optimizer.HoistLoopInvariants(optimizer.EliminateCommonSubexpressions(optimizer.EliminateTailCalls(optimizer.InlineFunctions(pb, cg, optimizer.DefaultInlineThreshold), cg), cg)), cg)
+	if got := run(t, full); got != want {
+		t.Errorf("output changed after the full pipeline:\nwant %q\ngot  %q", want, got)
+	}
+}
+
+func TestFoldConstantsAndEliminateDeadCode(t *testing.T) {
+	src := `
+let a : int = 9 - -2;
+let f : float = 1.5 * 4.0;
+let unused : int = 7 * 6;
+while (true) {
+    print(a);
+    break;
+}
+if (2 > 3) {
+    print(f);
+}
+print(f);
+`
+	pb, _ := compile(t, src)
+	want := run(t, pb)
+
+	folded := optimizer.FoldConstants(pb)
+	if got := run(t, folded); got != want {
+		t.Errorf("output changed after folding:\nwant %q\ngot  %q", want, got)
+	}
+	for _, in := range folded {
+		if in.Op == codegen.OpSub || in.Op == codegen.OpMul || in.Op == codegen.OpGt || in.Op == codegen.OpJmpf {
+			t.Errorf("expected %s over constants to be folded", in)
+		}
+	}
+
+	cleaned := optimizer.EliminateDeadCode(folded)
+	if got := run(t, cleaned); got != want {
+		t.Errorf("output changed after DCE:\nwant %q\ngot  %q", want, got)
+	}
+	// only the global stores and prints survive, plus the trailing nop
+	if len(cleaned) != 6 {
+		t.Errorf("expected 6 instructions after folding and DCE, got %d: %v", len(cleaned), cleaned)
+	}
+}
+
+func TestEliminateCommonSubexpressionsKeepsWidening(t *testing.T) {
+	src := `
+func half(x: int): float {
+    return x / 2;
+}
+print(half(3));
+`
+	pb, cg := compile(t, src)
+	optimized := optimizer.EliminateCommonSubexpressions(pb, cg)
+	if got := run(t, optimized); got != "1.00000000000000000000\n" {
+		t.Errorf("expected the int result to be returned as a float, got %q", got)
+	}
+}
+
+func TestSimplifyAlgebra(t *testing.T) {
+	src := `
+func arith(x: int): int {
+    let y : int = x * 2 + x * 1 + 0;
+    let m : int = x % 8;
+    let d : int = x / 4;
+    return y + m * 100 + d * 10000;
+}
+
+func scale(f: float): float {
+    let g : float = f * 1.0 - 0.0;
+    return g / 1.0;
+}
+
+func below(x: int): int {
+    let r : int = 0;
+    if (not not x < 3) {
+        r = 1;
+    }
+    return r;
+}
+
+let a : int = arith(-37);
+print(a);
+let b : int = arith(37);
+print(b);
+let c : float = scale(2.5);
+print(c);
+let e : int = below(1);
+print(e);
+`
+	pb, cg := compile(t, src)
+	want := run(t, pb)
+
+	simplified := optimizer.SimplifyAlgebra(pb, cg)
+	if got := run(t, simplified); got != want {
+		t.Errorf("output changed after simplification:\nwant %q\ngot  %q", want, got)
+	}
+	// the inner not of the double negation is left unread
+	simplified = optimizer.EliminateDeadCode(simplified)
+
+	shifts := 0
+	for _, in := range simplified {
+		switch in.Op {
+		case codegen.OpMul:
+			// only the multiplies by 100 and 10000 are left
+			if in.Type != lexer.INT {
+				t.Errorf("expected %s to be simplified", in)
+			}
+		case codegen.OpDiv, codegen.OpMod, codegen.OpNot:
+			t.Errorf("expected %s to be simplified", in)
+		case codegen.OpShl, codegen.OpShr, codegen.OpUShr:
+			shifts++
+		}
+	}
+	// one for x * 2, four for x % 8 and three for x / 4
+	if shifts != 8 {
+		t.Errorf("expected 8 shifts, got %d", shifts)
+	}
+}
+
+func TestEvaluatePureCalls(t *testing.T) {
+	pb, _ := compile(t, program)
+	want := run(t, pb)
+
+	evaluated := optimizer.EvaluatePureCalls(pb, optimizer.DefaultEvalSteps)
+	if got := run(t, evaluated); got != want {
+		t.Errorf("output changed after evaluating calls:\nwant %q\ngot  %q", want, got)
+	}
+	for _, r := range optimizer.Regions(evaluated)[:1] {
+		for _, idx := range r.Index {
+			if in := evaluated[idx]; in.Op == codegen.OpCall || in.Op == codegen.OpArg {
+				t.Errorf("expected the top-level call to sin to be evaluated, found %s", in)
+			}
+		}
+	}
+
+	src := `
+let g : int = 1;
+
+func noisy(x: int): int {
+    print(x);
+    return x;
+}
+
+func shared(x: int): int {
+    return x + g;
+}
+
+func spin(x: int): int {
+    while (x > 0) {
+        x = x + 1;
+    }
+    return x;
+}
+
+func square(x: int): int {
+    return x * x;
+}
+
+let a : int = noisy(3);
+let b : int = shared(4);
+g = 5;
+let c : int = shared(4);
+let d : int = square(7);
+print(b);
+print(c);
+print(d);
+let e : int = spin(1);
+`
+	pb, _ = compile(t, src)
+	evaluated = optimizer.EvaluatePureCalls(pb, 1000)
+
+	calls := make(map[string]int)
+	for _, in := range evaluated {
+		if in.Op == codegen.OpCall {
+			calls[in.Arg1.(string)]++
+		}
+	}
+	// printing, reading globals and running out of steps all keep the call
+	if calls["noisy"] != 1 || calls["shared"] != 2 || calls["spin"] != 1 || calls["square"] != 0 {
+		t.Errorf("unexpected calls left: %v", calls)
+	}
+}
+
+func TestCallGraph(t *testing.T) {
+	// even and odd call each other, which the language cannot express without forward declarations
+	pb := []codegen.Instruction{
+		{Op: codegen.OpLabel, Arg1: "even"},
+		{Op: codegen.OpParam, Arg1: 800, Arg2: 0, Type: lexer.INT},
+		{Op: codegen.OpArg, Arg1: 800, Arg2: 0, Type: lexer.INT},
+		{Op: codegen.OpCall, Arg1: "odd", Arg2: 1, Arg3: 801, Type: lexer.BOOL},
+		{Op: codegen.OpRet, Arg1: 801, Type: lexer.BOOL},
+		{Op: codegen.OpEnd},
+		{Op: codegen.OpLabel, Arg1: "odd"},
+		{Op: codegen.OpParam, Arg1: 800, Arg2: 0, Type: lexer.INT},
+		{Op: codegen.OpArg, Arg1: 800, Arg2: 0, Type: lexer.INT},
+		{Op: codegen.OpCall, Arg1: "even", Arg2: 1, Arg3: 801, Type: lexer.BOOL},
+		{Op: codegen.OpRet, Arg1: 801, Type: lexer.BOOL},
+		{Op: codegen.OpEnd},
+		{Op: codegen.OpLabel, Arg1: "loop"},
+		{Op: codegen.OpTailCall, Arg1: "loop", Arg2: 0},
+		{Op: codegen.OpEnd},
+		{Op: codegen.OpArg, Arg1: "#4", Arg2: 0},
+		{Op: codegen.OpCall, Arg1: "even", Arg2: 1, Arg3: 400, Type: lexer.BOOL},
+		{Op: codegen.OpNop},
+	}
+
+	g := optimizer.BuildCallGraph(pb)
+	cycles := g.Cycles()
+	if len(cycles) != 2 || len(cycles[0]) != 2 || cycles[0][0] != "even" || cycles[0][1] != "odd" || len(cycles[1]) != 1 || cycles[1][0] != "loop" {
+		t.Errorf("expected cycles [even odd] and [loop], got %v", cycles)
+	}
+	if reachable := g.Reachable(); !reachable["odd"] || reachable["loop"] {
+		t.Errorf("expected odd reachable and loop not, got %v", reachable)
+	}
+	if dot := g.DOT(); !strings.Contains(dot, `"even" -> "odd" [color=red];`) {
+		t.Errorf("expected the mutual recursion to be highlighted:\n%s", dot)
+	}
+
+	pruned := optimizer.EliminateDeadFunctions(pb)
+	if len(pruned) != len(pb)-3 {
+		t.Errorf("expected the body of loop to be removed, got %v", pruned)
+	}
+	for _, r := range optimizer.Regions(pruned)[1:] {
+		if r.Name == "loop" {
+			t.Errorf("expected loop to be removed")
+		}
+	}
+
+	src := `
+func helper(x: int): int {
+    return x + 1;
+}
+
+func unused(x: int): int {
+    return helper(x) * 2;
+}
+
+let a : int = helper(1);
+print(a);
+`
+	pb, _ = compile(t, src)
+	want := run(t, pb)
+	pruned = optimizer.EliminateDeadFunctions(pb)
+	if got := run(t, pruned); got != want {
+		t.Errorf("output changed after removing dead functions:\nwant %q\ngot  %q", want, got)
+	}
+	if regions := optimizer.Regions(pruned); len(regions) != 2 || regions[1].Name != "helper" {
+		t.Errorf("expected only helper to survive, got %v", regions)
+	}
+}
+
+func TestManager(t *testing.T) {
+	if _, err := optimizer.ParsePipeline("constfold,nope"); err == nil {
+		t.Errorf("expected an error for an unknown pass")
+	}
+	if _, err := optimizer.Preset(3); err == nil {
+		t.Errorf("expected an error for an unknown optimization level")
+	}
+
+	for level := 0; level <= 2; level++ {
+		pb, cg := compile(t, program)
+		want := run(t, pb)
+
+		passes, err := optimizer.Preset(level)
+		if err != nil {
+			t.Fatalf("preset %d: %v", level, err)
+		}
+
+		dumped := make([]string, 0)
+		pm := optimizer.NewManager(cg, passes, optimizer.WithPrintAfter([]string{"dce"}, func(pass string, _ []codegen.Instruction) {
+			dumped = append(dumped, pass)
+		}))
+		if got := run(t, pm.Run(pb)); got != want {
+			t.Errorf("-O%d changed the output:\nwant %q\ngot  %q", level, want, got)
+		}
+
+		stats := pm.Stats()
+		if len(stats) != len(passes) {
+			t.Errorf("-O%d: expected %d stats, got %d", level, len(passes), len(stats))
+		}
+		for i := 1; i < len(stats); i++ {
+			if stats[i].Before != stats[i-1].After {
+				t.Errorf("-O%d: %s starts from %d instructions but %s left %d", level, stats[i].Pass, stats[i].Before, stats[i-1].Pass, stats[i-1].After)
+			}
+		}
+		if level > 0 && len(dumped) != 1 {
+			t.Errorf("-O%d: expected one dump after dce, got %v", level, dumped)
+		}
+	}
+}
+
+func TestEliminateCommonSubexpressionsKeepsArrayStores(t *testing.T) {
+	// ys aliases xs, so the store between the two loads of xs[0] changes it
+	src := `
+let xs : [2]int = [1, 2];
+let ys : [2]int = xs;
+let a : int = xs[0];
+ys[0] = 5;
+let b : int = xs[0];
+print(a + b);
+`
+	pb, cg := compile(t, src)
+	optimized := optimizer.EliminateDeadCode(optimizer.EliminateCommonSubexpressions(pb, cg))
+	if got := run(t, optimized); got != "6\n" {
+		t.Errorf("expected the second load to see the store, got %q", got)
+	}
+
+	ops := make(map[codegen.Operation]int)
+	for _, in := range optimized {
+		ops[in.Op]++
+	}
+	// two stores fill the literal and one goes through ys
+	if ops[codegen.OpIndexStore] != 3 || ops[codegen.OpIndexLoad] != 2 {
+		t.Errorf("expected 3 stores and 2 loads, got %d and %d", ops[codegen.OpIndexStore], ops[codegen.OpIndexLoad])
+	}
+}
+
+func TestEliminateCommonSubexpressionsKeepsAppends(t *testing.T) {
+	// the append between the two lengths of xs changes it
+	src := `
+let xs : list[int] = [1];
+let a : int = len(xs);
+append(xs, 2);
+let b : int = len(xs);
+print(a + b);
+`
+	pb, cg := compile(t, src)
+	optimized := optimizer.EliminateDeadCode(optimizer.EliminateCommonSubexpressions(pb, cg))
+	if got := run(t, optimized); got != "3\n" {
+		t.Errorf("expected the second length to see the append, got %q", got)
+	}
+
+	ops := make(map[codegen.Operation]int)
+	for _, in := range optimized {
+		ops[in.Op]++
+	}
+	// one append fills the literal
+	if ops[codegen.OpAppend] != 2 || ops[codegen.OpListLen] != 2 {
+		t.Errorf("expected 2 appends and 2 lengths, got %d and %d", ops[codegen.OpAppend], ops[codegen.OpListLen])
+	}
+}
+
+func TestEliminateCommonSubexpressionsKeepsFieldStores(t *testing.T) {
+	// q aliases p, so the store between the two loads of p.x changes it
+	src := `
+struct P { x: int }
+let p : P = P { x: 1 };
+let q : P = p;
+let a : int = p.x;
+q.x = 5;
+let b : int = p.x;
+print(a + b);
+`
+	pb, cg := compile(t, src)
+	optimized := optimizer.EliminateDeadCode(optimizer.EliminateCommonSubexpressions(pb, cg))
+	if got := run(t, optimized); got != "6\n" {
+		t.Errorf("expected the second load to see the store, got %q", got)
+	}
+
+	ops := make(map[codegen.Operation]int)
+	for _, in := range optimized {
+		ops[in.Op]++
+	}
+	// one store fills the literal and one goes through q
+	if ops[codegen.OpFieldStore] != 2 || ops[codegen.OpFieldLoad] != 2 {
+		t.Errorf("expected 2 stores and 2 loads, got %d and %d", ops[codegen.OpFieldStore], ops[codegen.OpFieldLoad])
+	}
+}
+
+func TestFoldConstantsKeepsMatchArms(t *testing.T) {
+	// d is only known at run time, so every arm keeps its test
+	src := `
+enum Dir { Up, Down, Stay }
+func step(d: Dir): int {
+    let pos : int = 0;
+    match (d) {
+        Up => { pos = 2; }
+        Down => { pos = 0 - 1; }
+        Stay => { pos = 5; }
+    }
+    return pos;
+}
+print(step(Up));
+print(step(Down));
+print(step(Stay));
+`
+	pb, _ := compile(t, src)
+	optimized := optimizer.EliminateDeadCode(optimizer.FoldConstants(pb))
+	if got := run(t, optimized); got != "2\n-1\n5\n" {
+		t.Errorf("expected every arm to run for its variant, got %q", got)
+	}
+
+	tests := 0
+	for _, in := range optimized {
+		if in.Op == codegen.OpEq {
+			tests++
+		}
+	}
+	if tests != 3 {
+		t.Errorf("expected 3 arm tests, got %d", tests)
+	}
+}
@@ -0,0 +1,176 @@
+package codegen
+
+import (
+	"dolme/pkg/lexer"
+	"fmt"
+	"html/template"
+	"reflect"
+	"strings"
+)
+
+// SDTStep is one semantic action run by the code generator
+type SDTStep struct {
+	Action  string      // semantic action, e.g. @save
+	Token   lexer.Token // current token when the action ran
+	Before  []string    // semantic stack before the action, bottom first
+	After   []string    // semantic stack after the action
+	Changes []SDTChange // instructions appended to or rewritten in the program block
+}
+
+// SDTChange is an instruction written by a semantic action
+type SDTChange struct {
+	Index int          // position in the program block
+	Old   *Instruction // instruction that was there before, nil when appended
+	New   Instruction
+}
+
+// Backpatch tells whether the change filled in a placeholder left by @save
+// or @save_break, i.e. a jump whose target was not known when it was emitted
+func (ch SDTChange) Backpatch() bool {
+	return ch.Old != nil && ch.Old.Op == OpNop
+}
+
+// SDTTrace is the list of semantic actions run during a translation
+type SDTTrace []SDTStep
+
+// TraceSDT records every semantic action run from now on in t
+func (c *Codegen) TraceSDT(t *SDTTrace) {
+	c.sdt = t
+}
+
+// traceAction runs action and records what it did to ss and pb when tracing
+func (c *Codegen) traceAction(name string, action func()) {
+	if c.sdt == nil {
+		action()
+		return
+	}
+
+	before := append([]string{}, c.ss.Array()...)
+	pb := append([]Instruction{}, c.pb...)
+	action()
+
+	s := SDTStep{Action: name, Token: c.currentToken, Before: before, After: append([]string{}, c.ss.Array()...)}
+	for i, instr := range c.pb {
+		switch {
+		case i >= len(pb):
+			s.Changes = append(s.Changes, SDTChange{Index: i, New: instr})
+		case !reflect.DeepEqual(pb[i], instr):
+			s.Changes = append(s.Changes, SDTChange{Index: i, Old: &pb[i], New: instr})
+		}
+	}
+	*c.sdt = append(*c.sdt, s)
+}
+
+// token renders the current token of a step
+func (s SDTStep) token() string {
+	if s.Token.Type == lexer.EOF && s.Token.Lexeme == "" {
+		return "$"
+	}
+	return fmt.Sprintf("%s '%s' %d:%d", s.Token.Type, s.Token.Lexeme, s.Token.Pos.Line, s.Token.Pos.Column)
+}
+
+// semanticStack renders a semantic stack, [] when empty
+func semanticStack(ss []string) string {
+	return "[" + strings.Join(ss, " ") + "]"
+}
+
+// Text renders the trace with one block per action: the stack before and
+// after, then one line per instruction, `+` when appended and `~` when
+// rewritten, with backpatches marked
+func (t SDTTrace) Text() string {
+	var b strings.Builder
+	for i, s := range t {
+		fmt.Fprintf(&b, "%d\t%s\ttoken %s\n", i+1, s.Action, s.token())
+		fmt.Fprintf(&b, "\tss %s → %s\n", semanticStack(s.Before), semanticStack(s.After))
+		for _, ch := range s.Changes {
+			switch {
+			case ch.Old == nil:
+				fmt.Fprintf(&b, "\t+ %d: %s\n", ch.Index, ch.New)
+			case ch.Backpatch():
+				fmt.Fprintf(&b, "\t~ %d: %s ⇒ %s  <- backpatch\n", ch.Index, *ch.Old, ch.New)
+			default:
+				fmt.Fprintf(&b, "\t~ %d: %s ⇒ %s\n", ch.Index, *ch.Old, ch.New)
+			}
+		}
+	}
+
+	return b.String()
+}
+
+// sdtRow is an action in the HTML trace
+type sdtRow struct {
+	Step    int
+	Action  string
+	Token   string
+	Before  []string
+	After   []string
+	Changes []sdtChange
+}
+
+// sdtChange is an instruction in the HTML trace; Class is append, rewrite or backpatch
+type sdtChange struct {
+	Class string
+	Text  string
+}
+
+// sdtPage lays out the HTML trace as a table, one row per action, with
+// backpatched instructions and the loop markers on the stack highlighted
+var sdtPage = template.Must(template.New("sdt").Funcs(template.FuncMap{
+	"marker": func(v string) bool {
+		return strings.HasPrefix(v, "$while_") || strings.HasPrefix(v, "$break_")
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Syntax-directed translation trace</title>
+<style>
+body { font-family: monospace; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #ccc; padding: 2px 6px; vertical-align: top; text-align: left; }
+.ss span { display: inline-block; border: 1px solid #999; margin: 1px; padding: 0 3px; }
+.ss span.marker { background: #e0ecff; }
+.append { color: #060; }
+.rewrite { color: #a60; }
+.backpatch { background: #ffe08a; font-weight: bold; }
+</style>
+</head>
+<body>
+<table>
+<tr><th>#</th><th>action</th><th>token</th><th>ss before</th><th>ss after</th><th>pb</th></tr>
+{{range .}}<tr>
+<td>{{.Step}}</td><td>{{.Action}}</td><td>{{.Token}}</td>
+<td class="ss">{{range .Before}}<span{{if marker .}} class="marker"{{end}}>{{.}}</span>{{end}}</td>
+<td class="ss">{{range .After}}<span{{if marker .}} class="marker"{{end}}>{{.}}</span>{{end}}</td>
+<td>{{range .Changes}}<div class="{{.Class}}">{{.Text}}</div>{{end}}</td>
+</tr>
+{{end}}</table>
+</body>
+</html>
+`))
+
+// HTML renders the trace as a standalone page
+func (t SDTTrace) HTML() (string, error) {
+	rows := make([]sdtRow, 0, len(t))
+	for i, s := range t {
+		row := sdtRow{Step: i + 1, Action: s.Action, Token: s.token(), Before: s.Before, After: s.After}
+		for _, ch := range s.Changes {
+			switch {
+			case ch.Old == nil:
+				row.Changes = append(row.Changes, sdtChange{"append", fmt.Sprintf("+ %d: %s", ch.Index, ch.New)})
+			case ch.Backpatch():
+				row.Changes = append(row.Changes, sdtChange{"backpatch", fmt.Sprintf("~ %d: %s ⇒ %s (backpatch)", ch.Index, *ch.Old, ch.New)})
+			default:
+				row.Changes = append(row.Changes, sdtChange{"rewrite", fmt.Sprintf("~ %d: %s ⇒ %s", ch.Index, *ch.Old, ch.New)})
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	var b strings.Builder
+	if err := sdtPage.Execute(&b, rows); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
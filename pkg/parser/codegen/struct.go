@@ -0,0 +1,297 @@
+package codegen
+
+import (
+	"dolme/pkg/lexer"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// fieldSize is the size of every field, one 8-byte word
+const fieldSize = 8
+
+// StructType is a struct declared as `struct Point { x: float, y: float }`
+type StructType struct {
+	Name   string
+	Fields []Field // in declaration order
+}
+
+// Field is a field of a struct, Offset bytes from the start of the struct
+type Field struct {
+	Name   string
+	Type   fmt.Stringer // a ListType, a *StructType or a scalar type table entry
+	Offset int
+}
+
+// String renders the type by its name
+func (s *StructType) String() string {
+	return s.Name
+}
+
+// Size returns the bytes taken by the fields of the struct
+func (s *StructType) Size() int {
+	return len(s.Fields) * fieldSize
+}
+
+// FieldByName returns the field called name
+func (s *StructType) FieldByName(name string) (Field, bool) {
+	for _, f := range s.Fields {
+		if f.Name == name {
+			return f, true
+		}
+	}
+	return Field{}, false
+}
+
+// checkStructType reports an error unless name is a declared struct, and returns it
+func (c *Codegen) checkStructType(name string) (*StructType, bool) {
+	s, ok := c.structTypes[name]
+	if !ok {
+		c.addUnknownTypeError(name, c.currentToken.Pos)
+	}
+	return s, ok
+}
+
+// checkNamedType reports an error unless name is a declared struct or enum, and returns it
+func (c *Codegen) checkNamedType(name string) (fmt.Stringer, bool) {
+	if e, ok := c.enumTypes[name]; ok {
+		return e, true
+	}
+	return c.checkStructType(name)
+}
+
+// structDeclAction starts the declaration of a struct type
+func (c *Codegen) structDeclAction() {
+	name := c.currentToken.Lexeme
+	if _, ok := c.structTypes[name]; ok {
+		c.addTypeRedeclarationError(name, c.currentToken.Pos)
+	} else if _, ok := c.enumTypes[name]; ok {
+		c.addTypeRedeclarationError(name, c.currentToken.Pos)
+	}
+	c.checkNotVariant("struct", name)
+	c.structDecl = &StructType{Name: name}
+}
+
+// captureFieldNameAction stores the name of a field, declared or given a value in a struct literal
+func (c *Codegen) captureFieldNameAction() {
+	c.pushString(c.currentToken.Lexeme)
+}
+
+// structFieldAction adds a field to the struct being declared
+func (c *Codegen) structFieldAction() {
+	if c.ss.Size() >= 2 {
+		typ := c.topString()
+		name := c.topStringMinus(1)
+		c.pop(2)
+
+		s := c.structDecl
+		if s == nil {
+			return
+		}
+		if _, ok := s.FieldByName(name); ok {
+			c.addDuplicateFieldError(name, s.Name, c.currentToken.Pos)
+			return
+		}
+
+		t, full := c.parseType(typ)
+		if t == lexer.LSBRACE {
+			c.addArrayFieldError(name, s.Name, c.currentToken.Pos)
+			full = lexer.INT
+		}
+		s.Fields = append(s.Fields, Field{Name: name, Type: full, Offset: s.Size()})
+	}
+}
+
+// structEndAction makes the declared struct type usable
+func (c *Codegen) structEndAction() {
+	if s := c.structDecl; s != nil {
+		c.structTypes[s.Name] = s
+		c.structDecl = nil
+	}
+}
+
+// field returns the field name of the struct at base, reporting an error when there is none
+func (c *Codegen) field(base int, name string) (Field, bool) {
+	if s, ok := c.structs[base]; ok {
+		if f, ok := s.FieldByName(name); ok {
+			return f, true
+		}
+	}
+	c.addNoFieldError(name, c.typeOf(base), c.currentToken.Pos)
+	return Field{}, false
+}
+
+// fieldAction generates code to read a field of the struct on top of the stack
+func (c *Codegen) fieldAction() {
+	if c.ss.Size() >= 1 {
+		base := c.top()
+		c.pop(1)
+
+		t := c.getTemp()
+		f, ok := c.field(base, c.currentToken.Lexeme)
+		if !ok {
+			// an int after an error, so that it is not reported again
+			c.setVariableType(t, lexer.INT)
+			c.push(t)
+			return
+		}
+		c.setType(t, f.Type)
+
+		c.pb = append(c.pb, Instruction{Op: OpFieldLoad, Arg1: base, Arg2: "#" + strconv.Itoa(f.Offset), Arg3: t, Type: entry(f.Type)})
+		c.push(t)
+		c.i++
+	}
+}
+
+// fieldTargetAction stores the name of the field an assignment writes
+func (c *Codegen) fieldTargetAction() {
+	c.pushString(c.currentToken.Lexeme)
+}
+
+// fieldAssignAction generates code to write a field of a struct
+func (c *Codegen) fieldAssignAction() {
+	if c.ss.Size() >= 3 {
+		value := c.top()
+		name := c.topStringMinus(1)
+		base := c.topMinus(2)
+		c.pop(3)
+
+		if f, ok := c.field(base, name); ok {
+			c.storeField(base, f, value)
+		}
+	}
+}
+
+// storeField generates code to write value to the field f of the struct at base
+func (c *Codegen) storeField(base int, f Field, value any) {
+	if addr, ok := value.(int); ok && !c.fitsType(f.Type, addr) {
+		c.addTypeMismatchError(f.Type, c.typeOf(addr), c.currentToken.Pos)
+	}
+	c.pb = append(c.pb, Instruction{Op: OpFieldStore, Arg1: value, Arg2: "#" + strconv.Itoa(f.Offset), Arg3: base, Type: entry(f.Type)})
+	c.i++
+}
+
+// structLitStartAction marks where the fields of a struct literal start on the stack
+func (c *Codegen) structLitStartAction() {
+	c.checkStructType(c.currentToken.Lexeme)
+	c.pushString("$struct:" + c.currentToken.Lexeme)
+}
+
+// structLitAction generates code for a struct literal
+func (c *Codegen) structLitAction() {
+	n := 0
+	for n < c.ss.Size() && !strings.HasPrefix(c.topStringMinus(n), "$struct:") {
+		n++
+	}
+	if n == c.ss.Size() {
+		return
+	}
+
+	name := strings.TrimPrefix(c.topStringMinus(n), "$struct:")
+	s, ok := c.structTypes[name]
+	values := make(map[string]int)
+	for k := n - 1; k > 0; k -= 2 {
+		field, value := c.topStringMinus(k), c.topMinus(k-1)
+		if _, dup := values[field]; ok && dup {
+			c.addDuplicateFieldError(field, name, c.currentToken.Pos)
+		} else if ok {
+			if _, known := s.FieldByName(field); !known {
+				c.addNoFieldError(field, s, c.currentToken.Pos)
+			}
+		}
+		values[field] = value
+	}
+	c.pop(n + 1)
+
+	if !ok {
+		// unknown, already reported; an int so that it is not reported again
+		t := c.getTemp()
+		c.setVariableType(t, lexer.INT)
+		c.push(t)
+		return
+	}
+	c.push(c.newStruct(s, values))
+}
+
+// newStruct generates code for a new struct of type s with the given field values and returns its temp
+func (c *Codegen) newStruct(s *StructType, values map[string]int) int {
+	t := c.getTemp()
+	c.setType(t, s)
+
+	c.pb = append(c.pb, Instruction{Op: OpStruct, Arg1: "#" + strconv.Itoa(s.Size()), Arg2: nil, Arg3: t, Type: lexer.STRUCT})
+	c.i++
+	for _, f := range s.Fields {
+		if v, ok := values[f.Name]; ok {
+			c.storeField(t, f, v)
+		} else {
+			c.storeField(t, f, c.zeroValue(f.Type))
+		}
+	}
+	return t
+}
+
+// zeroValue returns the value a field of type full starts with
+func (c *Codegen) zeroValue(full fmt.Stringer) any {
+	switch full := full.(type) {
+	case ListType:
+		t := c.getTemp()
+		c.setType(t, full)
+		c.pb = append(c.pb, Instruction{Op: OpList, Arg1: nil, Arg2: nil, Arg3: t, Type: full.Elem})
+		c.i++
+		return t
+	case *StructType:
+		return c.newStruct(full, nil)
+	case lexer.TokenType:
+		switch full {
+		case lexer.FLOAT:
+			return "#0.0"
+		case lexer.BOOL:
+			return "#false"
+		case lexer.STR:
+			return `#""`
+		}
+	}
+	return "#0"
+}
+
+// methodName returns the name the method name of the type typ is compiled to
+func methodName(typ fmt.Stringer, name string) string {
+	return typ.String() + "." + name
+}
+
+// methodStartAction handles the start of a method definition
+func (c *Codegen) methodStartAction() {
+	if c.ss.Size() >= 2 {
+		typ := c.topString()
+		recv := c.topStringMinus(1)
+		c.pop(2)
+
+		t, full := c.parseType(typ)
+		if t != lexer.STRUCT {
+			c.addReceiverTypeError(c.currentToken.Lexeme, full, c.currentToken.Pos)
+		}
+
+		c.startFunction(methodName(full, c.currentToken.Lexeme))
+		c.pushString(recv)
+		c.pushString(typ)
+		c.paramAction()
+	}
+}
+
+// methodCallAction starts a call of the method named by the current token on the value on top of the stack
+func (c *Codegen) methodCallAction() {
+	if c.ss.Size() >= 1 {
+		recv := c.top()
+		c.pop(1)
+
+		typ := c.typeOf(recv)
+		funcName := methodName(typ, c.currentToken.Lexeme)
+		if _, ok := c.functionReturns[funcName]; !ok {
+			c.addNoMethodError(c.currentToken.Lexeme, typ, c.currentToken.Pos)
+		}
+
+		c.pushString(funcName)
+		c.push(recv)
+		c.argAction()
+	}
+}
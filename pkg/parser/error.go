@@ -4,6 +4,7 @@ import (
 	"dolme/pkg/color"
 	"dolme/pkg/lexer"
 	"fmt"
+	"strings"
 )
 
 // handleTerminalError is called when a terminal on the stack doesn't match current token.
@@ -32,7 +33,7 @@ func (p *Parser) handleTerminalError(expected string) bool {
 func (p *Parser) handleNonTerminalError(expected string) bool {
 	// Special: ArgList followed by a boundary like ';' (e.g., id '(' ; )
 	// This likely means a missing ')'. Emit that once.
-	if expected == "ArgList" {
+	if expected == "ArgList" || expected == "ArgList'" {
 		if p.currentToken.Type == lexer.SEMICOLON ||
 			p.currentToken.Type == lexer.RBRACE ||
 			p.isStatementBoundary(p.currentToken.Type) {
@@ -41,6 +42,12 @@ func (p *Parser) handleNonTerminalError(expected string) bool {
 		}
 	}
 
+	// A block still open when the input ends
+	if expected == "StmtList" && p.currentToken.Type == lexer.EOF {
+		p.addError("Missing closing brace")
+		return false
+	}
+
 	// Empty condition: if () or while()
 	if expected == "Cond" && p.currentToken.Type == lexer.RPAREN {
 		p.addError("Empty condition")
@@ -64,13 +71,78 @@ func (p *Parser) handleUnexpectedEndOfInput() {
 	p.addError(fmt.Sprintf("Unexpected token '%s' at end of input", p.currentToken.Type))
 }
 
-// addError records a parsing error with location
+// addError records a parsing error with location. Errors reported while
+// recovering from the previous one are cascades of it and dropped.
 func (p *Parser) addError(msg string) {
+	p.lastError, p.lastSuppressed = msg, p.recovering
+	if p.recovering {
+		return
+	}
+	p.recovering = true
+
 	pos := p.currentToken.Pos
 	formatted := color.RedText(msg) + " at " + color.YellowText(fmt.Sprintf("Line: %d, Column %d", pos.Line, pos.Column))
 	p.errors = append(p.errors, formatted)
 }
 
+// synchronize resumes parsing after an error on top, which has already been
+// popped. Tokens are skipped until one lets top continue (top is pushed
+// back), one follows the non-terminal top (top is abandoned), or one starts
+// or ends a statement (the stack, top included, is unwound to a symbol that
+// accepts it).
+func (p *Parser) synchronize(top string) {
+	nonTerminal := !p.isTerminal(top)
+	for {
+		tok := p.currentToken.Type
+		if nonTerminal {
+			if _, ok := p.table[top][tok]; ok {
+				p.push(top, p.popped)
+				return
+			}
+			if p.follow[top][symbols[tok]] {
+				return
+			}
+		} else if p.matchTerminal(top) {
+			p.push(top, p.popped)
+			return
+		}
+
+		if p.isStatementBoundary(tok) {
+			if nonTerminal {
+				p.push(top, p.popped)
+			}
+			p.unwind()
+			return
+		}
+		p.nextToken()
+	}
+}
+
+// unwind pops the stack until its top can continue with the current token:
+// a terminal that matches it or a non-terminal with a table entry for it.
+// Statement lists are kept and skip the token instead, until the input ends.
+func (p *Parser) unwind() {
+	for p.stack.Size() > 1 {
+		top := p.stack.Peek()
+		switch {
+		case p.isSemanticAction(top):
+		case p.isTerminal(top):
+			if p.matchTerminal(top) {
+				return
+			}
+		default:
+			if _, ok := p.table[top][p.currentToken.Type]; ok {
+				return
+			}
+			if (top == "StmtList" || top == "DeclList" || top == "Program") && p.currentToken.Type != lexer.EOF {
+				p.nextToken()
+				continue
+			}
+		}
+		p.pop()
+	}
+}
+
 // Errors returns the list of parsing errors
 func (p *Parser) Errors() []string {
 	return p.errors
@@ -79,7 +151,8 @@ func (p *Parser) Errors() []string {
 // isExpressionTail checks if the non-terminal is part of an expression
 func (p *Parser) isExpressionTail(sym string) bool {
 	switch sym {
-	case "Expr", "Expr'", "Term", "Term'", "Factor", "FactorSuffix":
+	case "Expr", "OrExpr'", "AndExpr", "AndExpr'", "NotExpr", "RelExpr", "RelExpr'",
+		"ArithExpr", "ArithExpr'", "Term", "Term'", "Unary", "Factor", "FactorSuffix":
 		return true
 	default:
 		return false
@@ -104,10 +177,21 @@ func (p *Parser) addContextualError(expected string) {
 
 // categorizeError provides a specific error message based on expected symbol and current token
 func (p *Parser) categorizeError(expected string, current lexer.Token) string {
+	// The lexer rejects a string literal with an unknown escape as a whole,
+	// and the opening quote of one without a closing quote on its own
+	if current.Type == lexer.ILLEGAL && current.Lexeme == `"` {
+		return "Unterminated string"
+	}
+	if current.Type == lexer.ILLEGAL && strings.HasPrefix(current.Lexeme, `"`) {
+		return "Invalid escape sequence in string"
+	}
+
 	// Delimiters
 	switch expected {
 	case ")":
 		return "Missing closing parenthesis"
+	case "]":
+		return "Missing closing bracket"
 	case "}":
 		return "Missing closing brace"
 	case "{":
@@ -135,7 +219,7 @@ func (p *Parser) categorizeError(expected string, current lexer.Token) string {
 		return "Expected identifier"
 	case "num":
 		return "Expected number"
-	case "string":
+	case "strlit":
 		if current.Type == lexer.ID {
 			return "Missing quotes around string"
 		}
@@ -143,8 +227,8 @@ func (p *Parser) categorizeError(expected string, current lexer.Token) string {
 	}
 
 	// Expressions missing before ) or ; or relation
-	if (expected == "Expr" || expected == "Term" || expected == "Factor") &&
-		(current.Type == lexer.SEMICOLON || current.Type == lexer.RPAREN) {
+	if (expected == "Expr" || expected == "Term" || expected == "Unary" || expected == "Factor") &&
+		(current.Type == lexer.SEMICOLON || current.Type == lexer.RPAREN || current.Type == lexer.RSBRACE) {
 		return "Missing expression"
 	}
 
@@ -165,7 +249,8 @@ func (p *Parser) isReservedKeywordAsId(current lexer.Token) bool {
 		lexer.LET: true, lexer.FUNC: true, lexer.RETURN: true, lexer.IF: true, lexer.ELSE: true,
 		lexer.WHILE: true, lexer.BREAK: true, lexer.CONTINUE: true, lexer.PRINT: true,
 		lexer.TRUE: true, lexer.FALSE: true, lexer.AND: true, lexer.OR: true, lexer.NOT: true,
-		lexer.INT: true, lexer.FLOAT: true, lexer.BOOL: true,
+		lexer.INT: true, lexer.FLOAT: true, lexer.BOOL: true, lexer.STR: true, lexer.LEN: true,
+		lexer.LIST: true, lexer.APPEND: true, lexer.STRUCT: true, lexer.ENUM: true, lexer.MATCH: true,
 	}
 	return reserved[current.Type]
 }
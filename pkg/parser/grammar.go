@@ -0,0 +1,267 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// GrammarDoc describes the grammar with everything derived from it: the
+// numbered productions, FIRST and FOLLOW sets and the LL(1) table. Its
+// Markdown rendering is the committed dolme.md.
+type GrammarDoc struct {
+	Productions  []Production // production i at index i, index 0 unused
+	NonTerminals []string     // in order of first definition
+	Terminals    []string     // in order of first use, $ last
+	First        map[string]SymbolSet
+	Follow       map[string]SymbolSet
+	Table        ParsingTable
+}
+
+// NewGrammarDoc computes the description of the language grammar
+func NewGrammarDoc() *GrammarDoc {
+	d := &GrammarDoc{
+		Productions: grammer,
+		First:       FirstSets(grammer),
+		Table:       NewParsingTable(),
+	}
+	d.Follow = FollowSets(grammer, d.First)
+
+	seen := make(map[string]bool)
+	for _, p := range grammer {
+		if p.LHS != "" && !seen[p.LHS] {
+			seen[p.LHS] = true
+			d.NonTerminals = append(d.NonTerminals, p.LHS)
+		}
+	}
+	for _, p := range grammer {
+		for _, sym := range p.Symbols() {
+			if _, ok := terminals[sym]; ok && !seen[sym] {
+				seen[sym] = true
+				d.Terminals = append(d.Terminals, sym)
+			}
+		}
+	}
+	d.Terminals = append(d.Terminals, endMarker)
+
+	return d
+}
+
+// order lists the members of s as terminals in grammar order, with ε last
+func (d *GrammarDoc) order(s SymbolSet) []string {
+	out := make([]string, 0, len(s))
+	for _, t := range d.Terminals {
+		if s[t] {
+			out = append(out, t)
+		}
+	}
+	if s[epsilon] {
+		out = append(out, epsilon)
+	}
+
+	return out
+}
+
+// entries returns, for each production of nt in the table, the terminals
+// that select it in grammar order, and the production numbers in order
+func (d *GrammarDoc) entries(nt string) (map[int][]string, []int) {
+	byProd := make(map[int][]string)
+	numbers := make([]int, 0)
+	for _, t := range d.Terminals {
+		prod, ok := d.Table[nt][terminals[t]]
+		if !ok {
+			continue
+		}
+		n := productionNumbers[prod.String()]
+		if _, ok := byProd[n]; !ok {
+			numbers = append(numbers, n)
+		}
+		byProd[n] = append(byProd[n], t)
+	}
+	sort.Ints(numbers)
+
+	return byProd, numbers
+}
+
+// code renders a symbol as inline Markdown code; backticks are not grammar symbols
+func code(sym string) string {
+	return "`" + sym + "`"
+}
+
+// codes renders a list of symbols as inline code separated by spaces
+func codes(syms []string) string {
+	out := make([]string, len(syms))
+	for i, sym := range syms {
+		out[i] = code(sym)
+	}
+	return strings.Join(out, " ")
+}
+
+// Markdown renders the productions, FIRST and FOLLOW sets, the table and the compound types
+func (d *GrammarDoc) Markdown() string {
+	var b strings.Builder
+	b.WriteString("# DOLME Grammar and LL(1) Parsing Table\n\n")
+	b.WriteString("<!-- Generated by `dolme grammar -format=md` from `pkg/parser/table.go`. Do not edit. -->\n\n")
+	b.WriteString("The productions of the `grammer` slice in `pkg/parser/table.go`, the FIRST and FOLLOW sets computed from them and the LL(1) table `NewParsingTable()` derives. ")
+	b.WriteString("Symbols starting with `@` are semantic actions: the parser runs them when it reaches them and they take no part in FIRST, FOLLOW or the table. ")
+	b.WriteString("`ε` is the empty string and `$` the end of input.\n\n")
+
+	b.WriteString("## 1. Productions\n\n```\n")
+	width := 0
+	for _, nt := range d.NonTerminals {
+		width = max(width, len(nt))
+	}
+	for i, p := range d.Productions {
+		if p.LHS == "" {
+			continue
+		}
+		fmt.Fprintf(&b, "%3d  %-*s → %s\n", i, width, p.LHS, strings.Join(p.RHS, " "))
+	}
+	b.WriteString("```\n\n")
+
+	b.WriteString("## 2. FIRST sets\n\n| Non-terminal | FIRST |\n|---|---|\n")
+	for _, nt := range d.NonTerminals {
+		fmt.Fprintf(&b, "| %s | %s |\n", nt, codes(d.order(d.First[nt])))
+	}
+
+	b.WriteString("\n## 3. FOLLOW sets\n\n| Non-terminal | FOLLOW |\n|---|---|\n")
+	for _, nt := range d.NonTerminals {
+		fmt.Fprintf(&b, "| %s | %s |\n", nt, codes(d.order(d.Follow[nt])))
+	}
+
+	b.WriteString("\n## 4. LL(1) parsing table\n\n")
+	b.WriteString("For each non-terminal, the lookahead tokens that select each of its productions. ")
+	b.WriteString("A production is entered under FIRST of its right-hand side, and under FOLLOW of its left-hand side when the right-hand side can derive ε. ")
+	b.WriteString("A missing token is a syntax error.\n\n")
+	b.WriteString("| Non-terminal | Lookahead | Production |\n|---|---|---|\n")
+	for _, nt := range d.NonTerminals {
+		byProd, numbers := d.entries(nt)
+		for _, n := range numbers {
+			fmt.Fprintf(&b, "| %s | %s | %d |\n", nt, codes(byProd[n]), n)
+		}
+	}
+
+	b.WriteString("\n## 5. Compound types\n\n")
+	b.WriteString("How the semantic actions type the arrays, lists, structs and enums the productions above declare. ")
+	b.WriteString("An int stored, passed or returned where a float is expected is widened to a float.\n\n")
+	b.WriteString("- `[n]T` is an array of n elements of type T, n from 1 to 1048576. ")
+	b.WriteString("An array is a reference to its elements: assigning it or passing it to a function shares them. ")
+	b.WriteString("Arrays cannot be returned from a function or be the field of a struct. ")
+	b.WriteString("An array literal takes the type of its first element of known type, float when ints and floats mix, and the type of the array it is stored in, so `[1, 2]` stored in a `[2]float` holds floats; `[]` fits any array. ")
+	b.WriteString("Every index is checked against the length when the program runs.\n")
+	b.WriteString("- `list[T]` is a growable list of elements of type T. ")
+	b.WriteString("A list is a reference to its elements on the heap: assigning it or passing it to a function shares them, and an append through any copy is seen by all. ")
+	b.WriteString("An append may move the elements, so each index reads them again. ")
+	b.WriteString("A list literal of any length fits a list of its element type. ")
+	b.WriteString("The interpreter frees the lists no variable reaches any more; native code never frees them.\n")
+	b.WriteString("- `struct P { x: T, ... }` declares a struct type whose fields take 8 bytes each, in declaration order. ")
+	b.WriteString("A struct is a reference to its fields: assigning it or passing it to a function shares them. ")
+	b.WriteString("A struct type is usable once its fields are declared, so a struct cannot hold itself. ")
+	b.WriteString("A field left out of a literal gets the zero value of its type: 0, 0.0, false, \"\", a new empty list or a new struct.\n")
+	b.WriteString("- `func (p: P) m(...)` declares a method of the struct P, compiled as a function named `P.m` whose first parameter is the receiver. ")
+	b.WriteString("No call can name it directly, as names hold no dot; `p.m(...)` passes p as that first argument.\n")
+	b.WriteString("- `enum E { A, B, ... }` declares an enum whose values are the indexes of its variants, as ints. ")
+	b.WriteString("Variants are named without their enum, so a variant name belongs to one enum only and no variable, parameter, function or struct may share it. ")
+	b.WriteString("A `match` on an enum tests its arms in order and must have an arm for every variant.\n")
+
+	return b.String()
+}
+
+// tokenClasses are the terminals that stand for a class of lexemes rather
+// than a fixed one
+var tokenClasses = map[string]bool{"id": true, "num": true, "strlit": true}
+
+// ebnfSymbol renders a symbol for EBNF: fixed terminals quoted, actions and
+// ε as comments
+func ebnfSymbol(sym string) string {
+	switch {
+	case sym == epsilon || strings.HasPrefix(sym, "@"):
+		return "(* " + sym + " *)"
+	case tokenClasses[sym]:
+		return sym
+	}
+	if _, ok := terminals[sym]; ok {
+		return strconv.Quote(sym)
+	}
+	return sym
+}
+
+// EBNF renders the grammar with one rule per non-terminal and its
+// productions as alternatives. Semantic actions are kept as comments.
+func (d *GrammarDoc) EBNF() string {
+	var b strings.Builder
+	b.WriteString("(* id, num and strlit are the identifier, number and string literal tokens *)\n")
+	for _, nt := range d.NonTerminals {
+		alts := make([]string, 0)
+		for _, p := range d.Productions {
+			if p.LHS != nt {
+				continue
+			}
+			syms := make([]string, 0, len(p.RHS))
+			for _, sym := range p.RHS {
+				syms = append(syms, ebnfSymbol(sym))
+			}
+			alts = append(alts, strings.Join(syms, " "))
+		}
+		fmt.Fprintf(&b, "%s = %s ;\n", nt, strings.Join(alts, "\n    | "))
+	}
+
+	return b.String()
+}
+
+// jsonProduction is a production in the JSON grammar
+type jsonProduction struct {
+	Number int      `json:"number"`
+	LHS    string   `json:"lhs"`
+	RHS    []string `json:"rhs"`
+	Rule   string   `json:"rule"`
+}
+
+// jsonGrammar is the JSON grammar
+type jsonGrammar struct {
+	Productions  []jsonProduction          `json:"productions"`
+	NonTerminals []string                  `json:"nonterminals"`
+	Terminals    []string                  `json:"terminals"`
+	First        map[string][]string       `json:"first"`
+	Follow       map[string][]string       `json:"follow"`
+	Table        map[string]map[string]int `json:"table"`
+}
+
+// JSON renders the grammar, the sets and the table, which maps each
+// non-terminal and lookahead token to a production number
+func (d *GrammarDoc) JSON() ([]byte, error) {
+	g := jsonGrammar{
+		Productions:  make([]jsonProduction, 0, len(d.Productions)),
+		NonTerminals: d.NonTerminals,
+		Terminals:    d.Terminals,
+		First:        make(map[string][]string),
+		Follow:       make(map[string][]string),
+		Table:        make(map[string]map[string]int),
+	}
+	for i, p := range d.Productions {
+		if p.LHS != "" {
+			g.Productions = append(g.Productions, jsonProduction{Number: i, LHS: p.LHS, RHS: p.RHS, Rule: p.String()})
+		}
+	}
+	for _, nt := range d.NonTerminals {
+		g.First[nt] = d.order(d.First[nt])
+		g.Follow[nt] = d.order(d.Follow[nt])
+		g.Table[nt] = make(map[string]int)
+		for tok, prod := range d.Table[nt] {
+			g.Table[nt][symbols[tok]] = productionNumbers[prod.String()]
+		}
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(g); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
@@ -0,0 +1,220 @@
+package parser
+
+import (
+	"dolme/pkg/lexer"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	epsilon   = "ε" // empty string in FIRST sets and empty productions
+	endMarker = "$" // end of input in FOLLOW sets
+)
+
+// SymbolSet is a set of grammar symbols
+type SymbolSet map[string]bool
+
+// Sorted returns the symbols in alphabetical order
+func (s SymbolSet) Sorted() []string {
+	out := make([]string, 0, len(s))
+	for sym := range s {
+		out = append(out, sym)
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// add inserts the symbols of other, except skip, and reports whether s grew
+func (s SymbolSet) add(other SymbolSet, skip string) bool {
+	grew := false
+	for sym := range other {
+		if sym != skip && !s[sym] {
+			s[sym] = true
+			grew = true
+		}
+	}
+
+	return grew
+}
+
+// String renders a production as `LHS → RHS`
+func (p Production) String() string {
+	return p.LHS + " → " + strings.Join(p.RHS, " ")
+}
+
+// Symbols returns the grammar symbols of the right-hand side, leaving out
+// semantic actions and ε
+func (p Production) Symbols() []string {
+	out := make([]string, 0, len(p.RHS))
+	for _, sym := range p.RHS {
+		if sym != epsilon && !strings.HasPrefix(sym, "@") {
+			out = append(out, sym)
+		}
+	}
+
+	return out
+}
+
+// Grammar returns the productions of the language. Index i holds production
+// number i; index 0 is unused.
+func Grammar() []Production {
+	return grammer
+}
+
+// nonTerminals returns the set of symbols that appear on a left-hand side
+func nonTerminals(g []Production) SymbolSet {
+	nts := make(SymbolSet)
+	for _, p := range g {
+		if p.LHS != "" {
+			nts[p.LHS] = true
+		}
+	}
+
+	return nts
+}
+
+// FirstSets computes FIRST of every non-terminal; ε marks those that derive
+// the empty string
+func FirstSets(g []Production) map[string]SymbolSet {
+	nts := nonTerminals(g)
+	first := make(map[string]SymbolSet)
+	for nt := range nts {
+		first[nt] = make(SymbolSet)
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, p := range g {
+			if p.LHS == "" {
+				continue
+			}
+			if first[p.LHS].add(firstOfSequence(p.Symbols(), first), "") {
+				changed = true
+			}
+		}
+	}
+
+	return first
+}
+
+// firstOfSequence computes FIRST of a string of grammar symbols
+func firstOfSequence(seq []string, first map[string]SymbolSet) SymbolSet {
+	out := make(SymbolSet)
+	for _, sym := range seq {
+		f, isNonTerminal := first[sym]
+		if !isNonTerminal {
+			out[sym] = true
+			return out
+		}
+		out.add(f, epsilon)
+		if !f[epsilon] {
+			return out
+		}
+	}
+	out[epsilon] = true
+
+	return out
+}
+
+// FollowSets computes FOLLOW of every non-terminal; $ marks the end of input
+func FollowSets(g []Production, first map[string]SymbolSet) map[string]SymbolSet {
+	follow := make(map[string]SymbolSet)
+	for nt := range first {
+		follow[nt] = make(SymbolSet)
+	}
+	for _, p := range g {
+		if p.LHS != "" {
+			follow[p.LHS][endMarker] = true // the start symbol
+			break
+		}
+	}
+
+	for changed := true; changed; {
+		changed = false
+		for _, p := range g {
+			syms := p.Symbols()
+			for i, sym := range syms {
+				if _, isNonTerminal := first[sym]; !isNonTerminal {
+					continue
+				}
+				rest := firstOfSequence(syms[i+1:], first)
+				if follow[sym].add(rest, epsilon) {
+					changed = true
+				}
+				if rest[epsilon] && follow[sym].add(follow[p.LHS], "") {
+					changed = true
+				}
+			}
+		}
+	}
+
+	return follow
+}
+
+// checkSymbols reports the first symbol of g that is neither a terminal nor
+// the left-hand side of a production
+func checkSymbols(g []Production) error {
+	nts := nonTerminals(g)
+	for i, p := range g {
+		for _, sym := range p.Symbols() {
+			if _, ok := terminals[sym]; !ok && !nts[sym] {
+				return fmt.Errorf("production %d (%s) uses unknown symbol %q", i, p, sym)
+			}
+		}
+	}
+
+	return nil
+}
+
+// BuildParsingTable derives the LL(1) table of g. Production i is chosen for
+// its left-hand side on every token in FIRST of its right-hand side, and on
+// FOLLOW of the left-hand side when the right-hand side can be empty. A cell
+// claimed by two productions is an LL(1) conflict and reported with both.
+func BuildParsingTable(g []Production) (ParsingTable, error) {
+	if err := checkSymbols(g); err != nil {
+		return nil, err
+	}
+	nts := nonTerminals(g)
+
+	first := FirstSets(g)
+	follow := FollowSets(g, first)
+
+	table := make(ParsingTable)
+	owner := make(map[string]map[string]int) // non-terminal -> terminal -> production number
+	for nt := range nts {
+		table[nt] = make(map[lexer.TokenType]Production)
+		owner[nt] = make(map[string]int)
+	}
+
+	conflicts := make([]string, 0)
+	for i, p := range g {
+		if p.LHS == "" {
+			continue
+		}
+
+		lookahead := firstOfSequence(p.Symbols(), first)
+		if lookahead[epsilon] {
+			lookahead.add(follow[p.LHS], "")
+		}
+
+		for _, sym := range lookahead.Sorted() {
+			if sym == epsilon {
+				continue
+			}
+			if j, taken := owner[p.LHS][sym]; taken {
+				conflicts = append(conflicts, fmt.Sprintf("LL(1) conflict in %s on %q: production %d (%s) and production %d (%s)", p.LHS, sym, j, g[j], i, p))
+				continue
+			}
+			owner[p.LHS][sym] = i
+			table[p.LHS][terminals[sym]] = p
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("grammar is not LL(1):\n%s", strings.Join(conflicts, "\n"))
+	}
+
+	return table, nil
+}
@@ -0,0 +1,389 @@
+package parser
+
+import (
+	"dolme/pkg/lexer"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// LRKind is the kind of an ACTION table entry
+type LRKind int
+
+const (
+	LRShift  LRKind = iota + 1 // push the token and go to state Target
+	LRReduce                   // reduce by production Target
+	LRAccept                   // the input is a Program
+)
+
+// LRAction is an entry of the ACTION table
+type LRAction struct {
+	Kind   LRKind
+	Target int // state for a shift, production for a reduce
+}
+
+// LRProduction is a production of the grammar an LR parser runs on. Every
+// semantic action of the LL(1) grammar is replaced by a marker non-terminal
+// deriving ε, and reducing the marker runs the action. Since markers are
+// reduced exactly where the LL(1) parser pops their action, both parsers
+// feed the translator the same tokens and actions in the same order.
+type LRProduction struct {
+	LHS    string
+	RHS    []string
+	Action string // semantic action of a marker, empty otherwise
+	Source int    // index of the production in the LL(1) grammar, 0 for the augmented start
+}
+
+// String renders a production as `LHS → RHS`
+func (p LRProduction) String() string {
+	if len(p.RHS) == 0 {
+		return p.LHS + " → " + epsilon
+	}
+	return p.LHS + " → " + strings.Join(p.RHS, " ")
+}
+
+// LRTable is the ACTION and GOTO table of an LR(1) or LALR(1) automaton
+type LRTable struct {
+	Productions []LRProduction
+	Action      []map[lexer.TokenType]LRAction // state -> lookahead -> action
+	Goto        []map[string]int               // state -> non-terminal -> state
+}
+
+// States returns the number of states of the automaton
+func (t *LRTable) States() int {
+	return len(t.Action)
+}
+
+// Expected returns the terminals that have an action in state, sorted
+func (t *LRTable) Expected(state int) []string {
+	out := make([]string, 0, len(t.Action[state]))
+	for tok := range t.Action[state] {
+		out = append(out, symbols[tok])
+	}
+	sort.Strings(out)
+
+	return out
+}
+
+// markerGrammar turns g into the augmented LR grammar: production 0 is
+// `Program' → Program`, followed by g with actions replaced by markers, then
+// one ε production per marker
+func markerGrammar(g []Production) []LRProduction {
+	start := ""
+	for _, p := range g {
+		if p.LHS != "" {
+			start = p.LHS
+			break
+		}
+	}
+
+	prods := []LRProduction{{LHS: start + "'", RHS: []string{start}}}
+	markers := make([]LRProduction, 0)
+	seen := make(map[string]bool)
+	for i, p := range g {
+		if p.LHS == "" {
+			continue
+		}
+
+		rhs := make([]string, 0, len(p.RHS))
+		for _, sym := range p.RHS {
+			switch {
+			case sym == epsilon:
+			case strings.HasPrefix(sym, "@"):
+				name := fmt.Sprintf("%s#%d", sym[1:], i)
+				for seen[name] {
+					name += "'"
+				}
+				seen[name] = true
+				rhs = append(rhs, name)
+				markers = append(markers, LRProduction{LHS: name, Action: sym, Source: i})
+			default:
+				rhs = append(rhs, sym)
+			}
+		}
+		prods = append(prods, LRProduction{LHS: p.LHS, RHS: rhs, Source: i})
+	}
+
+	return append(prods, markers...)
+}
+
+// lrItem is an LR(0) item: a production with a dot before symbol dot
+type lrItem struct {
+	prod, dot int
+}
+
+// itemSet maps the items of a state to their lookaheads
+type itemSet map[lrItem]SymbolSet
+
+// sortedItems returns the items of s in a fixed order
+func (s itemSet) sortedItems() []lrItem {
+	items := make([]lrItem, 0, len(s))
+	for it := range s {
+		items = append(items, it)
+	}
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].prod != items[j].prod {
+			return items[i].prod < items[j].prod
+		}
+		return items[i].dot < items[j].dot
+	})
+
+	return items
+}
+
+// key identifies a state by its kernel; withLookaheads false gives its LR(0) core
+func (s itemSet) key(withLookaheads bool) string {
+	var b strings.Builder
+	for _, it := range s.sortedItems() {
+		fmt.Fprintf(&b, "%d.%d", it.prod, it.dot)
+		if withLookaheads {
+			b.WriteString(":" + strings.Join(s[it].Sorted(), ","))
+		}
+		b.WriteByte(';')
+	}
+
+	return b.String()
+}
+
+// lrBuilder holds what the item set construction needs about the grammar
+type lrBuilder struct {
+	prods []LRProduction
+	byLHS map[string][]int
+	first map[string]SymbolSet
+}
+
+// closure adds to kernel the items predicted by it, with their lookaheads
+func (b *lrBuilder) closure(kernel itemSet) itemSet {
+	set := make(itemSet, len(kernel))
+	work := make([]lrItem, 0, len(kernel))
+	for it, la := range kernel {
+		set[it] = make(SymbolSet)
+		set[it].add(la, "")
+		work = append(work, it)
+	}
+
+	for len(work) > 0 {
+		it := work[len(work)-1]
+		work = work[:len(work)-1]
+
+		rhs := b.prods[it.prod].RHS
+		if it.dot >= len(rhs) {
+			continue
+		}
+		next, isNonTerminal := b.byLHS[rhs[it.dot]]
+		if !isNonTerminal {
+			continue
+		}
+
+		rest := firstOfSequence(rhs[it.dot+1:], b.first)
+		la := make(SymbolSet)
+		la.add(rest, epsilon)
+		if rest[epsilon] {
+			la.add(set[it], "")
+		}
+
+		for _, q := range next {
+			ni := lrItem{prod: q}
+			_, known := set[ni]
+			if !known {
+				set[ni] = make(SymbolSet)
+			}
+			if set[ni].add(la, "") || !known {
+				work = append(work, ni)
+			}
+		}
+	}
+
+	return set
+}
+
+// successors returns the kernels reached from set on each symbol after a
+// dot, and the symbols in a fixed order
+func (b *lrBuilder) successors(set itemSet) (map[string]itemSet, []string) {
+	kernels := make(map[string]itemSet)
+	order := make([]string, 0)
+	for _, it := range set.sortedItems() {
+		rhs := b.prods[it.prod].RHS
+		if it.dot >= len(rhs) {
+			continue
+		}
+		sym := rhs[it.dot]
+		if kernels[sym] == nil {
+			kernels[sym] = make(itemSet)
+			order = append(order, sym)
+		}
+		ni := lrItem{prod: it.prod, dot: it.dot + 1}
+		if kernels[sym][ni] == nil {
+			kernels[sym][ni] = make(SymbolSet)
+		}
+		kernels[sym][ni].add(set[it], "")
+	}
+
+	return kernels, order
+}
+
+// BuildLRTable derives the canonical LR(1) automaton of g, or its LALR(1)
+// version when lalr is set, where states with the same LR(0) core are merged
+// and their lookaheads joined. Cells claimed by two actions are shift/reduce
+// or reduce/reduce conflicts and reported with the productions involved.
+func BuildLRTable(g []Production, lalr bool) (*LRTable, error) {
+	if err := checkSymbols(g); err != nil {
+		return nil, err
+	}
+
+	prods := markerGrammar(g)
+	plain := make([]Production, len(prods))
+	b := &lrBuilder{prods: prods, byLHS: make(map[string][]int)}
+	for i, p := range prods {
+		plain[i] = Production{LHS: p.LHS, RHS: p.RHS}
+		b.byLHS[p.LHS] = append(b.byLHS[p.LHS], i)
+	}
+	b.first = FirstSets(plain)
+
+	// canonical LR(1) collection
+	states := []itemSet{b.closure(itemSet{{prod: 0}: {endMarker: true}})}
+	index := map[string]int{states[0].key(true): 0}
+	trans := []map[string]int{}
+	for s := 0; s < len(states); s++ {
+		trans = append(trans, make(map[string]int))
+		kernels, order := b.successors(states[s])
+		for _, sym := range order {
+			k := kernels[sym].key(true)
+			to, ok := index[k]
+			if !ok {
+				to = len(states)
+				index[k] = to
+				states = append(states, b.closure(kernels[sym]))
+			}
+			trans[s][sym] = to
+		}
+	}
+
+	if lalr {
+		states, trans = mergeCores(states, trans)
+	}
+
+	name := "LR(1)"
+	if lalr {
+		name = "LALR(1)"
+	}
+
+	t := &LRTable{
+		Productions: prods,
+		Action:      make([]map[lexer.TokenType]LRAction, len(states)),
+		Goto:        make([]map[string]int, len(states)),
+	}
+	conflicts := make([]string, 0)
+	for s, set := range states {
+		t.Action[s] = make(map[lexer.TokenType]LRAction)
+		t.Goto[s] = make(map[string]int)
+		for sym, to := range trans[s] {
+			if _, isNonTerminal := b.byLHS[sym]; isNonTerminal {
+				t.Goto[s][sym] = to
+			}
+		}
+
+		put := func(sym string, act LRAction) {
+			tok := terminals[sym]
+			old, taken := t.Action[s][tok]
+			if !taken || old == act {
+				t.Action[s][tok] = act
+				return
+			}
+			conflicts = append(conflicts, fmt.Sprintf("%s conflict in state %d on %q: %s and %s", name, s, sym, t.describe(old), t.describe(act)))
+		}
+
+		for _, it := range set.sortedItems() {
+			rhs := prods[it.prod].RHS
+			switch {
+			case it.dot < len(rhs):
+				if _, isNonTerminal := b.byLHS[rhs[it.dot]]; !isNonTerminal {
+					put(rhs[it.dot], LRAction{Kind: LRShift, Target: trans[s][rhs[it.dot]]})
+				}
+			case it.prod == 0:
+				put(endMarker, LRAction{Kind: LRAccept})
+			default:
+				for _, la := range set[it].Sorted() {
+					put(la, LRAction{Kind: LRReduce, Target: it.prod})
+				}
+			}
+		}
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("grammar is not %s:\n%s", name, strings.Join(conflicts, "\n"))
+	}
+
+	return t, nil
+}
+
+// mergeCores merges the LR(1) states that share an LR(0) core
+func mergeCores(states []itemSet, trans []map[string]int) ([]itemSet, []map[string]int) {
+	group := make([]int, len(states))
+	byCore := make(map[string]int)
+	merged := make([]itemSet, 0)
+	for s, set := range states {
+		k := set.key(false)
+		m, ok := byCore[k]
+		if !ok {
+			m = len(merged)
+			byCore[k] = m
+			merged = append(merged, make(itemSet))
+		}
+		group[s] = m
+		for it, la := range set {
+			if merged[m][it] == nil {
+				merged[m][it] = make(SymbolSet)
+			}
+			merged[m][it].add(la, "")
+		}
+	}
+
+	mergedTrans := make([]map[string]int, len(merged))
+	for s, edges := range trans {
+		m := group[s]
+		if mergedTrans[m] == nil {
+			mergedTrans[m] = make(map[string]int)
+		}
+		for sym, to := range edges {
+			mergedTrans[m][sym] = group[to]
+		}
+	}
+
+	return merged, mergedTrans
+}
+
+// describe renders an ACTION entry for a conflict report
+func (t *LRTable) describe(act LRAction) string {
+	switch act.Kind {
+	case LRShift:
+		return fmt.Sprintf("shift to state %d", act.Target)
+	case LRReduce:
+		p := t.Productions[act.Target]
+		return fmt.Sprintf("reduce %s (production %d)", p, p.Source)
+	default:
+		return "accept"
+	}
+}
+
+// lrTables caches the automata of the Dolme grammar, built on first use
+var lrTables = map[bool]*struct {
+	once  sync.Once
+	table *LRTable
+}{false: {}, true: {}}
+
+// NewLRTable returns the LR(1), or with lalr the LALR(1), table of the
+// grammar. Like NewParsingTable it panics when the grammar has a conflict.
+func NewLRTable(lalr bool) *LRTable {
+	c := lrTables[lalr]
+	c.once.Do(func() {
+		table, err := BuildLRTable(grammer, lalr)
+		if err != nil {
+			panic(err)
+		}
+		c.table = table
+	})
+
+	return c.table
+}
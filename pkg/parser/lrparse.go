@@ -0,0 +1,160 @@
+package parser
+
+import (
+	"dolme/pkg/lexer"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Algorithm selects how the parser recognizes the input
+type Algorithm int
+
+const (
+	LL1   Algorithm = iota // predictive parsing with the LL(1) table
+	LALR1                  // shift-reduce parsing with the LALR(1) automaton
+	LR1                    // shift-reduce parsing with the canonical LR(1) automaton
+)
+
+// algorithms maps the names accepted by ParseAlgorithm to algorithms
+var algorithms = map[string]Algorithm{"ll1": LL1, "lalr": LALR1, "lr1": LR1}
+
+// String returns the name ParseAlgorithm accepts for a
+func (a Algorithm) String() string {
+	for name, alg := range algorithms {
+		if alg == a {
+			return name
+		}
+	}
+	return fmt.Sprintf("Algorithm(%d)", int(a))
+}
+
+// ParseAlgorithm returns the algorithm called name: ll1, lalr or lr1
+func ParseAlgorithm(name string) (Algorithm, error) {
+	a, ok := algorithms[name]
+	if !ok {
+		return LL1, fmt.Errorf("unknown parser %q (ll1, lalr, lr1)", name)
+	}
+
+	return a, nil
+}
+
+// WithAlgorithm selects the parsing algorithm; LL1 is the default
+func WithAlgorithm(a Algorithm) Option {
+	return func(p *Parser) {
+		switch a {
+		case LALR1:
+			p.lr = NewLRTable(true)
+		case LR1:
+			p.lr = NewLRTable(false)
+		default:
+			p.lr = nil
+		}
+	}
+}
+
+// parseLR runs the shift-reduce driver. Tokens go to the translator when
+// shifted and marker reductions run their semantic action, so the
+// translator sees exactly what the LL(1) parser would send it. The first
+// syntax error stops the parse.
+func (p *Parser) parseLR() {
+	states := []int{0}
+	syms := []string{}     // grammar symbol under each state but the first, for the trace
+	nodes := []*TreeNode{} // tree node under each state but the first, when building a tree
+	for {
+		state := states[len(states)-1]
+		stack, tok := p.lrSnapshot(states, syms), p.currentToken
+		act, ok := p.lr.Action[state][p.currentToken.Type]
+		if !ok {
+			p.handleLRError(state)
+			p.record(Step{Stack: stack, Token: tok, Kind: StepError, Symbol: strings.Join(p.lr.Expected(state), " "), Message: p.errorMessage()})
+			if p.tree != nil {
+				p.tree.Children = nodes // what was parsed before the error
+			}
+			return
+		}
+
+		switch act.Kind {
+		case LRShift:
+			p.tr.SetCurrentToken(p.currentToken)
+			p.record(Step{Stack: stack, Token: tok, Kind: StepShift, Symbol: symbols[tok.Type]})
+			p.nextToken()
+			states = append(states, act.Target)
+			syms = append(syms, symbols[tok.Type])
+			if p.tree != nil {
+				nodes = append(nodes, &TreeNode{Kind: NodeTerminal, Symbol: symbols[tok.Type], Token: tok})
+			}
+
+		case LRReduce:
+			prod := p.lr.Productions[act.Target]
+			if prod.Action != "" {
+				p.tr.ExecuteAction(prod.Action)
+			}
+			p.record(Step{Stack: stack, Token: tok, Kind: StepReduce, Production: act.Target, Rule: prod.String(), Symbol: prod.Action})
+			states = states[:len(states)-len(prod.RHS)]
+			syms = syms[:len(syms)-len(prod.RHS)]
+			states = append(states, p.lr.Goto[states[len(states)-1]][prod.LHS])
+			syms = append(syms, prod.LHS)
+			if p.tree != nil {
+				rhs := nodes[len(nodes)-len(prod.RHS):]
+				nodes = append(nodes[:len(nodes)-len(prod.RHS)], p.reduceNode(prod, rhs))
+			}
+
+		case LRAccept:
+			p.record(Step{Stack: stack, Token: tok, Kind: StepAccept})
+			if p.tree != nil {
+				*p.tree = *nodes[0]
+			}
+			return
+		}
+	}
+}
+
+// reduceNode builds the tree node of a reduction from the nodes of its
+// right-hand side: a marker becomes the leaf of its semantic action, so the
+// tree matches the one the LL(1) parser builds
+func (p *Parser) reduceNode(prod LRProduction, rhs []*TreeNode) *TreeNode {
+	if prod.Action != "" {
+		return &TreeNode{Kind: NodeAction, Symbol: prod.Action}
+	}
+
+	node := &TreeNode{Kind: NodeNonTerminal, Symbol: prod.LHS, Production: prod.Source}
+	node.Children = append(node.Children, rhs...)
+	if len(rhs) == 0 {
+		node.Children = []*TreeNode{{Kind: NodeEpsilon, Symbol: epsilon}}
+	}
+
+	return node
+}
+
+// lrSnapshot interleaves states and symbols, `0 func 3 id 7`, when tracing
+func (p *Parser) lrSnapshot(states []int, syms []string) []string {
+	if p.trace == nil {
+		return nil
+	}
+	out := []string{strconv.Itoa(states[0])}
+	for i, sym := range syms {
+		out = append(out, sym, strconv.Itoa(states[i+1]))
+	}
+	return out
+}
+
+// handleLRError reports a token that has no action in state, naming the
+// expected terminal the same way the LL(1) parser does when there is a
+// single candidate
+func (p *Parser) handleLRError(state int) {
+	expected := p.lr.Expected(state)
+	switch {
+	case slices.Contains(expected, ";") && p.isStatementBoundary(p.currentToken.Type):
+		p.addError("Missing semicolon")
+	case len(expected) == 1:
+		p.addContextualError(expected[0])
+	case p.currentToken.Type == lexer.EOF && slices.Contains(expected, "}"):
+		p.addError("Missing closing brace")
+	case p.currentToken.Type == lexer.EOF:
+		p.handleUnexpectedEndOfInput()
+	default:
+		p.addContextualError("")
+	}
+}
@@ -7,24 +7,54 @@ import (
 	"strings"
 )
 
+// Translator receives the matched tokens and semantic actions of a parse in
+// order. The code generator is the default one; ast.Builder builds a tree instead.
+type Translator interface {
+	SetCurrentToken(token lexer.Token)
+	ExecuteAction(actionName string)
+}
+
 type Parser struct {
-	stack        *stack.Stack     // LL(1) parsing stack
-	lexer        *lexer.Lexer     // lexer instance
-	cg           *codegen.Codegen // code generator instance
-	currentToken lexer.Token      // current token
-	table        ParsingTable     // LL(1) parsing table
-	errors       []string         // list of errors
+	stack          *stack.Stack         // LL(1) parsing stack
+	lexer          *lexer.Lexer         // lexer instance
+	cg             *codegen.Codegen     // code generator instance
+	tr             Translator           // receiver of tokens and semantic actions
+	currentToken   lexer.Token          // current token
+	table          ParsingTable         // LL(1) parsing table
+	follow         map[string]SymbolSet // FOLLOW sets used to resynchronize after an error
+	lr             *LRTable             // LR automaton, nil for LL(1) parsing
+	errors         []string             // list of errors
+	recovering     bool                 // an error was reported and no token has matched since
+	trace          *Trace               // receives every step when tracing
+	lastError      string               // last error message, for the trace
+	lastSuppressed bool                 // whether the last error was a dropped cascade
+	tree           *TreeNode            // root of the parse tree when building one
+	nodes          []*TreeNode          // tree node of each parse stack entry
+	popped         *TreeNode            // node of the symbol popped last
+}
+
+// Option configures a Parser
+type Option func(*Parser)
+
+// WithTranslator sends the tokens and semantic actions to t instead of the code generator
+func WithTranslator(t Translator) Option {
+	return func(p *Parser) { p.tr = t }
 }
 
 // NewParser creates a new parser instance
-func NewParser(l *lexer.Lexer) *Parser {
+func NewParser(l *lexer.Lexer, opts ...Option) *Parser {
 	p := &Parser{
 		lexer:  l,
 		cg:     codegen.NewCodegen(),
 		table:  NewParsingTable(),
+		follow: FollowSets(grammer, FirstSets(grammer)),
 		stack:  stack.NewStack("$", "Program"), // Program is start state and $ is bottom of the stack
 		errors: []string{},
 	}
+	p.tr = p.cg
+	for _, opt := range opts {
+		opt(p)
+	}
 
 	// Initialize current token
 	p.nextToken()
@@ -34,24 +64,49 @@ func NewParser(l *lexer.Lexer) *Parser {
 
 // Parse starts parsing the input program
 func (p *Parser) Parse() {
+	if p.lr != nil {
+		p.parseLR()
+		return
+	}
+
 	for p.stack.Size() > 1 { // While stack is not empty (only $ remains)
-		top := p.stack.Pop()
+		stack, tok := p.snapshot(), p.currentToken
+		top := p.pop()
 
 		if p.isTerminal(top) {
-			// Check if this is a semantic action
+			// Check if this is a semantic action; code generation stops at the first error
 			if p.isSemanticAction(top) {
-				p.cg.ExecuteAction(top)
+				s := Step{Stack: stack, Token: tok, Kind: StepAction, Symbol: top}
+				if len(p.errors) == 0 {
+					p.tr.ExecuteAction(top)
+				} else {
+					s.Message = "not run after a syntax error"
+				}
+				p.record(s)
 			} else if p.matchTerminal(top) {
-				p.cg.SetCurrentToken(p.currentToken)
+				if len(p.errors) == 0 {
+					p.tr.SetCurrentToken(p.currentToken)
+				}
+				p.record(Step{Stack: stack, Token: tok, Kind: StepMatch, Symbol: top})
+				if p.tree != nil {
+					p.popped.Token = tok
+				}
+				p.recovering = false
 				p.nextToken()
 			} else {
 				if p.handleTerminalError(top) {
 					break
 				}
+				p.record(Step{Stack: stack, Token: tok, Kind: StepError, Symbol: top, Message: p.errorMessage()})
+				p.synchronize(top)
 			}
 		} else {
 			// Non-terminal: pick production from table
 			if production, ok := p.table[top][p.currentToken.Type]; ok {
+				rule := production.String()
+				p.record(Step{Stack: stack, Token: tok, Kind: StepExpand, Production: productionNumbers[rule], Rule: rule})
+				children := p.expand(p.popped, productionNumbers[rule], production)
+
 				rhs_length := len(production.RHS)
 				// If production is ε, do not push anything
 				if rhs_length == 0 || (rhs_length == 1 && production.RHS[0] == "ε") {
@@ -61,7 +116,7 @@ func (p *Parser) Parse() {
 				// Push RHS of production onto stack in reverse order (so first symbol is on top)
 				for i := rhs_length - 1; i >= 0; i-- {
 					if production.RHS[i] != "ε" {
-						p.stack.Push(production.RHS[i])
+						p.push(production.RHS[i], nth(children, i))
 					}
 				}
 
@@ -69,6 +124,8 @@ func (p *Parser) Parse() {
 				if p.handleNonTerminalError(top) {
 					break
 				}
+				p.record(Step{Stack: stack, Token: tok, Kind: StepError, Symbol: top, Message: p.errorMessage()})
+				p.synchronize(top)
 			}
 
 		}
@@ -76,6 +133,7 @@ func (p *Parser) Parse() {
 
 	if p.currentToken.Type != lexer.EOF {
 		p.handleUnexpectedEndOfInput()
+		p.record(Step{Stack: p.snapshot(), Token: p.currentToken, Kind: StepError, Symbol: "$", Message: p.errorMessage()})
 		return
 	}
 }
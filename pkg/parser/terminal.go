@@ -5,6 +5,30 @@ import (
 	"strings"
 )
 
+// terminals maps the terminal symbols used in the grammar to the tokens they match
+var terminals = map[string]lexer.TokenType{
+	"let": lexer.LET, "func": lexer.FUNC, "return": lexer.RETURN, "if": lexer.IF, "else": lexer.ELSE,
+	"while": lexer.WHILE, "break": lexer.BREAK, "continue": lexer.CONTINUE, "print": lexer.PRINT,
+	"true": lexer.TRUE, "false": lexer.FALSE, "not": lexer.NOT, "and": lexer.AND, "or": lexer.OR,
+	"int": lexer.INT, "float": lexer.FLOAT, "bool": lexer.BOOL, "string": lexer.STR, "len": lexer.LEN,
+	"list": lexer.LIST, "append": lexer.APPEND, "struct": lexer.STRUCT, "enum": lexer.ENUM, "match": lexer.MATCH,
+	"(": lexer.LPAREN, ")": lexer.RPAREN, "{": lexer.LBRACE, "}": lexer.RBRACE, "[": lexer.LSBRACE, "]": lexer.RSBRACE, ".": lexer.DOT,
+	";": lexer.SEMICOLON, ",": lexer.COMMA, "=": lexer.ASSIGN, ":": lexer.COLON,
+	"+": lexer.PLUS, "-": lexer.MINUS, "*": lexer.MULT, "/": lexer.DIV, "%": lexer.MOD,
+	"<": lexer.LT, ">": lexer.GT, "<=": lexer.LE, ">=": lexer.GE, "==": lexer.EQ, "!=": lexer.NE, "=>": lexer.ARROW,
+	"id": lexer.ID, "num": lexer.NUM, "strlit": lexer.STRING,
+	"$": lexer.EOF, // end of input
+}
+
+// symbols maps tokens back to their terminal symbol
+var symbols = func() map[lexer.TokenType]string {
+	m := make(map[lexer.TokenType]string, len(terminals))
+	for sym, t := range terminals {
+		m[t] = sym
+	}
+	return m
+}()
+
 // isTerminal checks if a symbol is a terminal
 func (p *Parser) isTerminal(symbol string) bool {
 	// Semantic actions are considered terminals
@@ -12,103 +36,12 @@ func (p *Parser) isTerminal(symbol string) bool {
 		return true
 	}
 
-	terminals := map[string]bool{
-		"let": true, "func": true, "return": true, "if": true, "while": true, "break": true, "continue": true, "print": true, "else": true,
-		"true": true, "false": true, "not": true, "and": true, "or": true,
-		"int": true, "float": true, "bool": true,
-		"(": true, ")": true, "{": true, "}": true, ";": true, ",": true, "=": true, ":": true,
-		"+": true, "-": true, "*": true, "/": true, "%": true,
-		"<": true, ">": true, "<=": true, ">=": true, "==": true, "!=": true,
-		"id": true, "num": true, "string": true, "$": true, "EOF": true,
-	}
-
-	return terminals[symbol]
+	_, ok := terminals[symbol]
+	return ok
 }
 
 // matchTerminal checks if the current token matches the expected terminal
 func (p *Parser) matchTerminal(expected string) bool {
-	switch expected {
-	case "id":
-		return p.currentToken.Type == lexer.ID
-	case "num":
-		return p.currentToken.Type == lexer.NUM
-	case "string":
-		return p.currentToken.Type == lexer.STRING
-	case "$":
-		return p.currentToken.Type == lexer.EOF
-	case "let":
-		return p.currentToken.Type == lexer.LET
-	case "func":
-		return p.currentToken.Type == lexer.FUNC
-	case "return":
-		return p.currentToken.Type == lexer.RETURN
-	case "if":
-		return p.currentToken.Type == lexer.IF
-	case "while":
-		return p.currentToken.Type == lexer.WHILE
-	case "break":
-		return p.currentToken.Type == lexer.BREAK
-	case "continue":
-		return p.currentToken.Type == lexer.CONTINUE
-	case "print":
-		return p.currentToken.Type == lexer.PRINT
-	case "else":
-		return p.currentToken.Type == lexer.ELSE
-	case "true":
-		return p.currentToken.Type == lexer.TRUE
-	case "false":
-		return p.currentToken.Type == lexer.FALSE
-	case "not":
-		return p.currentToken.Type == lexer.NOT
-	case "and":
-		return p.currentToken.Type == lexer.AND
-	case "or":
-		return p.currentToken.Type == lexer.OR
-	case "(":
-		return p.currentToken.Type == lexer.LPAREN
-	case ")":
-		return p.currentToken.Type == lexer.RPAREN
-	case "{":
-		return p.currentToken.Type == lexer.LBRACE
-	case "}":
-		return p.currentToken.Type == lexer.RBRACE
-	case ";":
-		return p.currentToken.Type == lexer.SEMICOLON
-	case ",":
-		return p.currentToken.Type == lexer.COMMA
-	case ":":
-		return p.currentToken.Type == lexer.COLON
-	case "=":
-		return p.currentToken.Type == lexer.ASSIGN
-	case "int":
-		return p.currentToken.Type == lexer.INT
-	case "float":
-		return p.currentToken.Type == lexer.FLOAT
-	case "bool":
-		return p.currentToken.Type == lexer.BOOL
-	case "+":
-		return p.currentToken.Type == lexer.PLUS
-	case "-":
-		return p.currentToken.Type == lexer.MINUS
-	case "*":
-		return p.currentToken.Type == lexer.MULT
-	case "/":
-		return p.currentToken.Type == lexer.DIV
-	case "%":
-		return p.currentToken.Type == lexer.MOD
-	case "<":
-		return p.currentToken.Type == lexer.LT
-	case ">":
-		return p.currentToken.Type == lexer.GT
-	case "<=":
-		return p.currentToken.Type == lexer.LE
-	case ">=":
-		return p.currentToken.Type == lexer.GE
-	case "==":
-		return p.currentToken.Type == lexer.EQ
-	case "!=":
-		return p.currentToken.Type == lexer.NE
-	default:
-		return false
-	}
+	t, ok := terminals[expected]
+	return ok && p.currentToken.Type == t
 }
@@ -0,0 +1,123 @@
+package parser_test
+
+import (
+	"dolme/pkg/interpreter"
+	"dolme/pkg/parser"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestArrays(t *testing.T) {
+	src := `func fill(xs: [4]float, v: float): int {
+    let i : int = 0;
+    while (i < len(xs)) {
+        xs[i] = v * i;
+        i = i + 1;
+    }
+    return i;
+}
+let xs : [4]float = [];
+let n : int = fill(xs, 0.5);
+print(xs[3]);
+let ys : [3]int = [1, 2, 3];
+let zs : [3]int = ys;
+zs[0] = ys[1] + ys[2];
+print(ys[0]);
+print(n + len(zs));
+let names : [2]string = ["a", "b"];
+print(names[1] + names[0]);
+let flags : [2]bool = [];
+print(flags[1]);
+`
+	want := "1.50000000000000000000\n5\n7\nba\nfalse\n"
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		if got := run(t, src, a); got != want {
+			t.Errorf("%s: unexpected output %q", a, got)
+		}
+	}
+}
+
+func TestArrayBounds(t *testing.T) {
+	src := `let xs : [3]int = [1, 2, 3];
+let i : int = 0 - 1;
+print(xs[0]);
+print(xs[i]);
+`
+	p := parseWith(src, parser.LL1)
+	if errs := append(p.Errors(), p.GetSemanticErrors()...); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var out strings.Builder
+	err := interpreter.NewInterpreter(p.GetIRCode(), interpreter.WithWriter(&out)).Run()
+	if err == nil || err.Error() != "index out of range [-1] with length 3 at line 4" {
+		t.Errorf("expected an out of range error at line 4, got %v", err)
+	}
+	if out.String() != "1\n" {
+		t.Errorf("unexpected output before the error %q", out.String())
+	}
+}
+
+func TestArrayHeapLimit(t *testing.T) {
+	p := parseWith("let xs : [1000]int = [];\nprint(len(xs));\n", parser.LL1)
+	err := interpreter.NewInterpreter(p.GetIRCode(), interpreter.WithHeapLimit(1024)).Run()
+	if !errors.Is(err, interpreter.ErrHeapLimitExceeded) {
+		t.Errorf("expected the heap limit to be exceeded, got %v", err)
+	}
+
+	// every array is garbage once the next iteration starts
+	garbage := `let k : int = 0;
+while (k < 10000) {
+    let xs : [10]int = [];
+    k = k + 1;
+}
+print(k);
+`
+	p = parseWith(garbage, parser.LL1)
+	var out strings.Builder
+	if err := interpreter.NewInterpreter(p.GetIRCode(), interpreter.WithWriter(&out), interpreter.WithHeapLimit(1024)).Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "10000\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	// each frame keeps its array alive until the recursion unwinds
+	nested := `func f(n: int): int {
+    let xs : [10]int = [];
+    if (n == 0) { return 0; }
+    return f(n - 1) + xs[0];
+}
+print(f(100));
+`
+	p = parseWith(nested, parser.LL1)
+	err = interpreter.NewInterpreter(p.GetIRCode(), interpreter.WithHeapLimit(1024)).Run()
+	if !errors.Is(err, interpreter.ErrHeapLimitExceeded) {
+		t.Errorf("expected the heap limit to be exceeded, got %v", err)
+	}
+}
+
+func TestArrayTypes(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"let xs : [3]int = [1, 2];\n", "Type mismatch expected [3]int, found [2]int"},
+		{"let xs : [2]int = [1, 2.5];\n", "Type mismatch expected [2]int, found [2]float"},
+		{"let xs : [2]bool = [true, 1];\n", "Type mismatch expected bool, found int"},
+		{"let xs : [0]int = [];\n", "Invalid array length `0`"},
+		{"let xs : [99999999999]int = [];\n", "Invalid array length `99999999999`"},
+		{"let b : bool = true;\nprint(b[0]);\n", "Cannot index a value of type bool"},
+		{"let xs : [2]int = [];\nxs[true] = 1;\n", "Type mismatch expected int, found bool"},
+		{"let xs : [2]int = [];\nxs[0] = 1.5;\n", "Type mismatch expected int, found float"},
+		{"let xs : [2]int = [];\nlet n : int = xs + 1;\n", "Type mismatch expected int, found [2]int"},
+		{"let xs : [2]int = [];\nlet ys : [3]int = xs;\n", "Type mismatch expected [3]int, found [2]int"},
+		{"func f(xs: [2]int): int { return 0; }\nlet ys : [3]int = [];\nlet n : int = f(ys);\n", "Type mismatch expected [2]int, found [3]int"},
+		{"func f(): [2]int { return 0; }\n", "Arrays cannot be returned from `f`"},
+	}
+
+	for _, tt := range tests {
+		expectSemanticError(t, tt.src, tt.expected)
+	}
+}
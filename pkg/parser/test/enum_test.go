@@ -0,0 +1,101 @@
+package parser_test
+
+import (
+	"dolme/pkg/parser"
+	"testing"
+)
+
+func TestEnums(t *testing.T) {
+	src := `enum Color { Red, Green, Blue }
+enum Op { Skip, Stop, Go }
+struct Light { color: Color, on: bool }
+func next(c: Color): Color {
+    match (c) {
+        Red => { return Green; }
+        Green => { return Blue; }
+        Blue => { return Red; }
+    }
+    return Red;
+}
+let c : Color = Red;
+let i : int = 0;
+while (i < 4) {
+    match (c) {
+        Red => { print("red"); }
+        Green => {
+            match (next(c)) {
+                Blue => { print("then blue"); }
+                Red => {}
+                Green => {}
+            }
+        }
+        Blue => { print("blue"); }
+    }
+    c = next(c);
+    i = i + 1;
+}
+let l : Light = Light { on: true };
+print(l.color == Red);
+l.color = Blue;
+print(l.color != Blue);
+let ops : list[int] = [0, 2, 2, 0, 1, 2];
+let n : int = 0;
+let op : Op = Go;
+i = 0;
+while (i < len(ops)) {
+    let k : int = ops[i];
+    i = i + 1;
+    op = Go;
+    if (k == 0) {
+        op = Skip;
+    }
+    if (k == 1) {
+        op = Stop;
+    }
+    match (op) {
+        Skip => { continue; }
+        Stop => { break; }
+        Go => { n = n + 1; }
+    }
+}
+print(n * 10 + i);
+`
+	want := "red\nthen blue\nblue\nred\ntrue\nfalse\n25\n"
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		if got := run(t, src, a); got != want {
+			t.Errorf("%s: unexpected output %q", a, got)
+		}
+	}
+}
+
+func TestEnumTypes(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"enum C { A, B, D }\nlet c : C = A;\nmatch (c) {\n    B => {}\n}\n", "Non-exhaustive match on C, missing A, D"},
+		{"enum C { A }\nlet c : C = A;\nmatch (c) {\n    A => {}\n    E => {}\n}\n", "No variant `E` in C"},
+		{"enum C { A }\nlet c : C = A;\nmatch (c) {\n    A => {}\n    A => {}\n}\n", "Duplicate arm `A`"},
+		{"let n : int = 1;\nmatch (n) {}\n", "Cannot match on a value of type int"},
+		{"enum C { A, A }\n", "Redeclaration of variant `A` of `C`"},
+		{"enum C { A }\nenum D { A }\n", "Name clash between variant `A` of `D` and variant `A` of `C`"},
+		{"enum C { A }\nstruct C { x: int }\n", "Redeclaration of type `C`"},
+		{"enum C { A }\nlet n : int = A;\n", "Type mismatch expected int, found C"},
+		{"enum C { A }\nenum D { B }\nlet c : C = B;\n", "Type mismatch expected C, found D"},
+		{"enum C { A }\nlet n : int = A + 1;\n", "Type mismatch expected int, found C"},
+		{"enum C { A }\nenum D { B }\nprint(A == B);\n", "Type mismatch expected C, found D"},
+		{"enum C { A }\nfunc f(c: C): int { return 0; }\nlet n : int = f(0);\n", "Type mismatch expected C, found int"},
+		{"enum C { A }\nfunc f(): C { return 7; }\n", "Type mismatch expected C, found int"},
+		{"enum C { A }\nlet A : int = 3;\n", "Name clash between variant `A` of `C` and variable `A`"},
+		{"enum C { A }\nfunc A(): int { return 0; }\n", "Name clash between variant `A` of `C` and function `A`"},
+		{"enum C { A }\nfunc f(A: int): int { return A; }\n", "Name clash between variant `A` of `C` and parameter `A`"},
+		{"enum C { A }\nstruct A { x: int }\n", "Name clash between variant `A` of `C` and struct `A`"},
+		{"let A : int = 3;\nenum C { A }\n", "Name clash between variant `A` of `C` and variable `A`"},
+		{"func A(): int { return 0; }\nenum C { A }\n", "Name clash between variant `A` of `C` and function `A`"},
+		{"struct A { x: int }\nenum C { A }\n", "Name clash between variant `A` of `C` and struct `A`"},
+	}
+
+	for _, tt := range tests {
+		expectSemanticError(t, tt.src, tt.expected)
+	}
+}
@@ -0,0 +1,185 @@
+package parser_test
+
+import (
+	"bytes"
+	"dolme/pkg/interpreter"
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"dolme/pkg/parser/codegen"
+	"strings"
+	"testing"
+)
+
+// run parses and interprets src with the given algorithm and returns what it printed
+func run(t *testing.T, src string, a parser.Algorithm) string {
+	t.Helper()
+
+	p := parseWith(src, a)
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("%s: syntax errors: %v", a, errs)
+	}
+	if errs := p.GetSemanticErrors(); len(errs) > 0 {
+		t.Fatalf("%s: semantic errors: %v", a, errs)
+	}
+
+	var out bytes.Buffer
+	if err := interpreter.NewInterpreter(p.GetIRCode(), interpreter.WithWriter(&out)).Run(); err != nil {
+		t.Fatalf("%s: %v", a, err)
+	}
+	return out.String()
+}
+
+// expectSemanticError parses src and checks that it has no syntax errors and
+// a single semantic error containing want
+func expectSemanticError(t *testing.T, src, want string) {
+	t.Helper()
+
+	p := parseWith(src, parser.LL1)
+	if len(p.Errors()) > 0 {
+		t.Fatalf("%q: syntax errors: %v", src, p.Errors())
+	}
+	errs := p.GetSemanticErrors()
+	if len(errs) != 1 || !strings.Contains(ansi.ReplaceAllString(errs[0], ""), want) {
+		t.Errorf("%q: expected %q, got %v", src, want, errs)
+	}
+}
+
+func TestBooleanExpressions(t *testing.T) {
+	src := `func positive(x: int): bool {
+    return x > 0;
+}
+func negate(b: bool): bool {
+    return not b;
+}
+let a : int = 3;
+let b : int = 5;
+let ok : bool = a < b and positive(a);
+print(ok);
+let no : bool = negate(a == 3 or b < 0);
+print(no);
+let same : bool = ok == no;
+print(same);
+if (positive(b) and not no) {
+    print(a);
+}
+`
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		if got := run(t, src, a); got != "true\nfalse\nfalse\n3\n" {
+			t.Errorf("%s: unexpected output %q", a, got)
+		}
+	}
+}
+
+func TestLeftAssociativity(t *testing.T) {
+	src := "let d : int = 10 - 4 - 3;\nprint(d);\nlet q : int = 100 / 10 / 5;\nprint(q);\nlet f : float = 2 * 3 - 1;\nprint(f);\n"
+	if got := run(t, src, parser.LL1); got != "3\n2\n5.00000000000000000000\n" {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+func TestUnaryOperators(t *testing.T) {
+	src := `func f(x: int): int {
+    return x * 2;
+}
+let a : int = 7;
+let b : int = -a;
+print(b);
+let c : int = -(a + 3);
+print(c);
+let d : int = -f(1);
+print(d);
+let e : int = a -1;
+print(e);
+let g : float = -2.5 * -2;
+print(g);
+let h : int = - -a + +a;
+print(h);
+let k : bool = -a < 0 and not -a > 0;
+print(k);
+`
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		if got := run(t, src, a); got != "-7\n-10\n-2\n6\n5.00000000000000000000\n14\ntrue\n" {
+			t.Errorf("%s: unexpected output %q", a, got)
+		}
+	}
+}
+
+func TestExpressionTypes(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"let a : int = 1;\nlet bad : int = a < 2;\n", "Type mismatch expected int, found bool"},
+		{"let b : bool = 1 and true;\n", "Type mismatch expected bool, found int"},
+		{"let c : bool = not 2.5;\n", "Type mismatch expected bool, found float"},
+		{"let f : float = 1.5;\nlet i : int = f;\n", "Type mismatch expected int, found float"},
+		{"let n : int = -true;\n", "Type mismatch expected int, found bool"},
+		{"print(true == 1);\n", "Type mismatch expected bool, found int"},
+		{"print(2.5 > false);\n", "Type mismatch expected bool, found float"},
+	}
+
+	for _, tt := range tests {
+		expectSemanticError(t, tt.src, tt.expected)
+	}
+
+	// an int widens to a float variable
+	p := parseWith("let f : float = 1;\nf = 2;\n", parser.LL1)
+	if errs := p.GetSemanticErrors(); len(errs) > 0 {
+		t.Fatalf("unexpected semantic errors: %v", errs)
+	}
+	stores := 0
+	for _, in := range p.GetIRCode() {
+		if in.Op == codegen.OpAssign && in.Arg3 == codegen.GlobalAddrBase {
+			stores++
+			if in.Type != lexer.FLOAT {
+				t.Errorf("expected the stores into f to be float, got %s", in)
+			}
+		}
+	}
+	if stores != 2 {
+		t.Errorf("expected 2 stores into f, got %d", stores)
+	}
+}
+
+func TestUndefinedNames(t *testing.T) {
+	// the value of an undefined name has no type to mismatch with
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"let x : int = g();\n", "Undefined function `g`"},
+		{"let x : int = g() * 2;\n", "Undefined function `g`"},
+		{"let b : bool = g() < 1;\n", "Undefined function `g`"},
+		{"let s : string = g() + \"a\";\n", "Undefined function `g`"},
+		{"let n : int = len(g());\n", "Undefined function `g`"},
+		{"let xs : [2]int = [g(), 1];\n", "Undefined function `g`"},
+		{"let xs : list[int] = [];\nappend(xs, g());\n", "Undefined function `g`"},
+		{"func f(): bool { return g(); }\n", "Undefined function `g`"},
+		{"func f(): int { let x : int = 1; x = y + 1; return x; }\n", "Undefined variable `y`"},
+	}
+
+	for _, tt := range tests {
+		expectSemanticError(t, tt.src, tt.expected)
+	}
+}
+
+func TestIntsWidenToFloats(t *testing.T) {
+	// an int returned from a float function or passed as a float
+	// parameter arrives as a float
+	src := `func half(x: int): float {
+    return x / 2;
+}
+func third(f: float): float {
+    return f / 3;
+}
+let n : int = 3;
+print(half(n));
+print(third(n));
+`
+	want := "1.00000000000000000000\n1.00000000000000000000\n"
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		if got := run(t, src, a); got != want {
+			t.Errorf("%s: unexpected output %q", a, got)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package parser_test
+
+import (
+	"dolme/pkg/parser"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+)
+
+// production returns the number of the production rendered as rule
+func production(t *testing.T, rule string) int {
+	t.Helper()
+
+	for k, p := range parser.Grammar() {
+		if k > 0 && p.String() == rule {
+			return k
+		}
+	}
+	t.Fatalf("no production %s", rule)
+	return 0
+}
+
+func TestGrammarDocUpToDate(t *testing.T) {
+	committed, err := os.ReadFile("../../../dolme.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(committed) != parser.NewGrammarDoc().Markdown() {
+		t.Errorf("dolme.md is out of date with the grammar, regenerate it with `make docs`")
+	}
+}
+
+func TestGrammarDoc(t *testing.T) {
+	doc := parser.NewGrammarDoc()
+
+	if doc.NonTerminals[0] != "Program" || doc.Terminals[len(doc.Terminals)-1] != "$" {
+		t.Errorf("unexpected symbol order %v %v", doc.NonTerminals, doc.Terminals)
+	}
+
+	ebnf := doc.EBNF()
+	for _, want := range []string{"Program = DeclList ;", "DeclList = Decl DeclList\n    | (* ε *) ;", `Scalar = "int"`} {
+		if !strings.Contains(ebnf, want) {
+			t.Errorf("expected the EBNF to contain %q", want)
+		}
+	}
+
+	data, err := doc.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var g struct {
+		Productions []struct {
+			Number int    `json:"number"`
+			Rule   string `json:"rule"`
+		} `json:"productions"`
+		First map[string][]string       `json:"first"`
+		Table map[string]map[string]int `json:"table"`
+	}
+	if err := json.Unmarshal(data, &g); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.Productions) != len(parser.Grammar())-1 || g.Productions[0].Rule != "Program → DeclList" {
+		t.Errorf("unexpected productions %v", g.Productions[:1])
+	}
+	if g.Table["Stmt"]["let"] != production(t, "Stmt → VarDecl") || g.Table["DeclList"]["$"] != production(t, "DeclList → ε") {
+		t.Errorf("unexpected table entries %v %v", g.Table["Stmt"], g.Table["DeclList"])
+	}
+	if got := strings.Join(g.First["Type"], " "); got != "id [ list int float bool string" {
+		t.Errorf("unexpected FIRST(Type) %s", got)
+	}
+}
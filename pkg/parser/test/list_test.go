@@ -0,0 +1,116 @@
+package parser_test
+
+import (
+	"dolme/pkg/interpreter"
+	"dolme/pkg/parser"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLists(t *testing.T) {
+	src := `func push(xs: list[int], v: int): int {
+    append(xs, v);
+    return len(xs);
+}
+func squares(n: int): list[int] {
+    let out : list[int] = [];
+    let i : int = 0;
+    while (i < n) {
+        append(out, i * i);
+        i = i + 1;
+    }
+    return out;
+}
+let xs : list[int] = [1, 2];
+print(push(xs, 3));
+xs[0] = xs[1] + xs[2];
+print(xs[0]);
+let sq : list[int] = squares(5);
+print(sq[4] + len(sq));
+let fs : list[float] = [1, 2.5];
+append(fs, 4);
+print(fs[2]);
+let names : list[string] = [];
+append(names, "b");
+append(names, "a");
+print(names[1] + names[0]);
+`
+	want := "3\n5\n21\n4.00000000000000000000\nab\n"
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		if got := run(t, src, a); got != want {
+			t.Errorf("%s: unexpected output %q", a, got)
+		}
+	}
+}
+
+func TestListBounds(t *testing.T) {
+	src := `let xs : list[int] = [];
+append(xs, 7);
+print(xs[0]);
+print(xs[1]);
+`
+	p := parseWith(src, parser.LL1)
+	if errs := append(p.Errors(), p.GetSemanticErrors()...); len(errs) > 0 {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	var out strings.Builder
+	err := interpreter.NewInterpreter(p.GetIRCode(), interpreter.WithWriter(&out)).Run()
+	if err == nil || err.Error() != "index out of range [1] with length 1 at line 4" {
+		t.Errorf("expected an out of range error at line 4, got %v", err)
+	}
+	if out.String() != "7\n" {
+		t.Errorf("unexpected output before the error %q", out.String())
+	}
+}
+
+func TestListTypes(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"let xs : list[int] = [1, 2.5];\n", "Type mismatch expected list[int], found [2]float"},
+		{"let xs : list[int] = [];\nappend(xs, true);\n", "Type mismatch expected int, found bool"},
+		{"let xs : [2]int = [];\nappend(xs, 1);\n", "Cannot append to a value of type [2]int"},
+		{"let xs : list[int] = [];\nlet ys : list[float] = xs;\n", "Type mismatch expected list[float], found list[int]"},
+		{"let xs : list[int] = [];\nlet n : int = xs + 1;\n", "Type mismatch expected int, found list[int]"},
+		{"func f(xs: list[int]): int { return 0; }\nlet ys : [2]int = [];\nlet n : int = f(ys);\n", "Type mismatch expected list[int], found [2]int"},
+	}
+
+	for _, tt := range tests {
+		expectSemanticError(t, tt.src, tt.expected)
+	}
+}
+
+func TestHeapLimit(t *testing.T) {
+	// every pair is garbage once the next iteration starts, so the
+	// collector keeps the heap far below the limit
+	garbage := `let k : int = 0;
+while (k < 10000) {
+    let t : list[int] = [k, k];
+    k = k + 1;
+}
+print(k);
+`
+	p := parseWith(garbage, parser.LL1)
+	var out strings.Builder
+	if err := interpreter.NewInterpreter(p.GetIRCode(), interpreter.WithWriter(&out), interpreter.WithHeapLimit(1024)).Run(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.String() != "10000\n" {
+		t.Errorf("unexpected output %q", out.String())
+	}
+
+	// one reachable list that keeps growing cannot be collected
+	growing := `let xs : list[int] = [];
+while (true) {
+    append(xs, 1);
+}
+`
+	p = parseWith(growing, parser.LL1)
+	err := interpreter.NewInterpreter(p.GetIRCode(), interpreter.WithHeapLimit(1024)).Run()
+	if !errors.Is(err, interpreter.ErrHeapLimitExceeded) {
+		t.Errorf("expected the heap limit to be exceeded, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package parser_test
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+const program = `func fib(n: int): int {
+    if (n < 2) {
+        return n;
+    }
+    return fib(n - 1) + fib(n - 2);
+}
+
+func report(v: float, ok: bool): int {
+    if (not ok or v >= 10.0 and true) {
+        print(v);
+    } else {
+        return 0;
+    }
+    return 1;
+}
+
+let x : int = fib(10) * (3 - 1) % 7;
+let f : float = 2.5 / 0.5;
+let b : bool = false;
+let r : int = report(f, true);
+while (x > 0) {
+    if (x == 5) {
+        break;
+    }
+    x = x - 1;
+    if (x != 3) {
+        continue;
+    }
+    print(x);
+}
+print(r);
+`
+
+var position = regexp.MustCompile(`Line: \d+, Column \d+`)
+
+// parseWith parses src with the given algorithm
+func parseWith(src string, a parser.Algorithm) *parser.Parser {
+	p := parser.NewParser(lexer.NewLexer(src), parser.WithAlgorithm(a))
+	p.Parse()
+
+	return p
+}
+
+// sources returns the test program and the examples
+func sources(t *testing.T) map[string]string {
+	t.Helper()
+
+	srcs := map[string]string{"program": program}
+	examples, _ := filepath.Glob("../../../example/*.dolme")
+	for _, path := range examples {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		srcs[filepath.Base(path)] = string(src)
+	}
+
+	return srcs
+}
+
+func TestLRTables(t *testing.T) {
+	lalr, lr1 := parser.NewLRTable(true), parser.NewLRTable(false)
+	if lalr.States() >= lr1.States() {
+		t.Errorf("expected LALR(1) to merge states: %d LALR(1) states, %d LR(1) states", lalr.States(), lr1.States())
+	}
+
+	markers := 0
+	for _, prod := range lalr.Productions {
+		if prod.Action != "" {
+			markers++
+			if len(prod.RHS) != 0 || strings.HasPrefix(prod.LHS, "@") {
+				t.Errorf("marker %s should derive ε", prod)
+			}
+		}
+		for _, sym := range prod.RHS {
+			if strings.HasPrefix(sym, "@") {
+				t.Errorf("%s still contains a semantic action", prod)
+			}
+		}
+	}
+	if markers == 0 {
+		t.Errorf("expected semantic actions to become markers")
+	}
+}
+
+func TestLRConflict(t *testing.T) {
+	// the dangling else: E → if E | if E else E | id
+	g := []parser.Production{
+		{},
+		{LHS: "E", RHS: []string{"if", "E", "@jmpf_normal"}},
+		{LHS: "E", RHS: []string{"if", "E", "else", "E", "@jmp"}},
+		{LHS: "E", RHS: []string{"id", "@load"}},
+	}
+
+	for _, lalr := range []bool{true, false} {
+		_, err := parser.BuildLRTable(g, lalr)
+		if err == nil {
+			t.Fatalf("lalr=%v: expected a conflict", lalr)
+		}
+		for _, want := range []string{`on "else"`, "shift to state", "reduce jmpf_normal#1 → ε (production 1)"} {
+			if !strings.Contains(err.Error(), want) {
+				t.Errorf("lalr=%v: expected the error to mention %q, got %q", lalr, want, err)
+			}
+		}
+	}
+}
+
+func TestLRMatchesLL(t *testing.T) {
+	for name, src := range sources(t) {
+		ll := parseWith(src, parser.LL1)
+		if errs := ll.Errors(); len(errs) > 0 {
+			t.Fatalf("%s: syntax errors: %v", name, errs)
+		}
+
+		for _, a := range []parser.Algorithm{parser.LALR1, parser.LR1} {
+			lr := parseWith(src, a)
+			if errs := lr.Errors(); len(errs) > 0 {
+				t.Errorf("%s (%s): syntax errors: %v", name, a, errs)
+				continue
+			}
+			if !reflect.DeepEqual(lr.GetIRCode(), ll.GetIRCode()) {
+				t.Errorf("%s (%s): IR differs from LL(1)\nwant %v\ngot  %v", name, a, ll.GetIRCode(), lr.GetIRCode())
+			}
+			if !reflect.DeepEqual(lr.GetSemanticErrors(), ll.GetSemanticErrors()) {
+				t.Errorf("%s (%s): semantic errors differ\nwant %v\ngot  %v", name, a, ll.GetSemanticErrors(), lr.GetSemanticErrors())
+			}
+		}
+	}
+}
+
+func TestLRMatchesLLOnMutations(t *testing.T) {
+	for name, src := range sources(t) {
+		toks := strings.Fields(src)
+
+		// dropping any one word either leaves a program both parsers translate
+		// the same way, or both reject it at the same token
+		for i := range toks {
+			in := strings.Join(append(append([]string{}, toks[:i]...), toks[i+1:]...), " ")
+			ll := parseWith(in, parser.LL1)
+			lr := parseWith(in, parser.LALR1)
+
+			llErrs, lrErrs := ll.Errors(), lr.Errors()
+			switch {
+			case len(llErrs) == 0 && len(lrErrs) == 0:
+				if !reflect.DeepEqual(lr.GetIRCode(), ll.GetIRCode()) {
+					t.Errorf("%s without %q: IR differs from LL(1)", name, toks[i])
+				}
+			case len(llErrs) == 0 || len(lrErrs) == 0:
+				t.Errorf("%s without %q: LL(1) errors %v, LALR(1) errors %v", name, toks[i], llErrs, lrErrs)
+			case position.FindString(llErrs[0]) != position.FindString(lrErrs[0]):
+				t.Errorf("%s without %q: LL(1) fails at %s, LALR(1) at %s", name, toks[i], position.FindString(llErrs[0]), position.FindString(lrErrs[0]))
+			}
+		}
+	}
+}
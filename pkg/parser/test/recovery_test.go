@@ -0,0 +1,111 @@
+package parser_test
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+var ansi = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// parse parses src and returns its syntax errors without colors
+func parse(src string) (*parser.Parser, []string) {
+	p := parser.NewParser(lexer.NewLexer(src))
+	p.Parse()
+
+	errs := make([]string, 0, len(p.Errors()))
+	for _, e := range p.Errors() {
+		errs = append(errs, ansi.ReplaceAllString(e, ""))
+	}
+
+	return p, errs
+}
+
+func TestRecoveryReportsEveryError(t *testing.T) {
+	src := `let x : int = 5
+let y : int = 3 4;
+func f(a: int : int {
+    let z : int = ;
+    return a;
+}
+if (x > 1 {
+    print(x);
+}
+while () {
+    y = y + ;
+}
+}
+let k : int = f(1;
+print(k);
+func g(): int {
+    return k
+`
+	expected := []string{
+		"Missing semicolon at Line: 2, Column 1",
+		"Line: 2, Column 17",
+		"Line: 3, Column 15",
+		"Missing expression at Line: 4, Column 19",
+		"Line: 7, Column 11",
+		"Empty condition at Line: 10, Column 8",
+		"Missing expression at Line: 11, Column 13",
+		"Line: 13, Column 1",
+		"Missing closing parenthesis at Line: 14, Column 18",
+		"Missing semicolon at Line: 18, Column 1",
+	}
+
+	_, errs := parse(src)
+	if len(errs) != len(expected) {
+		t.Fatalf("expected %d errors, got %d:\n%s", len(expected), len(errs), strings.Join(errs, "\n"))
+	}
+	for i, want := range expected {
+		if !strings.Contains(errs[i], want) {
+			t.Errorf("error %d: expected %q, got %q", i, want, errs[i])
+		}
+	}
+}
+
+func TestRecoveryStopsCodegen(t *testing.T) {
+	src := `let a : int = 1;
+print(a);
+let b : int = ;
+let c : int = undefined + 2.5;
+print(c);
+`
+	p, errs := parse(src)
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 syntax error, got %v", errs)
+	}
+	if sem := p.GetSemanticErrors(); len(sem) != 0 {
+		t.Errorf("expected no semantic errors after the syntax error, got %v", sem)
+	}
+
+	// only the two statements before the error are translated
+	if n := len(p.GetIRCode()); n > 4 {
+		t.Errorf("expected code generation to stop at the error, got %d instructions", n)
+	}
+}
+
+func TestRecoveryTerminates(t *testing.T) {
+	examples, _ := filepath.Glob("../../../example/*.dolme")
+	if len(examples) == 0 {
+		t.Skip("no examples")
+	}
+
+	for _, path := range examples {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		toks := strings.Fields(string(src))
+
+		// every prefix and every single-token deletion must parse to completion
+		for i := range toks {
+			parse(strings.Join(toks[:i], " "))
+			parse(strings.Join(append(append([]string{}, toks[:i]...), toks[i+1:]...), " "))
+		}
+	}
+}
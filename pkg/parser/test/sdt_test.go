@@ -0,0 +1,68 @@
+package parser_test
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"dolme/pkg/parser/codegen"
+	"strings"
+	"testing"
+)
+
+func TestSDTTrace(t *testing.T) {
+	src := "let i : int = 0;\nwhile (i < 3) {\n  if (i == 1) { break; }\n  i = i + 1;\n}\n"
+
+	var sdt codegen.SDTTrace
+	p := parser.NewParser(lexer.NewLexer(src), parser.WithSDTTrace(&sdt))
+	p.Parse()
+
+	if first := sdt[0]; first.Action != "@capture_decl_var" || first.Token.Lexeme != "i" || len(first.Before) != 0 || strings.Join(first.After, " ") != "i" {
+		t.Errorf("unexpected first action %+v", first)
+	}
+
+	// every instruction of the program is appended by exactly one action
+	appended := 0
+	for _, s := range sdt {
+		for _, ch := range s.Changes {
+			if ch.Old == nil {
+				appended++
+			}
+		}
+	}
+	if appended != len(p.GetCG().GetProgram()) {
+		t.Errorf("expected %d appended instructions, got %d", len(p.GetCG().GetProgram()), appended)
+	}
+
+	// @jmpf_break fills in the while condition's jmpf and the break's jmp
+	var patched []codegen.Operation
+	for _, s := range sdt {
+		if s.Action != "@jmpf_break" {
+			continue
+		}
+		if strings.Join(s.After, " ") != "$while_1" {
+			t.Errorf("expected only the loop label to remain, got %v", s.After)
+		}
+		for _, ch := range s.Changes {
+			if !ch.Backpatch() {
+				t.Errorf("expected a backpatch, got %+v", ch)
+			}
+			patched = append(patched, ch.New.Op)
+		}
+	}
+	if len(patched) != 2 || patched[0] != codegen.OpJmpf || patched[1] != codegen.OpJmp {
+		t.Errorf("expected @jmpf_break to backpatch a jmpf and a jmp, got %v", patched)
+	}
+
+	if text := sdt.Text(); !strings.Contains(text, "~ 7: (nop, , , , $) ⇒ (jmp, , , 11, $)  <- backpatch") {
+		t.Errorf("expected the text trace to mark the break backpatch:\n%s", text)
+	}
+
+	html, err := sdt.HTML()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{`<div class="backpatch">`, `<span class="marker">$while_1</span>`} {
+		if !strings.Contains(html, want) {
+			t.Errorf("expected the HTML trace to contain %q", want)
+		}
+	}
+}
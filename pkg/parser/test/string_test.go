@@ -0,0 +1,63 @@
+package parser_test
+
+import (
+	"dolme/pkg/parser"
+	"strings"
+	"testing"
+)
+
+func TestStrings(t *testing.T) {
+	src := `func greet(name: string): string {
+    return "hello, " + name + "!";
+}
+let s : string = greet("dolme");
+print(s);
+print(len(s));
+let t : string = "a\tb";
+t = t + "\n" + "c";
+print(t);
+print(s == "hello, dolme!" and t != s);
+print("say \"hi\"");
+`
+	want := "hello, dolme!\n13\na\tb\nc\ntrue\nsay \"hi\"\n"
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		if got := run(t, src, a); got != want {
+			t.Errorf("%s: unexpected output %q", a, got)
+		}
+	}
+}
+
+func TestStringTypes(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"let s : string = 1;\n", "Type mismatch expected string, found int"},
+		{"let s : string = \"a\" + 1;\n", "Type mismatch expected string, found int"},
+		{"let s : string = \"a\" - \"b\";\n", "Cannot apply `-` to strings, which only support `+`"},
+		{"let b : bool = \"a\" < \"b\";\n", "Cannot apply `<` to strings, which only support `==` and `!=`"},
+		{"let b : bool = \"a\" >= 1;\n", "Cannot apply `>=` to strings, which only support `==` and `!=`"},
+		{"let b : bool = \"a\" == 1.5;\n", "Type mismatch expected string, found float"},
+		{"let n : int = len(true);\n", "Type mismatch expected string, found bool"},
+		{"func f(s: string): int { return len(s); }\nprint(f(3));\n", "Type mismatch expected string, found int"},
+		{"func f(n: int): int { return n; }\nprint(f(\"a\"));\n", "Type mismatch expected int, found string"},
+	}
+
+	for _, tt := range tests {
+		expectSemanticError(t, tt.src, tt.expected)
+	}
+}
+
+func TestInvalidEscape(t *testing.T) {
+	_, errs := parse("let s : string = \"a\\qb\";\n")
+	if len(errs) != 1 || !strings.HasPrefix(errs[0], "Invalid escape sequence in string at Line: 1, Column 18") {
+		t.Errorf("expected an invalid escape error, got %v", errs)
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	_, errs := parse("let s : string = \"abc\n")
+	if len(errs) == 0 || !strings.HasPrefix(errs[0], "Unterminated string at Line: 1, Column 18") {
+		t.Errorf("expected an unterminated string error, got %v", errs)
+	}
+}
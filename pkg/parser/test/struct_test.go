@@ -0,0 +1,129 @@
+package parser_test
+
+import (
+	"dolme/pkg/parser"
+	"testing"
+)
+
+func TestStructs(t *testing.T) {
+	src := `struct Point { x: float, y: float }
+struct Particle { pos: Point, mass: float, name: string, hits: list[int], alive: bool }
+func norm2(p: Point): float {
+    return p.x * p.x + p.y * p.y;
+}
+func mid(a: Point, b: Point): Point {
+    return Point { x: (a.x + b.x) / 2, y: (a.y + b.y) / 2 };
+}
+func push(p: Particle, v: float): int {
+    p.pos.x = p.pos.x + v;
+    append(p.hits, 1);
+    return len(p.hits);
+}
+let a : Point = Point { x: 1, y: 2.5 };
+let b : Point = Point { y: 0.5, x: 3 };
+print(norm2(a));
+let m : Point = mid(a, b);
+print(m.y);
+let p : Particle = Particle { pos: a, name: "p1" };
+print(push(p, 2));
+print(push(p, 2));
+print(a.x);
+print(p.name + "!");
+print(p.alive);
+let c : Point = a;
+c.y = 10;
+print(a.y);
+let e : Particle = Particle {};
+print(len(e.name) + len(e.hits));
+print(e.pos.y);
+`
+	want := "7.25000000000000000000\n1.50000000000000000000\n1\n2\n5.00000000000000000000\np1!\nfalse\n10.00000000000000000000\n0\n0.00000000000000000000\n"
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		if got := run(t, src, a); got != want {
+			t.Errorf("%s: unexpected output %q", a, got)
+		}
+	}
+}
+
+func TestStructTypes(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"let p : Point = 1;\n", "Unknown type `Point`"},
+		{"struct P { x: int }\nstruct P { y: int }\n", "Redeclaration of type `P`"},
+		{"struct P { x: int, x: float }\n", "Duplicate field `x` in `P`"},
+		{"struct P { xs: [2]int }\n", "Arrays cannot be fields"},
+		{"struct P { x: int }\nlet p : P = P {};\nprint(p.y);\n", "No field `y` in a value of type P"},
+		{"struct P { x: int }\nlet p : P = P { x: 1, y: 2 };\n", "No field `y` in a value of type P"},
+		{"struct P { x: int }\nlet p : P = P { x: 1, x: 2 };\n", "Duplicate field `x` in `P`"},
+		{"struct P { x: int }\nlet p : P = P { x: 1.5 };\n", "Type mismatch expected int, found float"},
+		{"struct P { x: int }\nstruct Q { x: int }\nlet p : P = Q {};\n", "Type mismatch expected P, found Q"},
+		{"struct P { x: int }\nlet p : P = P {};\nlet n : int = p + 1;\n", "Type mismatch expected int, found P"},
+		{"struct P { x: int }\nfunc f(): P { return 3; }\n", "Type mismatch expected P, found int"},
+		{"let n : int = 1;\nprint(n.x);\n", "No field `x` in a value of type int"},
+	}
+
+	for _, tt := range tests {
+		expectSemanticError(t, tt.src, tt.expected)
+	}
+}
+
+func TestMethods(t *testing.T) {
+	src := `struct Point { x: float, y: float }
+struct Body { pos: Point }
+func (p: Point) norm2(): float {
+    return p.x * p.x + p.y * p.y;
+}
+func (p: Point) add(q: Point): Point {
+    return Point { x: p.x + q.x, y: p.y + q.y };
+}
+func (p: Point) scale(k: float): int {
+    p.x = p.x * k;
+    p.y = p.y * k;
+    return 0;
+}
+func (p: Point) fact(n: int): int {
+    if (n <= 1) {
+        return 1;
+    }
+    return n * p.fact(n - 1);
+}
+func norm2(n: int): int {
+    return n * n;
+}
+let a : Point = Point { x: 1, y: 2 };
+let b : Point = Point { x: 3, y: 4 };
+print(a.norm2());
+print(a.add(b).norm2());
+print(a.add(b).x);
+let r : int = a.scale(2);
+print(a.y);
+let body : Body = Body { pos: b };
+print(body.pos.norm2() + 1);
+print(a.fact(5) + norm2(3));
+`
+	want := "5.00000000000000000000\n52.00000000000000000000\n4.00000000000000000000\n4.00000000000000000000\n26.00000000000000000000\n129\n"
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		if got := run(t, src, a); got != want {
+			t.Errorf("%s: unexpected output %q", a, got)
+		}
+	}
+}
+
+func TestMethodErrors(t *testing.T) {
+	tests := []struct {
+		src      string
+		expected string
+	}{
+		{"struct P { x: int }\nlet p : P = P {};\nprint(p.norm());\n", "No method `norm` on type P"},
+		{"struct P { x: int }\nstruct Q { x: int }\nfunc (q: Q) norm(): int { return q.x; }\nlet p : P = P {};\nprint(p.norm());\n", "No method `norm` on type P"},
+		{"let n : int = 1;\nprint(n.abs());\n", "No method `abs` on type int"},
+		{"func (n: int) abs(): int { return n; }\n", "Methods need a struct receiver, `abs` has one of type int"},
+		{"struct P { x: int }\nfunc (p: P) set(q: P): int { return 0; }\nlet p : P = P {};\nprint(p.set(1));\n", "Type mismatch expected P, found int"},
+	}
+
+	for _, tt := range tests {
+		expectSemanticError(t, tt.src, tt.expected)
+	}
+}
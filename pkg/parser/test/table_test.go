@@ -0,0 +1,135 @@
+package parser_test
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestFirstFollow(t *testing.T) {
+	g := parser.Grammar()
+	first := parser.FirstSets(g)
+	follow := parser.FollowSets(g, first)
+
+	tests := []struct {
+		sets     map[string]parser.SymbolSet
+		symbol   string
+		expected []string
+	}{
+		{first, "Type", []string{"[", "bool", "float", "id", "int", "list", "string"}},
+		{first, "Expr", []string{"(", "+", "-", "[", "false", "id", "len", "not", "num", "strlit", "true"}},
+		{first, "ArithExpr", []string{"(", "+", "-", "[", "false", "id", "len", "num", "strlit", "true"}},
+		{first, "NotExpr", []string{"(", "+", "-", "[", "false", "id", "len", "not", "num", "strlit", "true"}},
+		{first, "ElsePart", []string{"else", "ε"}},
+		{first, "ArgList", []string{"(", "+", "-", "[", "false", "id", "len", "not", "num", "strlit", "true", "ε"}},
+		{follow, "Program", []string{"$"}},
+		{follow, "StmtList", []string{"}"}},
+		{follow, "Param", []string{")", ","}},
+		{follow, "ReturnValue", []string{";"}},
+		{follow, "AndExpr", []string{")", ",", ";", "]", "or", "}"}},
+		{follow, "Term", []string{"!=", ")", "+", ",", "-", ";", "<", "<=", "==", ">", ">=", "]", "and", "or", "}"}},
+	}
+
+	for _, tt := range tests {
+		if got := tt.sets[tt.symbol].Sorted(); !reflect.DeepEqual(got, tt.expected) {
+			t.Errorf("%s: expected %v, got %v", tt.symbol, tt.expected, got)
+		}
+	}
+}
+
+func TestParsingTable(t *testing.T) {
+	table := parser.NewParsingTable()
+
+	tests := []struct {
+		nonTerminal string
+		token       lexer.TokenType
+		rule        string
+	}{
+		{"Program", lexer.FUNC, "Program → DeclList"},
+		{"DeclList", lexer.EOF, "DeclList → ε"},
+		{"Decl", lexer.LET, "Decl → Stmt"},
+		{"StmtList", lexer.RBRACE, "StmtList → ε"},
+		{"AssignSuffix", lexer.LPAREN, "AssignSuffix → ( ArgList ) @call"},
+		{"AssignSuffix", lexer.LSBRACE, "AssignSuffix → [ Expr ] @bounds = Expr @index_assign"},
+		{"ElsePart", lexer.ELSE, "ElsePart → @jmpf @save else { StmtList } @jmp"},
+		{"ElsePart", lexer.FUNC, "ElsePart → @jmpf_normal ε"},
+		{"ReturnValue", lexer.SEMICOLON, "ReturnValue → ε"},
+		{"ReturnValue", lexer.NOT, "ReturnValue → Expr"},
+		{"OrExpr'", lexer.OR, "OrExpr' → or AndExpr @or OrExpr'"},
+		{"NotExpr", lexer.NOT, "NotExpr → not NotExpr @not"},
+		{"NotExpr", lexer.TRUE, "NotExpr → RelExpr"},
+		{"RelOp", lexer.NE, "RelOp → != @push_relop"},
+		{"ArithExpr'", lexer.LT, "ArithExpr' → ε"},
+		{"Unary", lexer.MINUS, "Unary → - Unary @neg"},
+		{"Unary", lexer.NUM, "Unary → Factor"},
+		{"FactorSuffix", lexer.LPAREN, "FactorSuffix → @call_start ( ArgList ) @call_end"},
+		{"FactorSuffix", lexer.MULT, "FactorSuffix → @load"},
+		{"FactorSuffix", lexer.LSBRACE, "FactorSuffix → @load [ Expr ] @bounds @index"},
+		{"ArgList", lexer.NOT, "ArgList → Expr @arg ArgList'"},
+		{"ArgList", lexer.MINUS, "ArgList → Expr @arg ArgList'"},
+		{"Type", lexer.STR, "Type → Scalar"},
+		{"Type", lexer.LSBRACE, "Type → [ num @array_len ] Scalar"},
+		{"Scalar", lexer.STR, "Scalar → string"},
+		{"Factor", lexer.STRING, "Factor → strlit @push"},
+		{"Factor", lexer.LEN, "Factor → len ( Expr ) @len"},
+		{"Factor", lexer.LSBRACE, "Factor → [ @array_start Elems ] @array_lit"},
+		{"Elems", lexer.RSBRACE, "Elems → ε"},
+		{"PrintStmt", lexer.PRINT, "PrintStmt → print ( Expr ) ; @print"},
+		{"Type", lexer.LIST, "Type → list [ Scalar @list_type ]"},
+		{"Stmt", lexer.APPEND, "Stmt → AppendStmt"},
+		{"AppendStmt", lexer.APPEND, "AppendStmt → append ( Expr , Expr ) ; @append"},
+		{"Decl", lexer.STRUCT, "Decl → StructDecl"},
+		{"Decl", lexer.ENUM, "Decl → EnumDecl"},
+		{"Stmt", lexer.MATCH, "Stmt → MatchStmt"},
+		{"Arms", lexer.ID, "Arms → Arm Arms"},
+		{"Arms", lexer.RBRACE, "Arms → ε"},
+		{"Type", lexer.ID, "Type → id"},
+		{"AssignSuffix", lexer.DOT, "AssignSuffix → . id FieldTarget = Expr @field_assign"},
+		{"FieldTarget", lexer.DOT, "FieldTarget → @field . id FieldTarget"},
+		{"FieldTarget", lexer.ASSIGN, "FieldTarget → @field_target"},
+		{"FactorSuffix", lexer.DOT, "FactorSuffix → @load . id Member"},
+		{"FactorSuffix", lexer.LBRACE, "FactorSuffix → @struct_lit_start { FieldInits } @struct_lit"},
+		{"FuncName", lexer.ID, "FuncName → id @func_start"},
+		{"FuncName", lexer.LPAREN, "FuncName → ( id @capture_param_name : Type @capture_type ) id @method_start"},
+		{"Member", lexer.DOT, "Member → @field FieldSuffix"},
+		{"Member", lexer.LPAREN, "Member → @method_call ( ArgList ) @call_end FieldSuffix"},
+	}
+
+	for _, tt := range tests {
+		got, ok := table[tt.nonTerminal][tt.token]
+		if !ok {
+			t.Errorf("%s on %s: no entry, expected %s", tt.nonTerminal, tt.token, tt.rule)
+			continue
+		}
+		if got.String() != tt.rule {
+			t.Errorf("%s on %s: expected %s, got %s", tt.nonTerminal, tt.token, tt.rule, got)
+		}
+	}
+}
+
+func TestParsingTableConflict(t *testing.T) {
+	g := []parser.Production{
+		{},
+		{LHS: "S", RHS: []string{"A", "@done"}},
+		{LHS: "A", RHS: []string{"id", "@load"}},
+		{LHS: "A", RHS: []string{"B"}},
+		{LHS: "B", RHS: []string{"id", "=", "num"}},
+	}
+
+	_, err := parser.BuildParsingTable(g)
+	if err == nil {
+		t.Fatal("expected an LL(1) conflict")
+	}
+	for _, want := range []string{`A on "id"`, "production 2 (A → id @load)", "production 3 (A → B)"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected the error to mention %q, got %q", want, err)
+		}
+	}
+
+	g[4].RHS = []string{"id", "=", "nosuch"}
+	if _, err := parser.BuildParsingTable(g); err == nil || !strings.Contains(err.Error(), `"nosuch"`) {
+		t.Errorf("expected an unknown symbol error, got %v", err)
+	}
+}
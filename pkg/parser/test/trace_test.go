@@ -0,0 +1,133 @@
+package parser_test
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// trace parses src with the given algorithm and returns the recorded steps
+func trace(src string, a parser.Algorithm) (parser.Trace, *parser.Parser) {
+	var tr parser.Trace
+	p := parser.NewParser(lexer.NewLexer(src), parser.WithAlgorithm(a), parser.WithTrace(&tr))
+	p.Parse()
+
+	return tr, p
+}
+
+func TestTrace(t *testing.T) {
+	src := "let x : int = 1;\nprint(x);\n"
+	tr, _ := trace(src, parser.LL1)
+
+	first := tr[0]
+	if first.Kind != parser.StepExpand || first.Production != production(t, "Program → DeclList") || strings.Join(first.Stack, " ") != "$ Program" || first.Token.Type != lexer.LET {
+		t.Errorf("unexpected first step %+v", first)
+	}
+
+	counts := map[parser.StepKind]int{}
+	for _, s := range tr {
+		counts[s.Kind]++
+	}
+	// let x : int = 1 ; print ( x ) ;
+	if counts[parser.StepMatch] != 12 {
+		t.Errorf("expected 12 matched tokens, got %d", counts[parser.StepMatch])
+	}
+	// capture_decl_var, capture_type, push, define, load, print
+	if counts[parser.StepAction] != 6 {
+		t.Errorf("expected 6 semantic actions, got %d", counts[parser.StepAction])
+	}
+	if counts[parser.StepError] != 0 {
+		t.Errorf("expected no errors, got %d", counts[parser.StepError])
+	}
+
+	// the action runs once the token it reads has been matched
+	for i, s := range tr {
+		if s.Kind == parser.StepAction && s.Symbol == "@capture_decl_var" {
+			if prev := tr[i-1]; prev.Kind != parser.StepMatch || prev.Token.Lexeme != "x" {
+				t.Errorf("expected @capture_decl_var right after matching x, got %+v", prev)
+			}
+		}
+	}
+
+	text := tr.Text()
+	varDecl := "VarDecl → let id @capture_decl_var : Type @capture_type = Expr ; @define"
+	if !strings.Contains(text, fmt.Sprintf("expand %d: %s", production(t, varDecl), varDecl)) {
+		t.Errorf("expected the text trace to show the VarDecl expansion:\n%s", text)
+	}
+
+	out, err := tr.CSV()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(out)).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != len(tr)+1 || rows[0][0] != "step" || rows[1][6] != "expand" {
+		t.Errorf("unexpected CSV with %d rows, header %v", len(rows), rows[0])
+	}
+
+	data, err := tr.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var steps []map[string]any
+	if err := json.Unmarshal(data, &steps); err != nil {
+		t.Fatal(err)
+	}
+	if len(steps) != len(tr) || steps[0]["rule"] != "Program → DeclList" || steps[0]["production"] != float64(production(t, "Program → DeclList")) {
+		t.Errorf("unexpected JSON first step %v", steps[0])
+	}
+}
+
+func TestTraceErrors(t *testing.T) {
+	tr, p := trace("let x : int = 1\nprint(x);\n", parser.LL1)
+
+	errs := 0
+	skipped := 0
+	for _, s := range tr {
+		switch {
+		case s.Kind == parser.StepError:
+			errs++
+			if s.Message != "Missing semicolon" || s.Token.Type != lexer.PRINT {
+				t.Errorf("unexpected error step %+v", s)
+			}
+		case s.Kind == parser.StepAction && s.Message != "":
+			skipped++
+		}
+	}
+	if errs != len(p.Errors()) {
+		t.Errorf("expected %d error steps, got %d", len(p.Errors()), errs)
+	}
+	if skipped == 0 {
+		t.Errorf("expected the actions after the error to be reported as skipped")
+	}
+}
+
+func TestTraceLR(t *testing.T) {
+	tr, _ := trace("let x : int = 1;\n", parser.LALR1)
+
+	if last := tr[len(tr)-1]; last.Kind != parser.StepAccept {
+		t.Fatalf("expected the trace to end with accept, got %+v", last)
+	}
+
+	shifts, actions := 0, 0
+	for _, s := range tr {
+		switch {
+		case s.Kind == parser.StepShift:
+			shifts++
+		case s.Kind == parser.StepReduce && s.Symbol != "":
+			actions++
+		}
+	}
+	if shifts != 7 || actions != 4 {
+		t.Errorf("expected 7 shifts and 4 marker reductions, got %d and %d", shifts, actions)
+	}
+	if st := tr[1].Stack; len(st) != 3 || st[0] != "0" || st[1] != "let" {
+		t.Errorf("expected the stack to interleave states and symbols, got %q", st)
+	}
+}
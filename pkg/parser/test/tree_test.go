@@ -0,0 +1,102 @@
+package parser_test
+
+import (
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// tree parses src with the given algorithm and returns its parse tree
+func tree(src string, a parser.Algorithm) (*parser.TreeNode, *parser.Parser) {
+	p := parser.NewParser(lexer.NewLexer(src), parser.WithAlgorithm(a), parser.WithParseTree())
+	p.Parse()
+
+	return p.ParseTree(), p
+}
+
+// leaves lists the leaves of n in order, tokens by lexeme
+func leaves(n *parser.TreeNode) []string {
+	if len(n.Children) == 0 {
+		if n.Kind == parser.NodeTerminal {
+			return []string{n.Token.Lexeme}
+		}
+		return []string{n.Symbol}
+	}
+
+	out := make([]string, 0)
+	for _, c := range n.Children {
+		out = append(out, leaves(c)...)
+	}
+	return out
+}
+
+func TestParseTree(t *testing.T) {
+	root, _ := tree("let x : int = 1;\nif (x > 0) { print(x); }\n", parser.LL1)
+
+	if root.Symbol != "Program" || root.Production != production(t, "Program → DeclList") {
+		t.Fatalf("unexpected root %+v", root)
+	}
+
+	want := "let x @capture_decl_var : int @capture_type = 1 @push ε ε ε ε ε ; @define " +
+		"if ( x @load ε ε > @push_relop 0 @push ε ε @rel ε ε ) @save { print ( x @load ε ε ε ε ε ) ; @print ε } @jmpf_normal ε"
+	if got := strings.Join(leaves(root), " "); got != want {
+		t.Errorf("unexpected leaves\nwant %s\ngot  %s", want, got)
+	}
+
+	// Decl → Stmt → VarDecl
+	decl := root.Children[0].Children[0]
+	stmt, varDecl := decl.Children[0], decl.Children[0].Children[0]
+	if decl.Production != production(t, "Decl → Stmt") || stmt.Production != production(t, "Stmt → VarDecl") ||
+		varDecl.Production != production(t, "VarDecl → let id @capture_decl_var : Type @capture_type = Expr ; @define") {
+		t.Errorf("unexpected derivation of the declaration")
+	}
+
+	dot := root.DOT()
+	for _, want := range []string{"digraph parsetree {", "ordering=out;", `label="id\nx", shape=box`, `label="@save", shape=note`} {
+		if !strings.Contains(dot, want) {
+			t.Errorf("expected the DOT output to contain %q", want)
+		}
+	}
+
+	data, err := root.JSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var doc struct {
+		Kind     string `json:"kind"`
+		Rule     string `json:"rule"`
+		Children []any  `json:"children"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if doc.Kind != "nonterminal" || doc.Rule != "Program → DeclList" || len(doc.Children) != 1 {
+		t.Errorf("unexpected JSON root %+v", doc)
+	}
+}
+
+func TestParseTreeLRMatchesLL(t *testing.T) {
+	for name, src := range sources(t) {
+		ll, _ := tree(src, parser.LL1)
+		for _, a := range []parser.Algorithm{parser.LALR1, parser.LR1} {
+			if lr, _ := tree(src, a); !reflect.DeepEqual(lr, ll) {
+				t.Errorf("%s: %s tree differs from LL(1)", name, a)
+			}
+		}
+	}
+}
+
+func TestParseTreeAfterError(t *testing.T) {
+	for _, a := range []parser.Algorithm{parser.LL1, parser.LALR1} {
+		root, p := tree("let x : int = 1\nprint(x);\n", a)
+		if len(p.Errors()) == 0 {
+			t.Fatalf("%s: expected a syntax error", a)
+		}
+		if got := leaves(root); len(got) < 2 || got[0] != "let" || got[1] != "x" {
+			t.Errorf("%s: expected the tree to keep what was parsed, got %v", a, got)
+		}
+	}
+}
@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"bytes"
+	"dolme/pkg/lexer"
+	"dolme/pkg/parser/codegen"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+)
+
+// StepKind is what the parser did in one step
+type StepKind string
+
+const (
+	StepExpand StepKind = "expand" // LL(1): replace a non-terminal by a production
+	StepMatch  StepKind = "match"  // LL(1): a terminal matched the current token
+	StepAction StepKind = "action" // a semantic action ran
+	StepShift  StepKind = "shift"  // LR: push the current token
+	StepReduce StepKind = "reduce" // LR: pop a right-hand side and push its left-hand side
+	StepAccept StepKind = "accept" // LR: the input is a Program
+	StepError  StepKind = "error"  // no move for the current token
+)
+
+// Step is one iteration of the parse loop
+type Step struct {
+	Stack      []string    // parse stack before the step, bottom first
+	Token      lexer.Token // current token
+	Kind       StepKind
+	Production int    // production expanded or reduced, see Rule
+	Rule       string // the production as `LHS → RHS`
+	Symbol     string // terminal matched or shifted, action run, or what was expected at an error
+	Message    string // error message, without colors
+}
+
+// Trace is the list of steps of a parse
+type Trace []Step
+
+// WithTrace records every step of the parse in t
+func WithTrace(t *Trace) Option {
+	return func(p *Parser) { p.trace = t }
+}
+
+// WithSDTTrace records every semantic action the code generator runs in t,
+// with the semantic stack and program block changes, see codegen.SDTTrace
+func WithSDTTrace(t *codegen.SDTTrace) Option {
+	return func(p *Parser) { p.cg.TraceSDT(t) }
+}
+
+// productionNumbers maps each production of the grammar, as rendered by
+// String, to its index
+var productionNumbers = func() map[string]int {
+	m := make(map[string]int, len(grammer))
+	for i, p := range grammer {
+		if p.LHS != "" {
+			m[p.String()] = i
+		}
+	}
+	return m
+}()
+
+// snapshot copies the parse stack when tracing
+func (p *Parser) snapshot() []string {
+	if p.trace == nil {
+		return nil
+	}
+	return append([]string{}, p.stack.Array()...)
+}
+
+// record appends s to the trace when tracing
+func (p *Parser) record(s Step) {
+	if p.trace != nil {
+		*p.trace = append(*p.trace, s)
+	}
+}
+
+// errorMessage returns the message of the last error for the trace
+func (p *Parser) errorMessage() string {
+	if p.lastSuppressed {
+		return p.lastError + " (cascade, not reported)"
+	}
+	return p.lastError
+}
+
+// input renders a token as `type 'lexeme' line:column`
+func input(tok lexer.Token) string {
+	if tok.Type == lexer.EOF {
+		return fmt.Sprintf("$ %d:%d", tok.Pos.Line, tok.Pos.Column)
+	}
+	return fmt.Sprintf("%s '%s' %d:%d", tok.Type, tok.Lexeme, tok.Pos.Line, tok.Pos.Column)
+}
+
+// action describes what a step did
+func (s Step) action() string {
+	switch s.Kind {
+	case StepExpand:
+		return fmt.Sprintf("expand %d: %s", s.Production, s.Rule)
+	case StepReduce:
+		if s.Symbol != "" {
+			return fmt.Sprintf("reduce %d: %s, run %s", s.Production, s.Rule, s.Symbol)
+		}
+		return fmt.Sprintf("reduce %d: %s", s.Production, s.Rule)
+	case StepError:
+		return fmt.Sprintf("error, expected %s: %s", s.Symbol, s.Message)
+	case StepAccept:
+		return string(s.Kind)
+	case StepAction:
+		if s.Message != "" {
+			return fmt.Sprintf("skip %s (%s)", s.Symbol, s.Message)
+		}
+		return "run " + s.Symbol
+	default:
+		return fmt.Sprintf("%s %s", s.Kind, s.Symbol)
+	}
+}
+
+// Text renders the trace as an aligned table
+func (t Trace) Text() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "#\tSTACK\tINPUT\tACTION")
+	for i, s := range t {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", i+1, strings.Join(s.Stack, " "), input(s.Token), s.action())
+	}
+	w.Flush()
+
+	return b.String()
+}
+
+// CSV renders the trace with one row per step
+func (t Trace) CSV() (string, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+	rows := [][]string{{"step", "stack", "token", "lexeme", "line", "column", "action", "production", "rule", "symbol", "message"}}
+	for i, s := range t {
+		prod := ""
+		if s.Kind == StepExpand || s.Kind == StepReduce {
+			prod = strconv.Itoa(s.Production)
+		}
+		rows = append(rows, []string{
+			strconv.Itoa(i + 1), strings.Join(s.Stack, " "),
+			s.Token.Type.String(), s.Token.Lexeme, strconv.Itoa(s.Token.Pos.Line), strconv.Itoa(s.Token.Pos.Column),
+			string(s.Kind), prod, s.Rule, s.Symbol, s.Message,
+		})
+	}
+	if err := w.WriteAll(rows); err != nil {
+		return "", err
+	}
+
+	return b.String(), nil
+}
+
+// jsonToken is a token in the JSON trace
+type jsonToken struct {
+	Type   string `json:"type"`
+	Lexeme string `json:"lexeme"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+// jsonStep is a step in the JSON trace
+type jsonStep struct {
+	Step       int       `json:"step"`
+	Stack      []string  `json:"stack"`
+	Token      jsonToken `json:"token"`
+	Action     StepKind  `json:"action"`
+	Production *int      `json:"production,omitempty"`
+	Rule       string    `json:"rule,omitempty"`
+	Symbol     string    `json:"symbol,omitempty"`
+	Message    string    `json:"message,omitempty"`
+}
+
+// JSON renders the trace as an array of steps
+func (t Trace) JSON() ([]byte, error) {
+	steps := make([]jsonStep, 0, len(t))
+	for i, s := range t {
+		js := jsonStep{
+			Step:    i + 1,
+			Stack:   s.Stack,
+			Token:   jsonToken{Type: s.Token.Type.String(), Lexeme: s.Token.Lexeme, Line: s.Token.Pos.Line, Column: s.Token.Pos.Column},
+			Action:  s.Kind,
+			Rule:    s.Rule,
+			Symbol:  s.Symbol,
+			Message: s.Message,
+		}
+		if s.Kind == StepExpand || s.Kind == StepReduce {
+			js.Production = &s.Production
+		}
+		steps = append(steps, js)
+	}
+
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(steps); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}
@@ -0,0 +1,200 @@
+package parser
+
+import (
+	"bytes"
+	"dolme/pkg/lexer"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// NodeKind tells what a parse tree node stands for
+type NodeKind string
+
+const (
+	NodeNonTerminal NodeKind = "nonterminal"
+	NodeTerminal    NodeKind = "terminal"
+	NodeAction      NodeKind = "action"
+	NodeEpsilon     NodeKind = "epsilon"
+)
+
+// TreeNode is a node of the concrete parse tree. A non-terminal has one
+// child per symbol of the production that expanded it, in order, including
+// its semantic actions; an ε production has a single ε child.
+type TreeNode struct {
+	Kind       NodeKind
+	Symbol     string      // grammar symbol, semantic action or ε
+	Production int         // production that expanded a non-terminal, 0 if it never was
+	Token      lexer.Token // token matched by a terminal
+	Children   []*TreeNode
+}
+
+// WithParseTree builds the parse tree during the parse, see ParseTree
+func WithParseTree() Option {
+	return func(p *Parser) {
+		p.tree = &TreeNode{Kind: NodeNonTerminal, Symbol: "Program"}
+		p.nodes = []*TreeNode{nil, p.tree} // parallel to the parse stack: $ Program
+	}
+}
+
+// ParseTree returns the parse tree when the parser was created with
+// WithParseTree. After a syntax error the parts that were not parsed have
+// no children.
+func (p *Parser) ParseTree() *TreeNode {
+	return p.tree
+}
+
+// pop removes the top of the parse stack and, when building a tree, the
+// node that goes with it, which is kept in p.popped
+func (p *Parser) pop() string {
+	if p.tree != nil {
+		p.popped = p.nodes[len(p.nodes)-1]
+		p.nodes = p.nodes[:len(p.nodes)-1]
+	}
+	return p.stack.Pop()
+}
+
+// push adds a symbol to the parse stack with its node when building a tree
+func (p *Parser) push(symbol string, node *TreeNode) {
+	if p.tree != nil {
+		p.nodes = append(p.nodes, node)
+	}
+	p.stack.Push(symbol)
+}
+
+// expand gives node the children for production n, one per symbol except
+// an ε next to others, and returns them aligned with the right-hand side.
+// It returns nil when not building a tree.
+func (p *Parser) expand(node *TreeNode, n int, prod Production) []*TreeNode {
+	if p.tree == nil {
+		return nil
+	}
+
+	children := make([]*TreeNode, len(prod.RHS))
+	for i, sym := range prod.RHS {
+		if sym == epsilon && len(prod.RHS) > 1 {
+			continue
+		}
+		children[i] = p.newNode(sym)
+		node.Children = append(node.Children, children[i])
+	}
+	node.Production = n
+
+	return children
+}
+
+// nth returns nodes[i], or nil when there are no nodes
+func nth(nodes []*TreeNode, i int) *TreeNode {
+	if nodes == nil {
+		return nil
+	}
+	return nodes[i]
+}
+
+// newNode creates the leaf or unexpanded node for a grammar symbol
+func (p *Parser) newNode(symbol string) *TreeNode {
+	switch {
+	case symbol == epsilon:
+		return &TreeNode{Kind: NodeEpsilon, Symbol: epsilon}
+	case p.isSemanticAction(symbol):
+		return &TreeNode{Kind: NodeAction, Symbol: symbol}
+	case p.isTerminal(symbol):
+		return &TreeNode{Kind: NodeTerminal, Symbol: symbol}
+	default:
+		return &TreeNode{Kind: NodeNonTerminal, Symbol: symbol}
+	}
+}
+
+// label renders a node for DOT: the symbol, with the lexeme of a terminal
+// or the production number of a non-terminal
+func (n *TreeNode) label() string {
+	switch {
+	case n.Kind == NodeTerminal && n.Token.Lexeme != "" && n.Token.Lexeme != n.Symbol:
+		return n.Symbol + "\n" + n.Token.Lexeme
+	case n.Kind == NodeNonTerminal && n.Production > 0:
+		return fmt.Sprintf("%s (%d)", n.Symbol, n.Production)
+	default:
+		return n.Symbol
+	}
+}
+
+// DOT renders the tree as a Graphviz digraph with children in order.
+// Non-terminals are ellipses, tokens boxes and semantic actions notes.
+func (n *TreeNode) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph parsetree {\n")
+	b.WriteString("  ordering=out;\n")
+	b.WriteString("  node [fontname=\"monospace\"];\n")
+
+	id := 0
+	var walk func(n *TreeNode) int
+	walk = func(n *TreeNode) int {
+		me := id
+		id++
+
+		attrs := ""
+		switch n.Kind {
+		case NodeTerminal:
+			attrs = ", shape=box, style=filled, fillcolor=lightyellow"
+		case NodeAction:
+			attrs = ", shape=note, color=blue, fontcolor=blue"
+		case NodeEpsilon:
+			attrs = ", shape=plaintext, fontcolor=gray"
+		default:
+			if n.Production == 0 {
+				attrs = ", style=dashed"
+			}
+		}
+		fmt.Fprintf(&b, "  n%d [label=%q%s];\n", me, n.label(), attrs)
+
+		for _, c := range n.Children {
+			child := walk(c)
+			fmt.Fprintf(&b, "  n%d -> n%d;\n", me, child)
+		}
+		return me
+	}
+	if n != nil {
+		walk(n)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// jsonNode is a node in the JSON tree
+type jsonNode struct {
+	Kind       NodeKind    `json:"kind"`
+	Symbol     string      `json:"symbol"`
+	Production int         `json:"production,omitempty"`
+	Rule       string      `json:"rule,omitempty"`
+	Token      *jsonToken  `json:"token,omitempty"`
+	Children   []*jsonNode `json:"children,omitempty"`
+}
+
+// toJSON converts a node and its children
+func (n *TreeNode) toJSON() *jsonNode {
+	jn := &jsonNode{Kind: n.Kind, Symbol: n.Symbol, Production: n.Production}
+	if n.Kind == NodeNonTerminal && n.Production > 0 && n.Production < len(grammer) {
+		jn.Rule = grammer[n.Production].String()
+	}
+	if n.Kind == NodeTerminal {
+		jn.Token = &jsonToken{Type: n.Token.Type.String(), Lexeme: n.Token.Lexeme, Line: n.Token.Pos.Line, Column: n.Token.Pos.Column}
+	}
+	for _, c := range n.Children {
+		jn.Children = append(jn.Children, c.toJSON())
+	}
+	return jn
+}
+
+// JSON renders the tree as nested objects
+func (n *TreeNode) JSON() ([]byte, error) {
+	var b bytes.Buffer
+	enc := json.NewEncoder(&b)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(n.toJSON()); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimRight(b.Bytes(), "\n"), nil
+}